@@ -1,13 +1,17 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	_ "time/tzdata" // Embed the time zone database for the tz query parameter
 
 	"github.com/shrxyeh/ethereum-fund-flow/internal/api"
 	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/output"
 	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
 )
 
@@ -16,8 +20,9 @@ func main() {
 		defaultAddr = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2" // WETH Contract as default
 		showHelp    = flag.Bool("help", false, "Show usage information")
 		address     = flag.String("address", defaultAddr, "Ethereum address to analyze")
-		mode        = flag.String("mode", "both", "Analysis mode: beneficiary, payer, or both")
+		mode        = flag.String("mode", "", "Analysis mode: beneficiary, payer, or both (overrides .env ANALYSIS_MODE)")
 		port        = flag.String("port", "", "Port to run the server on (overrides .env PORT)")
+		output      = flag.String("output", "", "Analyze the address once and write the JSON result to this file path or http(s) webhook URL instead of starting the server")
 	)
 	
 	// Parse flags
@@ -30,21 +35,36 @@ func main() {
 	}
 	
 	// Validate mode
-	if *mode != "beneficiary" && *mode != "payer" && *mode != "both" {
+	if *mode != "" && !config.ValidAnalysisMode(*mode) {
 		fmt.Println("Error: mode must be 'beneficiary', 'payer', or 'both'")
 		printUsage()
 		os.Exit(1)
 	}
 	
+	// Validate the default address the same way the handlers validate user-supplied ones
+	if !etherscan.IsValidAddress(*address) {
+		fmt.Printf("Error: address %q is not a valid Ethereum address (0x followed by 40 hex characters)\n", *address)
+		printUsage()
+		os.Exit(1)
+	}
+	
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+
+	// Override mode if specified
+	if *mode != "" {
+		cfg.AnalysisMode = *mode
+	}
 	
 	// Override port if specified
 	if *port != "" {
 		cfg.Port = *port
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("Failed to load configuration: %v", err)
+		}
 	}
 	
 	// Initialize logger
@@ -53,12 +73,20 @@ func main() {
 	// Log startup information
 	l.Infof("Starting Ethereum Fund Flow Analysis API")
 	l.Infof("Default Ethereum address: %s", *address)
-	l.Infof("Analysis mode: %s", *mode)
+	l.Infof("Analysis mode: %s", cfg.AnalysisMode)
 	
 	// Start server
 	server := api.NewServer(cfg, l)
 	server.SetDefaultAddress(*address)
-	server.SetAnalysisMode(*mode)
+	server.SetAnalysisMode(cfg.AnalysisMode)
+	
+	// One-shot analysis mode: deliver the result to the output sink and exit
+	if *output != "" {
+		if err := runAnalysis(server, *address, *output); err != nil {
+			l.Fatalf("Analysis failed: %v", err)
+		}
+		return
+	}
 	
 	l.Infof("Server starting on port %s", cfg.Port)
 	if err := server.Start(); err != nil {
@@ -66,6 +94,25 @@ func main() {
 	}
 }
 
+// runAnalysis analyzes the address and writes the JSON result to the output destination
+func runAnalysis(server *api.Server, address, destination string) error {
+	sink, err := output.New(destination)
+	if err != nil {
+		return err
+	}
+
+	result, err := server.Analyze(address)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding result: %w", err)
+	}
+	return sink.Write(data)
+}
+
 func printUsage() {
 	fmt.Println("Ethereum Fund Flow Analysis API")
 	fmt.Println("\nUsage:")
@@ -76,4 +123,5 @@ func printUsage() {
 	fmt.Println("  ./bin/api -address=0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2 -mode=beneficiary")
 	fmt.Println("  ./bin/api -address=0x7a250d5630b4cf539739df2c5dacb4c659f2488d -mode=payer")
 	fmt.Println("  ./bin/api -port=9090")
+	fmt.Println("  ./bin/api -address=0x7a250d5630b4cf539739df2c5dacb4c659f2488d -mode=both -output=result.json")
 }
\ No newline at end of file
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"math/big"
+	"time"
+)
+
+// FilterActiveBeneficiaries keeps the beneficiaries whose most recent transaction is at or after since
+func FilterActiveBeneficiaries(beneficiaries []Beneficiary, since time.Time) []Beneficiary {
+	active := beneficiaries[:0]
+	for _, b := range beneficiaries {
+		if !lastActivity(b.Transactions).Before(since) {
+			active = append(active, b)
+		}
+	}
+	return active
+}
+
+// FilterActivePayers keeps the payers whose most recent transaction is at or after since
+func FilterActivePayers(payers []Payer, since time.Time) []Payer {
+	active := payers[:0]
+	for _, p := range payers {
+		if !lastActivity(p.Transactions).Before(since) {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// FilterBeneficiariesByDate keeps each beneficiary's transactions within [from, to), recomputing
+// its totals from the ones left, and drops beneficiaries left with none. A zero bound is open.
+func FilterBeneficiariesByDate(beneficiaries []Beneficiary, from, to time.Time) []Beneficiary {
+	kept := beneficiaries[:0]
+	for _, b := range beneficiaries {
+		b.Transactions = transactionsBetween(b.Transactions, from, to)
+		if len(b.Transactions) == 0 {
+			continue
+		}
+		b.Amount, b.RawAmount, b.USDValue = transactionTotals(b.Transactions)
+		b.TxCount, b.AvgAmount, b.MaxAmount = transactionStats(b.Amount, b.Transactions)
+		if b.Chains != nil {
+			b.Chains = chainTotals(b.Chains, b.Transactions)
+		}
+		kept = append(kept, b)
+	}
+	return kept
+}
+
+// FilterPayersByDate keeps each payer's transactions within [from, to), recomputing its totals
+// from the ones left, and drops payers left with none. A zero bound is open.
+func FilterPayersByDate(payers []Payer, from, to time.Time) []Payer {
+	kept := payers[:0]
+	for _, p := range payers {
+		p.Transactions = transactionsBetween(p.Transactions, from, to)
+		if len(p.Transactions) == 0 {
+			continue
+		}
+		p.Amount, p.RawAmount, p.USDValue = transactionTotals(p.Transactions)
+		p.TxCount, p.AvgAmount, p.MaxAmount = transactionStats(p.Amount, p.Transactions)
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// FilterBeneficiariesByAmount keeps the beneficiaries whose total amount is at least minAmount
+func FilterBeneficiariesByAmount(beneficiaries []Beneficiary, minAmount float64) []Beneficiary {
+	kept := beneficiaries[:0]
+	for _, b := range beneficiaries {
+		if b.Amount >= minAmount {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// FilterPayersByAmount keeps the payers whose total amount is at least minAmount
+func FilterPayersByAmount(payers []Payer, minAmount float64) []Payer {
+	kept := payers[:0]
+	for _, p := range payers {
+		if p.Amount >= minAmount {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// transactionsBetween returns the transactions within [from, to) in a new slice, leaving the
+// original untouched. A zero bound is open.
+func transactionsBetween(transactions []TransactionDetails, from, to time.Time) []TransactionDetails {
+	var kept []TransactionDetails
+	for _, tx := range transactions {
+		t := transactionTime(tx)
+		if (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to)) {
+			continue
+		}
+		kept = append(kept, tx)
+	}
+	return kept
+}
+
+// transactionTotals sums the amount, exact raw amount and USD value of transactions
+func transactionTotals(transactions []TransactionDetails) (amount float64, raw *big.Int, usd float64) {
+	raw = new(big.Int)
+	for _, tx := range transactions {
+		amount += tx.TxAmount
+		addRawValue(raw, tx.RawValue)
+		usd += tx.USDValue
+	}
+	return amount, raw, usd
+}
+
+// chainTotals recomputes a multi-chain breakdown from the transactions, keeping the chains'
+// order and dropping chains left without transactions
+func chainTotals(chains []ChainAmount, transactions []TransactionDetails) []ChainAmount {
+	totals := make(map[int]*ChainAmount, len(chains))
+	for _, tx := range transactions {
+		total, ok := totals[tx.ChainID]
+		if !ok {
+			total = &ChainAmount{ChainID: tx.ChainID}
+			totals[tx.ChainID] = total
+		}
+		total.Amount += tx.TxAmount
+		total.TxCount++
+		total.USDValue += tx.USDValue
+	}
+
+	var recomputed []ChainAmount
+	for _, chain := range chains {
+		if total, ok := totals[chain.ChainID]; ok {
+			recomputed = append(recomputed, *total)
+		}
+	}
+	return recomputed
+}
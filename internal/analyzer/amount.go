@@ -0,0 +1,154 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// divisors caches 10^decimals as a *big.Float keyed by decimal count, so the hot
+// per-transaction path doesn't recompute it. Cached values are shared and must not be modified.
+var divisors sync.Map
+
+// decimalDivisor returns 10^decimals, computing it once per decimal count
+func decimalDivisor(decimals int) *big.Float {
+	if divisor, ok := divisors.Load(decimals); ok {
+		return divisor.(*big.Float)
+	}
+
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	actual, _ := divisors.LoadOrStore(decimals, divisor)
+	return actual.(*big.Float)
+}
+
+// etherDecimals is the decimal count of Ether, which raw totals are kept in. Token transfers carry
+// no decimals and are scaled by it too.
+const etherDecimals = 18
+
+// toEtherUnits rescales a raw native value of a chain whose native token has the given decimals
+// to 18 decimals, so values of every chain are summed and formatted like Wei. More than 18
+// decimals would truncate the value, so configuration rejects them.
+func toEtherUnits(valueStr string, decimals int) string {
+	if decimals == etherDecimals {
+		return valueStr
+	}
+
+	value, err := parseRawValue(valueStr)
+	if err != nil {
+		return valueStr
+	}
+	if decimals < etherDecimals {
+		value.Mul(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(etherDecimals-decimals)), nil))
+	} else {
+		value.Quo(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-etherDecimals)), nil))
+	}
+	return value.String()
+}
+
+// weiToEther converts a raw Wei value string to Ether.
+// It fails on unparseable values and on values too large to represent as a float64.
+func weiToEther(valueStr string) (float64, error) {
+	return scaleAmount(valueStr, 18) // 10^18 (Wei to Ether)
+}
+
+// WeiToGwei converts a raw Wei value string, such as a gas price, to Gwei.
+// It fails on unparseable values and on values too large to represent as a float64.
+func WeiToGwei(valueStr string) (float64, error) {
+	return scaleAmount(valueStr, 9) // 10^9 (Wei to Gwei)
+}
+
+// scaleAmount converts a raw integer value string to a float by dividing by 10^decimals.
+// It fails on unparseable values and on values too large to represent as a float64.
+func scaleAmount(valueStr string, decimals int) (float64, error) {
+	raw, err := parseRawValue(valueStr)
+	if err != nil {
+		return 0, err
+	}
+
+	value := new(big.Float).SetInt(raw)
+	value.Quo(value, decimalDivisor(decimals))
+
+	amount, _ := value.Float64()
+	if math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("value out of range: %s", valueStr)
+	}
+
+	return amount, nil
+}
+
+// parseRawValue parses a raw integer value given in decimal, as the account module returns
+// it, or as 0x-prefixed hex, as proxy and log-derived sources return it
+func parseRawValue(valueStr string) (*big.Int, error) {
+	if strings.HasPrefix(valueStr, "0x") || strings.HasPrefix(valueStr, "0X") {
+		return hexToBigInt(strings.ToLower(valueStr))
+	}
+
+	value, ok := new(big.Int).SetString(valueStr, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid value: %q", valueStr)
+	}
+	return value, nil
+}
+
+// decimalValue normalizes a raw decimal or hex value string to decimal, leaving unparseable values as is
+func decimalValue(valueStr string) string {
+	value, err := parseRawValue(valueStr)
+	if err != nil {
+		return valueStr
+	}
+	return value.String()
+}
+
+// addRawValue adds a raw decimal or hex value string to a running total, ignoring unparseable values
+func addRawValue(total *big.Int, valueStr string) {
+	if value, err := parseRawValue(valueStr); err == nil {
+		total.Add(total, value)
+	}
+}
+
+// FormatUnits formats a raw integer amount divided by 10^decimals as an exact decimal string,
+// e.g. 1500000000000000000 with 18 decimals is "1.5". A nil amount formats as "0".
+func FormatUnits(raw *big.Int, decimals int) string {
+	if raw == nil {
+		return "0"
+	}
+
+	digits := new(big.Int).Abs(raw).String()
+	sign := ""
+	if raw.Sign() < 0 {
+		sign = "-"
+	}
+	if decimals <= 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// FormatEther formats a raw Wei amount as an exact decimal Ether string
+func FormatEther(wei *big.Int) string {
+	return FormatUnits(wei, 18)
+}
+
+// hexToBigInt parses a 0x-prefixed hex quantity
+func hexToBigInt(hex string) (*big.Int, error) {
+	digits := strings.TrimPrefix(hex, "0x")
+	if digits == "" {
+		return new(big.Int), nil
+	}
+
+	value, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid hex value: %q", hex)
+	}
+	return value, nil
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+func TestWeiToEther(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  float64
+	}{
+		{"0", 0},
+		{"1000000000000000000", 1},
+		{"1500000000000000000", 1.5},
+		{"1", 1e-18},
+	} {
+		got, err := weiToEther(tc.value)
+		if err != nil {
+			t.Errorf("weiToEther(%q): %v", tc.value, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("weiToEther(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestWeiToEtherRejectsUnparseableValues(t *testing.T) {
+	for _, value := range []string{"", "abc", "1.5", "-", "1e18"} {
+		if got, err := weiToEther(value); err == nil {
+			t.Errorf("weiToEther(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestWeiToEtherRejectsValuesOutOfFloat64Range(t *testing.T) {
+	// 10^327 Wei is 10^309 Ether, past the largest float64 (about 1.8e308)
+	value := "1" + strings.Repeat("0", 327)
+	got, err := weiToEther(value)
+	if err == nil {
+		t.Fatalf("weiToEther(10^327) = %v, want an error", got)
+	}
+	if !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("error = %q, want an out of range error", err)
+	}
+
+	// 10^325 Wei, 10^307 Ether, still fits
+	if _, err := weiToEther("1" + strings.Repeat("0", 325)); err != nil {
+		t.Errorf("weiToEther(10^325): %v", err)
+	}
+}
+
+func TestParseRawValue(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  string
+	}{
+		{"0", "0"},
+		{"1500000000000000000", "1500000000000000000"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"0x0", "0"},
+		{"0x", "0"},
+		{"0xde0b6b3a7640000", "1000000000000000000"},
+		{"0XDE0B6B3A7640000", "1000000000000000000"},
+		{"0x18ee90ff6c373e0ee4e3f0ad2", "123456789012345678901234567890"},
+	} {
+		got, err := parseRawValue(tc.value)
+		if err != nil {
+			t.Errorf("parseRawValue(%q): %v", tc.value, err)
+			continue
+		}
+		if got.String() != tc.want {
+			t.Errorf("parseRawValue(%q) = %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestParseRawValueRejectsInvalidValues(t *testing.T) {
+	// Hex digits without the prefix aren't decimal
+	for _, value := range []string{"", "de0b6b3a7640000", "0xzz", "1.5"} {
+		if got, err := parseRawValue(value); err == nil {
+			t.Errorf("parseRawValue(%q) = %s, want an error", value, got)
+		}
+	}
+}
+
+func TestAnalyzeHexValues(t *testing.T) {
+	client := newTestClient(t, testTransactions{
+		normal: []etherscan.Transaction{
+			testTransaction("0xa1", testAddress, testAlice, "0xde0b6b3a7640000"),
+			testTransaction("0xb1", testBob, testAddress, "0x1bc16d674ec80000"),
+		},
+	})
+
+	beneficiaries, err := NewBeneficiaryAnalyzer(client).AnalyzeBeneficiary(testAddress, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := findBeneficiary(t, beneficiaries, testAlice)
+	if alice.Amount != 1 || alice.Transactions[0].RawValue != "1000000000000000000" {
+		t.Errorf("alice = %v ETH (raw %s), want 1 (raw 1000000000000000000)", alice.Amount, alice.Transactions[0].RawValue)
+	}
+
+	payers, err := NewPayerAnalyzer(client).AnalyzePayer(testAddress, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob := findPayer(t, payers, testBob)
+	if bob.Amount != 2 || bob.Transactions[0].RawValue != "2000000000000000000" {
+		t.Errorf("bob = %v ETH (raw %s), want 2 (raw 2000000000000000000)", bob.Amount, bob.Transactions[0].RawValue)
+	}
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+const (
+	testAddress = "0x1111111111111111111111111111111111111111"
+	testAlice   = "0x2222222222222222222222222222222222222222"
+	testBob     = "0x3333333333333333333333333333333333333333"
+)
+
+// testTransactions are the lists the stub Etherscan serves for every address
+type testTransactions struct {
+	normal   []etherscan.Transaction
+	internal []etherscan.Transaction
+	tokens   []etherscan.TokenTransfer
+
+	// latestBlock is the chain head the stub reports
+	latestBlock int
+}
+
+// newTestClient returns a client backed by a stub Etherscan serving txs on their first page
+// and every address as an externally-owned account
+func newTestClient(t *testing.T, txs testTransactions) *etherscan.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("page") != "" && query.Get("page") != "1" {
+			fmt.Fprint(w, `{"status":"0","message":"No transactions found","result":[]}`)
+			return
+		}
+
+		switch query.Get("action") {
+		case "txlist":
+			json.NewEncoder(w).Encode(etherscan.TransactionResponse{Status: "1", Message: "OK", Result: txs.normal})
+		case "txlistinternal":
+			json.NewEncoder(w).Encode(etherscan.TransactionResponse{Status: "1", Message: "OK", Result: txs.internal})
+		case "tokentx":
+			json.NewEncoder(w).Encode(etherscan.TokenTransferResponse{Status: "1", Message: "OK", Result: txs.tokens})
+		case "eth_blockNumber":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":83,"result":"0x%x"}`, txs.latestBlock)
+		case "eth_getCode":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x"}`)
+		default:
+			fmt.Fprint(w, `{"status":"0","message":"No transactions found","result":[]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return etherscan.NewClient("TESTKEY", server.URL)
+}
+
+// testTransaction returns a successful normal or internal transaction of value Wei
+func testTransaction(hash, from, to, value string) etherscan.Transaction {
+	return etherscan.Transaction{
+		Hash:          hash,
+		BlockNumber:   "100",
+		TimeStamp:     "1710072000",
+		From:          from,
+		To:            to,
+		Value:         value,
+		IsError:       "0",
+		Confirmations: "100",
+	}
+}
+
+// testTokenTransfer returns a transfer of value base units of the token contract
+func testTokenTransfer(hash, contract, from, to, value string) etherscan.TokenTransfer {
+	return etherscan.TokenTransfer{
+		Hash:            hash,
+		BlockNumber:     "100",
+		TimeStamp:       "1710072000",
+		From:            from,
+		To:              to,
+		Value:           value,
+		TokenName:       "Test Token",
+		TokenSymbol:     "TST",
+		TokenDecimal:    "18",
+		ContractAddress: contract,
+		Confirmations:   "100",
+	}
+}
+
+// findBeneficiary returns the beneficiary with the given address, failing the test without one
+func findBeneficiary(t *testing.T, beneficiaries []Beneficiary, address string) Beneficiary {
+	t.Helper()
+
+	for _, b := range beneficiaries {
+		if b.Address == address {
+			return b
+		}
+	}
+	t.Fatalf("no beneficiary %s in %+v", address, beneficiaries)
+	return Beneficiary{}
+}
+
+// findPayer returns the payer with the given address, failing the test without one
+func findPayer(t *testing.T, payers []Payer, address string) Payer {
+	t.Helper()
+
+	for _, p := range payers {
+		if p.Address == address {
+			return p
+		}
+	}
+	t.Fatalf("no payer %s in %+v", address, payers)
+	return Payer{}
+}
@@ -2,24 +2,37 @@ package analyzer
 
 import (
 	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/price"
 	"golang.org/x/sync/errgroup"
 )
 
 // responsible for analyzing transactions to identify beneficiaries
 type BeneficiaryAnalyzer struct {
-	etherscanClient *etherscan.Client
-	debug           bool
+	etherscanClient   *etherscan.Client
+	stablecoins       map[string]float64
+	spamContracts     map[string]bool
+	wethContract      string
+	ownAddresses      map[string]bool
+	exchangeAddresses map[string]bool
+	sanctioned        map[string]bool
+	disabledTypes     map[string]bool
+	prices            price.Provider
+	budget            budget
+	debug             bool
 }
 
 // creates a new beneficiary analyzer
 func NewBeneficiaryAnalyzer(etherscanClient *etherscan.Client) *BeneficiaryAnalyzer {
 	return &BeneficiaryAnalyzer{
 		etherscanClient: etherscanClient,
+		budget:          unlimitedBudget,
 		debug:           true, // Enable debug logging
 	}
 }
@@ -28,7 +41,18 @@ func NewBeneficiaryAnalyzer(etherscanClient *etherscan.Client) *BeneficiaryAnaly
 type Beneficiary struct {
 	Address      string               `json:"beneficiary_address"`
 	Amount       float64              `json:"amount"`
+	TxCount      int                  `json:"tx_count"`
+	AvgAmount    float64              `json:"avg_amount"`
+	MaxAmount    float64              `json:"max_amount"`
 	Transactions []TransactionDetails `json:"transactions"`
+	RawAmount    *big.Int             `json:"-"` // Exact total in Wei
+	Label        string               `json:"label,omitempty"`
+	USDValue     float64              `json:"usd_value,omitempty"`
+	IsContract   *bool                `json:"is_contract,omitempty"`
+	Flags        []string             `json:"flags,omitempty"`
+	Score        float64              `json:"score,omitempty"`
+	Chains       []ChainAmount        `json:"chains,omitempty"` // Per-chain breakdown of a multi-chain analysis
+	Sanctioned   bool                 `json:"sanctioned,omitempty"`
 }
 
 // represents simplified transaction details
@@ -36,67 +60,221 @@ type TransactionDetails struct {
 	TxAmount      float64 `json:"tx_amount"`
 	DateTime      string  `json:"date_time"`
 	TransactionID string  `json:"transaction_id"`
+
+	// RawValue is the exact integer value in Wei (token base units for token transfers)
+	RawValue string `json:"-"`
+
+	// ChainID is the chain the transaction happened on in a multi-chain analysis
+	ChainID int `json:"chain_id,omitempty"`
+
+	// CallType is the call type of an internal transaction (call, create, suicide, ...)
+	CallType string `json:"call_type,omitempty"`
+
+	// Method is the name of the function a normal transaction called, decoded from its calldata
+	Method string `json:"method,omitempty"`
+
+	// Kind is "wrap" or "unwrap" for Ether moving into or out of WETH when weth=label is requested,
+	// and "mint" or "burn" for the analyzed token contract's own mints and burns with mint_burn=true
+	Kind string `json:"kind,omitempty"`
+
+	// USDValue is the transaction's USD value (historical with usd=true, otherwise stablecoin pegs only)
+	USDValue float64 `json:"usd_value,omitempty"`
+
+	// ViaProxy is the proxy contract a payment was routed through when resolve_proxies attributed it to its originator
+	ViaProxy string `json:"via_proxy,omitempty"`
+}
+
+// sets the stablecoin contracts (lowercase address -> USD peg) used for USD-denominated totals
+func (ba *BeneficiaryAnalyzer) SetStablecoins(stablecoins map[string]float64) {
+	ba.stablecoins = stablecoins
+}
+
+// sets the denylisted spam/airdrop token contracts (lowercase address) whose transfers are skipped
+func (ba *BeneficiaryAnalyzer) SetSpamContracts(spamContracts map[string]bool) {
+	ba.spamContracts = spamContracts
+}
+
+// sets the addresses (lowercase) the user controls, which are never reported as counterparties
+func (ba *BeneficiaryAnalyzer) SetOwnAddresses(ownAddresses map[string]bool) {
+	ba.ownAddresses = ownAddresses
+}
+
+// sets the transaction types (MovementInternal, MovementToken, ...) never fetched, whatever the request asks for
+func (ba *BeneficiaryAnalyzer) SetDisabledTypes(disabled map[string]bool) {
+	ba.disabledTypes = disabled
+}
+
+// sets the price feed used for historical USD values (usd=true)
+func (ba *BeneficiaryAnalyzer) SetPriceProvider(prices price.Provider) {
+	ba.prices = prices
+}
+
+// sets the Wrapped Ether contract (lowercase address) whose deposits and withdrawals are wrap/unwrap flows
+func (ba *BeneficiaryAnalyzer) SetWETHContract(wethContract string) {
+	ba.wethContract = wethContract
+}
+
+// sets the deadline (0 means none) and the total Etherscan retries (negative means unlimited)
+// of each analysis
+func (ba *BeneficiaryAnalyzer) SetBudget(timeout time.Duration, maxRetries int) {
+	ba.budget = budget{timeout: timeout, maxRetries: maxRetries}
 }
 
 // analyzes the transaction flow for a given address to identify beneficiaries
-func (ba *BeneficiaryAnalyzer) AnalyzeBeneficiary(address string) ([]Beneficiary, error) {
+func (ba *BeneficiaryAnalyzer) AnalyzeBeneficiary(address string, opts Options) ([]Beneficiary, error) {
 	if ba.debug {
 		fmt.Printf("DEBUG: Starting beneficiary analysis for address: %s\n", address)
 	}
 
-	// Fetch all transaction types concurrently
-	var normalTxs []etherscan.Transaction
-	var internalTxs []etherscan.Transaction
-	var tokenTransfers []etherscan.TokenTransfer
-	var err error
+	// Transaction types disabled for the deployment are never fetched
+	opts = opts.withoutTypes(ba.disabledTypes)
 
-	eg := errgroup.Group{}
+	// Every fetch of this analysis shares one deadline and retry budget
+	client, cancel := ba.budget.client(ba.etherscanClient, opts.ChainID)
+	defer cancel()
+
+	// Native values are scaled by the decimals of the analyzed chain's native token
+	opts.nativeDecimals = client.NativeDecimals()
+
+	// Fetch all transaction types concurrently; normal transactions are streamed below in streaming mode
+	fetch := fetchTransactionSet
+	if opts.Stream {
+		fetch = fetchTransferSet
+	}
+	txs, err := fetch(client, address, opts)
+	if err != nil {
+		return nil, err
+	}
+	internalTxs, tokenTransfers := txs.internal, txs.tokens
+
+	// Transactions are held to min_confirmations by their block against the current chain head
+	opts, err = withConfirmedBlock(client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if ba.debug {
+		fmt.Printf("DEBUG: Fetched %d normal transactions\n", len(txs.normal))
+		fmt.Printf("DEBUG: Fetched %d internal transactions\n", len(internalTxs))
+		fmt.Printf("DEBUG: Fetched %d token transfers\n", len(tokenTransfers))
+	}
+
+	// Aggregate the three transaction lists concurrently, each into its own map, and merge the
+	// maps once all are done. Internal transactions folded into their parent need the normal
+	// transactions' map, so with merge_internal they follow on the same goroutine.
+	beneficiaryMap := make(map[string]*Beneficiary)
+	internalMap := make(map[string]*Beneficiary)
+	tokenMap := make(map[string]*Beneficiary)
 
+	eg := errgroup.Group{}
 	eg.Go(func() error {
-		normalTxs, err = ba.etherscanClient.GetNormalTransactions(address)
+		parents, err := ba.processNormalTransactions(client, beneficiaryMap, address, opts, txs)
 		if err != nil {
-			return fmt.Errorf("error fetching normal transactions: %w", err)
+			return err
 		}
-		if ba.debug {
-			fmt.Printf("DEBUG: Fetched %d normal transactions\n", len(normalTxs))
+		if opts.MergeInternal {
+			ba.processInternalTransactions(beneficiaryMap, parents, address, opts, internalTxs)
 		}
 		return nil
 	})
-
+	if !opts.MergeInternal {
+		eg.Go(func() error {
+			ba.processInternalTransactions(internalMap, nil, address, opts, internalTxs)
+			return nil
+		})
+	}
 	eg.Go(func() error {
-		internalTxs, err = ba.etherscanClient.GetInternalTransactions(address)
-		if err != nil {
-			return fmt.Errorf("error fetching internal transactions: %w", err)
+		ba.processTokenTransfers(tokenMap, address, opts, tokenTransfers)
+		return nil
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	mergeBeneficiaryMaps(beneficiaryMap, internalMap)
+	mergeBeneficiaryMaps(beneficiaryMap, tokenMap)
+
+	// Drop the user's own addresses and any excluded for this request, with their amounts
+	for addr := range beneficiaryMap {
+		if ba.ownAddresses[addr] || opts.Exclude[addr] {
+			delete(beneficiaryMap, addr)
 		}
-		if ba.debug {
-			fmt.Printf("DEBUG: Fetched %d internal transactions\n", len(internalTxs))
+	}
+
+	// Gas goes to the network rather than a counterparty
+	if gas, ok := beneficiaryMap[GasBeneficiary]; ok {
+		gas.Label = gasLabel
+	}
+
+	// Funds sent to the zero address are burned rather than received by anyone
+	if burned, ok := beneficiaryMap[zeroAddress]; ok {
+		if opts.ExcludeBurns {
+			delete(beneficiaryMap, zeroAddress)
+		} else if burned.Label == "" {
+			burned.Label = burnAddressLabel
 		}
-		return nil
+	}
+
+	// Convert map to slice
+	beneficiaries := make([]Beneficiary, 0, len(beneficiaryMap))
+	for _, beneficiary := range beneficiaryMap {
+		beneficiaries = append(beneficiaries, *beneficiary)
+	}
+
+	// Most significant counterparties first
+	sort.SliceStable(beneficiaries, func(i, j int) bool {
+		return beneficiaries[i].Amount > beneficiaries[j].Amount
 	})
 
-	eg.Go(func() error {
-		tokenTransfers, err = ba.etherscanClient.GetTokenTransfers(address)
+	if ba.debug {
+		fmt.Printf("DEBUG: Found %d beneficiary addresses\n", len(beneficiaries))
+		for i, b := range beneficiaries {
+			if i < 5 { // Limit to first 5 for brevity
+				fmt.Printf("DEBUG: Beneficiary %d - Address: %s, Total: %f ETH, Transactions: %d\n", 
+					i, b.Address, b.Amount, len(b.Transactions))
+			}
+		}
+	}
+
+	if opts.DetectContracts {
+		addresses := make([]string, len(beneficiaries))
+		for i, b := range beneficiaries {
+			addresses[i] = b.Address
+		}
+
+		contracts, err := lookupContracts(client, addresses)
 		if err != nil {
-			return fmt.Errorf("error fetching token transfers: %w", err)
+			return nil, err
 		}
-		if ba.debug {
-			fmt.Printf("DEBUG: Fetched %d token transfers\n", len(tokenTransfers))
+
+		for i := range beneficiaries {
+			info := contracts[strings.ToLower(beneficiaries[i].Address)]
+			beneficiaries[i].IsContract = &info.isContract
+			if beneficiaries[i].Label == "" {
+				beneficiaries[i].Label = info.name
+			}
 		}
-		return nil
-	})
+	}
 
-	if err := eg.Wait(); err != nil {
-		return nil, err
+	// Screen the counterparties against the sanctions list
+	for i := range beneficiaries {
+		beneficiaries[i].Sanctioned = ba.sanctioned[strings.ToLower(beneficiaries[i].Address)]
 	}
 
-	// Process transactions to identify beneficiaries
-	beneficiaryMap := make(map[string]*Beneficiary)
+	return beneficiaries, nil
+}
 
-	// Process normal transactions
-	for i, tx := range normalTxs {
-		if ba.debug && i < 5 {
+// aggregates the outgoing normal transactions into the beneficiary map, returning the
+// beneficiary each transaction was attributed to by hash when internal transactions are merged
+func (ba *BeneficiaryAnalyzer) processNormalTransactions(client *etherscan.Client, beneficiaryMap map[string]*Beneficiary, address string, opts Options, txs *transactionSet) (map[string]string, error) {
+	// Beneficiary each normal transaction was attributed to, keyed by hash, so the
+	// internal transactions it spawned can be merged into it
+	parents := make(map[string]string)
+
+	seen := 0
+	err := forEachNormalTransaction(client, address, opts, txs, func(tx etherscan.Transaction) {
+		if ba.debug && seen < 5 {
 			fmt.Printf("DEBUG: Normal tx %d - From: %s, To: %s, Value: %s, Hash: %s, IsError: %s\n", 
-				i, tx.From, tx.To, tx.Value, tx.Hash, tx.IsError)
+				seen, tx.From, tx.To, tx.Value, tx.Hash, tx.IsError)
 			
 			// Convert timestamp
 			timestamp, err := stringToInt64(tx.TimeStamp)
@@ -105,83 +283,131 @@ func (ba *BeneficiaryAnalyzer) AnalyzeBeneficiary(address string) ([]Beneficiary
 				fmt.Printf("DEBUG: Timestamp: %s -> %s\n", tx.TimeStamp, formattedTime)
 			}
 		}
+		seen++
+
+		// Skip transactions that may still be reorganized away
+		if !opts.confirmed(tx.BlockNumber) {
+			return
+		}
+
+		// Gas is paid on every transaction the address sends, including failed ones
+		if opts.IncludeGas && strings.EqualFold(tx.From, address) {
+			if fee := gasFee(tx); fee != "" {
+				ba.processBeneficiary(beneficiaryMap, GasBeneficiary, kindGas, "", "", fee, tx.Hash, tx.TimeStamp,
+					etherUSDValue(ba.prices, opts, fee, tx.TimeStamp), opts.native(), opts.Location)
+			}
+		}
 
 		// Only consider outgoing transactions (where this address is the source)
 		if strings.EqualFold(tx.From, address) && tx.IsError == "0" {
+			kind, skip := wrapFlow(opts, ba.wethContract, tx.From, tx.To, tx.Value)
+			if skip {
+				return
+			}
 			if ba.debug {
 				fmt.Printf("DEBUG: Processing outgoing normal transaction to %s with value %s\n", tx.To, tx.Value)
 			}
-			ba.processBeneficiary(beneficiaryMap, tx.To, tx.Value, tx.Hash, tx.TimeStamp)
+			ba.processBeneficiary(beneficiaryMap, tx.To, kind, "", decodeMethod(tx), tx.Value, tx.Hash, tx.TimeStamp,
+				etherUSDValue(ba.prices, opts, tx.Value, tx.TimeStamp), opts.native(), opts.Location)
+			if opts.MergeInternal {
+				parents[tx.Hash] = tx.To
+			}
 		}
+	})
+	if err != nil {
+		return nil, err
 	}
+	return parents, nil
+}
 
-	// Process internal transactions
+// aggregates the outgoing internal transactions into the beneficiary map, folding those whose
+// hash is in parents into the beneficiary of their normal transaction
+func (ba *BeneficiaryAnalyzer) processInternalTransactions(beneficiaryMap map[string]*Beneficiary, parents map[string]string, address string, opts Options, internalTxs []etherscan.Transaction) {
 	for i, tx := range internalTxs {
 		if ba.debug && i < 5 {
 			fmt.Printf("DEBUG: Internal tx %d - From: %s, To: %s, Value: %s, Hash: %s, IsError: %s\n", 
 				i, tx.From, tx.To, tx.Value, tx.Hash, tx.IsError)
 		}
 
-		// Only consider outgoing transactions
+		// Only consider outgoing transactions; a contract calling itself moves nothing to a counterparty
 		if strings.EqualFold(tx.From, address) && tx.IsError == "0" {
+			if isSelfMove(address, tx.From, tx.To) || opts.ExcludeCallTypes[strings.ToLower(tx.Type)] || !opts.confirmed(tx.BlockNumber) {
+				continue
+			}
+			if parent, ok := parents[tx.Hash]; ok {
+				ba.mergeIntoParent(beneficiaryMap, parent, tx.Value, tx.Hash, etherUSDValue(ba.prices, opts, tx.Value, tx.TimeStamp), opts.native())
+				continue
+			}
+			kind, skip := wrapFlow(opts, ba.wethContract, tx.From, tx.To, tx.Value)
+			if skip {
+				continue
+			}
 			if ba.debug {
 				fmt.Printf("DEBUG: Processing outgoing internal transaction to %s with value %s\n", tx.To, tx.Value)
 			}
-			ba.processBeneficiary(beneficiaryMap, tx.To, tx.Value, tx.Hash, tx.TimeStamp)
+			ba.processBeneficiary(beneficiaryMap, tx.To, kind, tx.Type, "", tx.Value, tx.Hash, tx.TimeStamp,
+				etherUSDValue(ba.prices, opts, tx.Value, tx.TimeStamp), opts.native(), opts.Location)
 		}
 	}
+}
 
-	// Process token transfers
+// aggregates the outgoing token transfers into the beneficiary map
+func (ba *BeneficiaryAnalyzer) processTokenTransfers(beneficiaryMap map[string]*Beneficiary, address string, opts Options, tokenTransfers []etherscan.TokenTransfer) {
 	for i, transfer := range tokenTransfers {
 		if ba.debug && i < 5 {
 			fmt.Printf("DEBUG: Token transfer %d - From: %s, To: %s, Value: %s, Token: %s (%s), Hash: %s\n", 
 				i, transfer.From, transfer.To, transfer.Value, transfer.TokenName, transfer.TokenSymbol, transfer.Hash)
 		}
 
+		// Skip transfers of denylisted spam tokens unless asked for, and unconfirmed ones
+		if !opts.IncludeSpam && isSpamTransfer(ba.spamContracts, transfer) || !opts.confirmed(transfer.BlockNumber) {
+			continue
+		}
+
+		// Mints of the analyzed contract's own token go to their recipient
+		if opts.MintBurn {
+			if party, kind := mintBurnParty(address, transfer); kind == kindMint {
+				ba.processBeneficiary(beneficiaryMap, party, kind, "", "", transfer.Value, transfer.Hash, transfer.TimeStamp,
+					tokenUSDValue(ba.prices, opts, ba.stablecoins, transfer), etherDecimals, opts.Location)
+				continue
+			}
+		}
+
 		// Only consider outgoing transfers
 		if strings.EqualFold(transfer.From, address) {
 			if ba.debug {
 				fmt.Printf("DEBUG: Processing outgoing token transfer to %s with value %s of token %s\n", 
 					transfer.To, transfer.Value, transfer.TokenSymbol)
 			}
-			ba.processBeneficiary(beneficiaryMap, transfer.To, transfer.Value, transfer.Hash, transfer.TimeStamp)
-		}
-	}
-
-	// Convert map to slice
-	beneficiaries := make([]Beneficiary, 0, len(beneficiaryMap))
-	for _, beneficiary := range beneficiaryMap {
-		beneficiaries = append(beneficiaries, *beneficiary)
-	}
-
-	if ba.debug {
-		fmt.Printf("DEBUG: Found %d beneficiary addresses\n", len(beneficiaries))
-		for i, b := range beneficiaries {
-			if i < 5 { // Limit to first 5 for brevity
-				fmt.Printf("DEBUG: Beneficiary %d - Address: %s, Total: %f ETH, Transactions: %d\n", 
-					i, b.Address, b.Amount, len(b.Transactions))
-			}
+			ba.processBeneficiary(beneficiaryMap, transfer.To, "", "", "", transfer.Value, transfer.Hash, transfer.TimeStamp,
+				tokenUSDValue(ba.prices, opts, ba.stablecoins, transfer), etherDecimals, opts.Location)
 		}
 	}
-
-	return beneficiaries, nil
 }
 
-// adds a transaction to the beneficiary map
+// adds a transaction to the beneficiary map; kind is its wrap/unwrap label, callType its
+// internal call type and method the function a normal transaction called, if any, and
+// decimals those of its raw value
 func (ba *BeneficiaryAnalyzer) processBeneficiary(beneficiaryMap map[string]*Beneficiary, 
-	beneficiaryAddr, valueStr, hash, timestampStr string) {
+	beneficiaryAddr, kind, callType, method, valueStr, hash, timestampStr string, usdValue float64, decimals int, loc *time.Location) {
 		
-	// Convert value to float (from Wei to Ether)
-	value := new(big.Float)
-	value.SetString(valueStr)
-	divisor := new(big.Float)
-	divisor.SetString("1000000000000000000") // 10^18 (Wei to Ether)
-	value.Quo(value, divisor)
+	// Key and display by lowercase address so differently-cased inputs aggregate together
+	beneficiaryAddr = strings.ToLower(beneficiaryAddr)
 
-	amount, _ := value.Float64()
+	// Keep raw values in 18 decimals whatever the native token's decimals
+	valueStr = toEtherUnits(valueStr, decimals)
+
+	// Convert value to float (from Wei to Ether), skipping values that can't be represented
+	amount, err := weiToEther(valueStr)
+	if err != nil {
+		if ba.debug {
+			fmt.Printf("DEBUG: Skipping transaction %s: %v\n", hash, err)
+		}
+		return
+	}
 
 	// Format timestamp
-	dateTime, err := etherscan.FormatTime(timestampStr)
+	dateTime, err := etherscan.FormatTime(timestampStr, loc)
 	if err != nil {
 		dateTime = timestampStr // Use original timestamp if formatting fails
 		if ba.debug {
@@ -202,17 +428,72 @@ func (ba *BeneficiaryAnalyzer) processBeneficiary(beneficiaryMap map[string]*Ben
 		TxAmount:      amount,
 		DateTime:      dateTime,
 		TransactionID: hash,
+		RawValue:      decimalValue(valueStr),
+		CallType:      callType,
+		Method:        method,
+		Kind:          kind,
+		USDValue:      usdValue,
 	}
 
 	// Add to beneficiary map
 	if b, exists := beneficiaryMap[beneficiaryAddr]; exists {
 		b.Amount += amount
+		addRawValue(b.RawAmount, valueStr)
 		b.Transactions = append(b.Transactions, txDetails)
+		b.USDValue += usdValue
+		b.TxCount++
+		b.AvgAmount = b.Amount / float64(b.TxCount)
+		b.MaxAmount = math.Max(b.MaxAmount, amount)
 	} else {
+		rawAmount := new(big.Int)
+		addRawValue(rawAmount, valueStr)
 		beneficiaryMap[beneficiaryAddr] = &Beneficiary{
 			Address:      beneficiaryAddr,
 			Amount:       amount,
+			TxCount:      1,
+			AvgAmount:    amount,
+			MaxAmount:    amount,
+			RawAmount:    rawAmount,
 			Transactions: []TransactionDetails{txDetails},
+			USDValue:     usdValue,
+		}
+	}
+
+	// Label the WETH contract by what the address did with it; mints only mark their transactions
+	if b := beneficiaryMap[beneficiaryAddr]; isWrapKind(kind) && b.Label == "" {
+		b.Label = kind
+	}
+}
+
+// folds the value of an internal transaction, with the given native decimals, into the parent
+// normal transaction in the beneficiary map
+func (ba *BeneficiaryAnalyzer) mergeIntoParent(beneficiaryMap map[string]*Beneficiary, parentAddr, valueStr, hash string, usdValue float64, decimals int) {
+	valueStr = toEtherUnits(valueStr, decimals)
+	amount, err := weiToEther(valueStr)
+	if err != nil {
+		return
+	}
+
+	b, exists := beneficiaryMap[strings.ToLower(parentAddr)]
+	if !exists {
+		return
+	}
+
+	for i := range b.Transactions {
+		if b.Transactions[i].TransactionID == hash {
+			b.Transactions[i].TxAmount += amount
+			b.Amount += amount
+			b.Transactions[i].USDValue += usdValue
+			b.USDValue += usdValue
+			b.AvgAmount = b.Amount / float64(b.TxCount)
+			b.MaxAmount = math.Max(b.MaxAmount, b.Transactions[i].TxAmount)
+
+			raw := new(big.Int)
+			addRawValue(raw, b.Transactions[i].RawValue)
+			addRawValue(raw, valueStr)
+			b.Transactions[i].RawValue = raw.String()
+			addRawValue(b.RawAmount, valueStr)
+			return
 		}
 	}
 }
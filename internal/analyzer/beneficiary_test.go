@@ -0,0 +1,38 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// Spellings of one address: lowercase, EIP-55 checksummed and uppercase
+const (
+	testMixedLower = "0xabcdef0123456789abcdef0123456789abcdef01"
+	testMixedSum   = "0xAbCdEf0123456789aBcDeF0123456789AbCdEf01"
+	testMixedUpper = "0xABCDEF0123456789ABCDEF0123456789ABCDEF01"
+)
+
+func TestAnalyzeBeneficiaryAggregatesMixedCaseAddresses(t *testing.T) {
+	client := newTestClient(t, testTransactions{
+		normal: []etherscan.Transaction{
+			testTransaction("0xa1", testAddress, testMixedSum, "1000000000000000000"),
+			testTransaction("0xa2", testAddress, testMixedLower, "2000000000000000000"),
+		},
+		internal: []etherscan.Transaction{
+			testTransaction("0xa3", testAddress, testMixedUpper, "500000000000000000"),
+		},
+	})
+
+	beneficiaries, err := NewBeneficiaryAnalyzer(client).AnalyzeBeneficiary(testAddress, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(beneficiaries) != 1 {
+		t.Fatalf("%d beneficiaries, want the three spellings as one: %+v", len(beneficiaries), beneficiaries)
+	}
+	if b := beneficiaries[0]; b.Address != testMixedLower || b.Amount != 3.5 || b.TxCount != 3 {
+		t.Errorf("beneficiary = %s with %v ETH in %d transactions, want %s with 3.5 in 3", b.Address, b.Amount, b.TxCount, testMixedLower)
+	}
+}
@@ -0,0 +1,92 @@
+package analyzer
+
+import "math/big"
+
+// Buckets beneficiaries are grouped into by BucketBeneficiaries
+const (
+	BucketExchanges = "exchanges"
+	BucketContracts = "contracts"
+	BucketWallets   = "wallets"
+	BucketOther     = "other" // Gas fees, contract creations and the zero address
+)
+
+// knownExchanges labels well-known centralized exchange hot wallets
+var knownExchanges = map[string]string{
+	"0x3f5ce5fbfe3e9af3971dd833d26ba9b5c936f0be": "Binance",
+	"0x28c6c06298d514db089934071355e5743bf21d60": "Binance 14",
+	"0x21a31ee1afc51d94c2efccaa2092ad1028285549": "Binance 15",
+	"0xdfd5293d8e347dfe59e90efd55b2956a1343963d": "Binance 16",
+	"0xbe0eb53f46cd790cd13851d5eff43d12404d33e8": "Binance 7",
+	"0xf977814e90da44bfa03b6295a0616a897441acec": "Binance 8",
+	"0x71660c4005ba85c37ccec55d0c4493e66fe775d3": "Coinbase 1",
+	"0x503828976d22510aad0201ac7ec88293211d23da": "Coinbase 2",
+	"0xa9d1e08c7793af67e9d92fe308d5697fb81d3e43": "Coinbase 10",
+	"0x2910543af39aba0cd09dbb2d50200b3e800a63d2": "Kraken",
+	"0x6cc5f688a315f3dc28a7781717a9a798a59fda7b": "OKX",
+}
+
+// BeneficiaryBucket groups the beneficiaries of one kind with their combined totals
+type BeneficiaryBucket struct {
+	Name          string
+	Amount        float64
+	RawAmount     *big.Int // Exact total in Wei
+	USDValue      float64
+	Count         int
+	Beneficiaries []Beneficiary
+}
+
+// sets the addresses, in addition to the built-in exchange wallets, bucketed as exchanges
+func (ba *BeneficiaryAnalyzer) SetExchangeAddresses(exchangeAddresses map[string]bool) {
+	ba.exchangeAddresses = exchangeAddresses
+}
+
+// groups beneficiaries into exchanges, contracts and wallets (EOAs), keeping their order within
+// each bucket. Known exchanges are labeled when unlabeled; contracts are told apart from wallets
+// by IsContract, so the beneficiaries should come from an analysis with DetectContracts.
+func (ba *BeneficiaryAnalyzer) BucketBeneficiaries(beneficiaries []Beneficiary) []BeneficiaryBucket {
+	buckets := []BeneficiaryBucket{
+		{Name: BucketExchanges, RawAmount: new(big.Int)},
+		{Name: BucketContracts, RawAmount: new(big.Int)},
+		{Name: BucketWallets, RawAmount: new(big.Int)},
+		{Name: BucketOther, RawAmount: new(big.Int)},
+	}
+	index := map[string]int{BucketExchanges: 0, BucketContracts: 1, BucketWallets: 2, BucketOther: 3}
+
+	for _, b := range beneficiaries {
+		name, known := ba.beneficiaryBucket(b.Address, b.IsContract)
+		if known != "" && b.Label == "" {
+			b.Label = known
+		}
+
+		bucket := &buckets[index[name]]
+		bucket.Amount += b.Amount
+		bucket.USDValue += b.USDValue
+		bucket.Count++
+		if b.RawAmount != nil {
+			bucket.RawAmount.Add(bucket.RawAmount, b.RawAmount)
+		}
+		bucket.Beneficiaries = append(bucket.Beneficiaries, b)
+	}
+
+	// The other bucket is only reported when something falls into it
+	if buckets[index[BucketOther]].Count == 0 {
+		buckets = buckets[:index[BucketOther]]
+	}
+	return buckets
+}
+
+// beneficiaryBucket returns the bucket of an address and, for built-in exchanges, their name
+func (ba *BeneficiaryAnalyzer) beneficiaryBucket(address string, isContract *bool) (bucket, label string) {
+	if name, ok := knownExchanges[address]; ok {
+		return BucketExchanges, name
+	}
+	switch {
+	case ba.exchangeAddresses[address]:
+		return BucketExchanges, ""
+	case address == "" || address == GasBeneficiary || address == zeroAddress:
+		return BucketOther, ""
+	case isContract != nil && *isContract:
+		return BucketContracts, ""
+	}
+	return BucketWallets, ""
+}
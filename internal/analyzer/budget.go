@@ -0,0 +1,30 @@
+package analyzer
+
+import (
+	"context"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// budget bounds the total time and Etherscan retries of one analysis
+type budget struct {
+	timeout    time.Duration // 0 means no deadline
+	maxRetries int           // Negative means only the per-request attempt limit applies
+}
+
+// unlimitedBudget leaves every fetch to its own retries, as before any budget is set
+var unlimitedBudget = budget{maxRetries: -1}
+
+// client returns a client whose requests share the budget, scoped to the chain when chainID is
+// set, and the function releasing it
+func (b budget) client(c *etherscan.Client, chainID int) (*etherscan.Client, context.CancelFunc) {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if b.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+	}
+	if chainID != 0 {
+		c = c.WithChain(chainID)
+	}
+	return c.WithBudget(ctx, b.maxRetries), cancel
+}
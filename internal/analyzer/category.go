@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"math/big"
+	"sort"
+)
+
+// Label categories beneficiaries are summarized by with SummarizeByCategory
+const (
+	CategoryCEX     = "CEX"
+	CategoryDEX     = "DEX"
+	CategoryWETH    = "WETH"
+	CategoryGas     = "Gas"
+	CategoryBurn    = "Burn"
+	CategoryUnknown = "Unknown"
+)
+
+// knownDEXes labels well-known decentralized exchange routers
+var knownDEXes = map[string]string{
+	"0x7a250d5630b4cf539739df2c5dacb4c659f2488d": "Uniswap V2 Router",
+	"0xe592427a0aece92de3edee1f18e0157c05861564": "Uniswap V3 Router",
+	"0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45": "Uniswap V3 Router 2",
+	"0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad": "Uniswap Universal Router",
+	"0xd9e1ce17f2641f24ae83637ab66a2cca9c378b9f": "SushiSwap Router",
+	"0x1111111254eeb25477b68fb85ed929f73a960582": "1inch v5 Router",
+	"0xdef1c0ded9bec7f1a1670819833240f027b25eff": "0x Exchange Proxy",
+}
+
+// CategoryTotal is the combined outflow to the beneficiaries of one label category
+type CategoryTotal struct {
+	Category  string
+	Amount    float64
+	RawAmount *big.Int // Exact total in Wei
+	USDValue  float64
+	Count     int
+}
+
+// totals the beneficiaries by label category, largest first. Exchanges come from the built-in
+// wallets and EXCHANGE_ADDRESSES, DEXes from the built-in routers; anything uncategorized is
+// counted as Unknown.
+func (ba *BeneficiaryAnalyzer) SummarizeByCategory(beneficiaries []Beneficiary) []CategoryTotal {
+	index := make(map[string]int)
+	var totals []CategoryTotal
+
+	for _, b := range beneficiaries {
+		category := ba.beneficiaryCategory(b.Address)
+		i, ok := index[category]
+		if !ok {
+			i = len(totals)
+			index[category] = i
+			totals = append(totals, CategoryTotal{Category: category, RawAmount: new(big.Int)})
+		}
+
+		total := &totals[i]
+		total.Amount += b.Amount
+		total.USDValue += b.USDValue
+		total.Count++
+		if b.RawAmount != nil {
+			total.RawAmount.Add(total.RawAmount, b.RawAmount)
+		}
+	}
+
+	sort.SliceStable(totals, func(i, j int) bool {
+		return totals[i].Amount > totals[j].Amount
+	})
+	return totals
+}
+
+// beneficiaryCategory returns the label category of an address
+func (ba *BeneficiaryAnalyzer) beneficiaryCategory(address string) string {
+	if _, ok := knownExchanges[address]; ok || ba.exchangeAddresses[address] {
+		return CategoryCEX
+	}
+	if _, ok := knownDEXes[address]; ok {
+		return CategoryDEX
+	}
+	switch {
+	case ba.wethContract != "" && address == ba.wethContract:
+		return CategoryWETH
+	case address == GasBeneficiary:
+		return CategoryGas
+	case address == zeroAddress:
+		return CategoryBurn
+	}
+	return CategoryUnknown
+}
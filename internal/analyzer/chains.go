@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+// ChainAmount is a counterparty's flow on one chain, in that chain's native units
+type ChainAmount struct {
+	ChainID  int     `json:"chain_id"`
+	Amount   float64 `json:"amount"`
+	TxCount  int     `json:"tx_count"`
+	USDValue float64 `json:"usd_value,omitempty"`
+}
+
+// MergeChainBeneficiaries combines the beneficiary analyses of one address on several chains,
+// results[i] being the analysis on chainIDs[i]. A counterparty found on several chains becomes
+// one entry whose Chains break its flow down per chain and whose transactions are tagged with
+// their chain. The merged Amount adds native amounts of different chains; the per-chain
+// amounts, or USDValue when priced, are the comparable figures.
+func MergeChainBeneficiaries(chainIDs []int, results [][]Beneficiary) []Beneficiary {
+	merged := make(map[string]*Beneficiary)
+
+	for i, chainID := range chainIDs {
+		for _, b := range results[i] {
+			for j := range b.Transactions {
+				b.Transactions[j].ChainID = chainID
+			}
+			chain := ChainAmount{ChainID: chainID, Amount: b.Amount, TxCount: b.TxCount, USDValue: b.USDValue}
+
+			m, exists := merged[b.Address]
+			if !exists {
+				b := b
+				b.RawAmount = new(big.Int).Set(b.RawAmount)
+				b.Chains = []ChainAmount{chain}
+				merged[b.Address] = &b
+				continue
+			}
+
+			m.Amount += b.Amount
+			m.RawAmount.Add(m.RawAmount, b.RawAmount)
+			m.Transactions = append(m.Transactions, b.Transactions...)
+			m.USDValue += b.USDValue
+			m.TxCount += b.TxCount
+			m.AvgAmount = m.Amount / float64(m.TxCount)
+			m.MaxAmount = math.Max(m.MaxAmount, b.MaxAmount)
+			m.Chains = append(m.Chains, chain)
+			if m.Label == "" {
+				m.Label = b.Label
+			}
+			m.Sanctioned = m.Sanctioned || b.Sanctioned
+			if b.IsContract != nil && (m.IsContract == nil || *b.IsContract) {
+				m.IsContract = b.IsContract // A contract on any chain
+			}
+		}
+	}
+
+	beneficiaries := make([]Beneficiary, 0, len(merged))
+	for _, b := range merged {
+		beneficiaries = append(beneficiaries, *b)
+	}
+	sort.SliceStable(beneficiaries, func(i, j int) bool {
+		return beneficiaries[i].Amount > beneficiaries[j].Amount
+	})
+	return beneficiaries
+}
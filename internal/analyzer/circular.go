@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// CircularFlow represents value cycling between an address and a counterparty in both
+// directions (A→B→A), which may indicate wash trading or layering
+type CircularFlow struct {
+	Counterparty string  `json:"counterparty"`
+	Outgoing     float64 `json:"outgoing"`
+	Incoming     float64 `json:"incoming"`
+	// RoundTrip is the volume that went out and came back, the smaller of the two directions
+	RoundTrip float64 `json:"round_trip"`
+	// Net is the incoming minus the outgoing amount from the address's point of view
+	Net float64 `json:"net"`
+}
+
+// detectCircularFlows finds the counterparties with Ether flowing both to and from the
+// address, largest round-trip volume first. Token transfers are left out since amounts of
+// different tokens can't be added up.
+func detectCircularFlows(address string, txs *transactionSet) []CircularFlow {
+	flows := make(map[string]*CircularFlow)
+
+	add := func(from, to, valueStr string) {
+		outgoing := strings.EqualFold(from, address)
+		incoming := strings.EqualFold(to, address)
+		if outgoing == incoming { // Unrelated or a self-transfer
+			return
+		}
+
+		amount, err := weiToEther(valueStr)
+		if err != nil || amount == 0 {
+			return
+		}
+
+		counterparty := strings.ToLower(from)
+		if outgoing {
+			counterparty = strings.ToLower(to)
+		}
+
+		flow, exists := flows[counterparty]
+		if !exists {
+			flow = &CircularFlow{Counterparty: counterparty}
+			flows[counterparty] = flow
+		}
+		if outgoing {
+			flow.Outgoing += amount
+		} else {
+			flow.Incoming += amount
+		}
+	}
+
+	for _, tx := range txs.normal {
+		if tx.IsError == "0" {
+			add(tx.From, tx.To, tx.Value)
+		}
+	}
+	for _, tx := range txs.internal {
+		if tx.IsError == "0" {
+			add(tx.From, tx.To, tx.Value)
+		}
+	}
+
+	var circular []CircularFlow
+	for _, flow := range flows {
+		if flow.Outgoing == 0 || flow.Incoming == 0 {
+			continue
+		}
+		flow.RoundTrip = math.Min(flow.Outgoing, flow.Incoming)
+		flow.Net = flow.Incoming - flow.Outgoing
+		circular = append(circular, *flow)
+	}
+
+	sort.SliceStable(circular, func(i, j int) bool {
+		return circular[i].RoundTrip > circular[j].RoundTrip
+	})
+
+	return circular
+}
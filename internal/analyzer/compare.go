@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+)
+
+// CounterpartyAmount is a counterparty and the total amount one address sent it
+type CounterpartyAmount struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"`
+}
+
+// SharedCounterparty is a beneficiary of both compared addresses with the amount each sent it
+type SharedCounterparty struct {
+	Address string  `json:"address"`
+	AmountA float64 `json:"amount_a"`
+	AmountB float64 `json:"amount_b"`
+}
+
+// Comparison splits the beneficiaries of two addresses into those they share and those unique to each
+type Comparison struct {
+	A      string               `json:"a"`
+	B      string               `json:"b"`
+	Shared []SharedCounterparty `json:"shared"`
+	OnlyA  []CounterpartyAmount `json:"only_a"`
+	OnlyB  []CounterpartyAmount `json:"only_b"`
+}
+
+// CompareBeneficiaries compares the beneficiaries of addresses a and b. Shared counterparties
+// are ordered by combined amount and the unique ones by amount, largest first.
+func CompareBeneficiaries(a, b string, beneficiariesA, beneficiariesB []Beneficiary) *Comparison {
+	comparison := &Comparison{
+		A:      strings.ToLower(a),
+		B:      strings.ToLower(b),
+		Shared: []SharedCounterparty{},
+		OnlyA:  []CounterpartyAmount{},
+		OnlyB:  []CounterpartyAmount{},
+	}
+
+	amountsB := make(map[string]float64, len(beneficiariesB))
+	for _, beneficiary := range beneficiariesB {
+		amountsB[beneficiary.Address] = beneficiary.Amount
+	}
+
+	inA := make(map[string]bool, len(beneficiariesA))
+	for _, beneficiary := range beneficiariesA {
+		inA[beneficiary.Address] = true
+		if amountB, ok := amountsB[beneficiary.Address]; ok {
+			comparison.Shared = append(comparison.Shared, SharedCounterparty{
+				Address: beneficiary.Address,
+				AmountA: beneficiary.Amount,
+				AmountB: amountB,
+			})
+		} else {
+			comparison.OnlyA = append(comparison.OnlyA, CounterpartyAmount{Address: beneficiary.Address, Amount: beneficiary.Amount})
+		}
+	}
+
+	for _, beneficiary := range beneficiariesB {
+		if !inA[beneficiary.Address] {
+			comparison.OnlyB = append(comparison.OnlyB, CounterpartyAmount{Address: beneficiary.Address, Amount: beneficiary.Amount})
+		}
+	}
+
+	sort.SliceStable(comparison.Shared, func(i, j int) bool {
+		return comparison.Shared[i].AmountA+comparison.Shared[i].AmountB > comparison.Shared[j].AmountA+comparison.Shared[j].AmountB
+	})
+	sortByAmount(comparison.OnlyA)
+	sortByAmount(comparison.OnlyB)
+
+	return comparison
+}
+
+// sortByAmount orders counterparties by amount, largest first
+func sortByAmount(counterparties []CounterpartyAmount) {
+	sort.SliceStable(counterparties, func(i, j int) bool {
+		return counterparties[i].Amount > counterparties[j].Amount
+	})
+}
@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// withConfirmedBlock sets the last block with at least MinConfirmations confirmations on the
+// options, from a fresh lookup of the latest block. Every transaction type is checked against
+// it rather than against the confirmation count Etherscan reported, which a cached response
+// keeps frozen at the count it had when fetched.
+func withConfirmedBlock(client *etherscan.Client, opts Options) (Options, error) {
+	if opts.MinConfirmations == 0 {
+		return opts, nil
+	}
+
+	latest, err := client.GetLatestBlockNumber()
+	if err != nil {
+		return opts, fmt.Errorf("error fetching latest block for min_confirmations: %w", err)
+	}
+	opts.confirmedBlock = latest - opts.MinConfirmations + 1
+	return opts, nil
+}
+
+// confirmed reports whether a transaction mined in the given block has at least
+// MinConfirmations confirmations. Transactions whose block can't be parsed are treated as
+// unconfirmed.
+func (o Options) confirmed(blockNumber string) bool {
+	if o.MinConfirmations == 0 {
+		return true
+	}
+
+	block, err := strconv.Atoi(blockNumber)
+	return err == nil && block <= o.confirmedBlock
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// atBlock returns the transaction as mined in block, with Etherscan's confirmation count
+func atBlock(tx etherscan.Transaction, block, confirmations string) etherscan.Transaction {
+	tx.BlockNumber, tx.Confirmations = block, confirmations
+	return tx
+}
+
+// transferAtBlock returns the token transfer as mined in block, with Etherscan's confirmation count
+func transferAtBlock(transfer etherscan.TokenTransfer, block, confirmations string) etherscan.TokenTransfer {
+	transfer.BlockNumber, transfer.Confirmations = block, confirmations
+	return transfer
+}
+
+func TestMinConfirmationsCountsFromLatestBlock(t *testing.T) {
+	// At head 200, blocks up to 189 have 12 confirmations. The reported counts are stale, as a
+	// cached list's are: block 100 was fetched with 1 confirmation, block 195 claims thousands.
+	client := newTestClient(t, testTransactions{
+		latestBlock: 200,
+		normal: []etherscan.Transaction{
+			atBlock(testTransaction("0xa1", testAddress, testAlice, oneEther), "100", "1"),
+			atBlock(testTransaction("0xa2", testAddress, testAlice, oneEther), "195", "5000"),
+		},
+		internal: []etherscan.Transaction{
+			atBlock(testTransaction("0xa3", testAddress, testAlice, oneEther), "189", ""),
+			atBlock(testTransaction("0xa4", testAddress, testAlice, oneEther), "190", ""),
+		},
+		tokens: []etherscan.TokenTransfer{
+			transferAtBlock(testTokenTransfer("0xa5", testBob, testAddress, testAlice, oneEther), "150", "3"),
+			transferAtBlock(testTokenTransfer("0xa6", testBob, testAddress, testAlice, oneEther), "200", "5000"),
+		},
+	})
+
+	beneficiaries, err := NewBeneficiaryAnalyzer(client).AnalyzeBeneficiary(testAddress, Options{MinConfirmations: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice := findBeneficiary(t, beneficiaries, testAlice)
+	kept := make(map[string]bool)
+	for _, tx := range alice.Transactions {
+		kept[tx.TransactionID] = true
+	}
+	for hash, want := range map[string]bool{"0xa1": true, "0xa2": false, "0xa3": true, "0xa4": false, "0xa5": true, "0xa6": false} {
+		if kept[hash] != want {
+			t.Errorf("transaction %s kept = %v, want %v", hash, kept[hash], want)
+		}
+	}
+}
+
+func TestMinConfirmationsAppliesToPayers(t *testing.T) {
+	client := newTestClient(t, testTransactions{
+		latestBlock: 200,
+		normal: []etherscan.Transaction{
+			atBlock(testTransaction("0xb1", testBob, testAddress, oneEther), "100", "1"),
+			atBlock(testTransaction("0xb2", testBob, testAddress, oneEther), "195", "5000"),
+		},
+	})
+
+	payers, err := NewPayerAnalyzer(client).AnalyzePayer(testAddress, Options{MinConfirmations: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob := findPayer(t, payers, testBob)
+	if len(bob.Transactions) != 1 || bob.Transactions[0].TransactionID != "0xb1" {
+		t.Errorf("transactions = %+v, want only 0xb1", bob.Transactions)
+	}
+}
@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"golang.org/x/sync/errgroup"
+)
+
+// contractLookupWorkers bounds concurrent contract lookups so they don't exhaust the Etherscan quota
+const contractLookupWorkers = 3
+
+// contractInfo describes whether an address is a contract and, if verified, its name
+type contractInfo struct {
+	isContract bool
+	name       string
+}
+
+// lookupContracts determines which addresses are contracts and the names of verified
+// contracts, keyed by lowercase address
+func lookupContracts(client *etherscan.Client, addresses []string) (map[string]contractInfo, error) {
+	contracts := make(map[string]contractInfo, len(addresses))
+	var mu sync.Mutex
+
+	eg := errgroup.Group{}
+	eg.SetLimit(contractLookupWorkers)
+
+	for _, address := range addresses {
+		address := address
+		eg.Go(func() error {
+			isContract, err := client.IsContract(address)
+			if err != nil {
+				return fmt.Errorf("error detecting contract %s: %w", address, err)
+			}
+
+			info := contractInfo{isContract: isContract}
+			if isContract {
+				name, verified, err := client.GetContractName(address)
+				if err != nil {
+					return fmt.Errorf("error fetching contract name %s: %w", address, err)
+				}
+				if verified {
+					info.name = name
+				}
+			}
+
+			mu.Lock()
+			contracts[strings.ToLower(address)] = info
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return contracts, nil
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// DirectFlow represents the value moved directly from one address to another
+type DirectFlow struct {
+	From         string               `json:"from"`
+	To           string               `json:"to"`
+	Amount       float64              `json:"amount"`
+	TxCount      int                  `json:"tx_count"`
+	Transactions []TransactionDetails `json:"transactions"`
+}
+
+// CounterpartyFlow represents the flows between two specific addresses
+type CounterpartyFlow struct {
+	Forward DirectFlow  `json:"forward"`
+	Reverse *DirectFlow `json:"reverse,omitempty"`
+}
+
+// analyzes the direct flow of value from one address to another, optionally including the
+// reverse direction. Only the source address's transactions are fetched.
+func (fa *FlowAnalyzer) AnalyzeCounterparty(from, to string, includeReverse bool, opts Options) (*CounterpartyFlow, error) {
+	txs, err := fetchTransactionSet(fa.etherscanClient, from, opts.withoutTypes(fa.disabledTypes))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CounterpartyFlow{
+		Forward: collectDirectFlow(txs, from, to, opts.Location),
+	}
+	if includeReverse {
+		reverse := collectDirectFlow(txs, to, from, opts.Location)
+		result.Reverse = &reverse
+	}
+
+	return result, nil
+}
+
+// collectDirectFlow gathers the transactions in the set that move value from one address to another
+func collectDirectFlow(txs *transactionSet, from, to string, loc *time.Location) DirectFlow {
+	flow := DirectFlow{
+		From:         from,
+		To:           to,
+		Transactions: []TransactionDetails{},
+	}
+
+	add := func(txFrom, txTo, valueStr, hash, timestampStr string) {
+		if !strings.EqualFold(txFrom, from) || !strings.EqualFold(txTo, to) {
+			return
+		}
+
+		details, err := newTransactionDetails(valueStr, hash, timestampStr, loc)
+		if err != nil {
+			return
+		}
+		flow.Amount += details.TxAmount
+		flow.TxCount++
+		flow.Transactions = append(flow.Transactions, details)
+	}
+
+	for _, tx := range txs.normal {
+		if tx.IsError == "0" {
+			add(tx.From, tx.To, tx.Value, tx.Hash, tx.TimeStamp)
+		}
+	}
+	for _, tx := range txs.internal {
+		if tx.IsError == "0" {
+			add(tx.From, tx.To, tx.Value, tx.Hash, tx.TimeStamp)
+		}
+	}
+	for _, transfer := range txs.tokens {
+		add(transfer.From, transfer.To, transfer.Value, transfer.Hash, transfer.TimeStamp)
+	}
+
+	return flow
+}
+
+// newTransactionDetails builds the transaction details for a raw value, hash and timestamp
+func newTransactionDetails(valueStr, hash, timestampStr string, loc *time.Location) (TransactionDetails, error) {
+	amount, err := weiToEther(valueStr)
+	if err != nil {
+		return TransactionDetails{}, err
+	}
+
+	dateTime, err := etherscan.FormatTime(timestampStr, loc)
+	if err != nil {
+		dateTime = timestampStr // Use original timestamp if formatting fails
+	}
+
+	return TransactionDetails{
+		TxAmount:      amount,
+		DateTime:      dateTime,
+		TransactionID: hash,
+		RawValue:      decimalValue(valueStr),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"math"
+	"math/big"
+)
+
+// DedupeBeneficiaries collapses each beneficiary's transaction entries that share a hash,
+// such as a swap appearing as both a normal transaction and a token transfer, summing their amounts
+func DedupeBeneficiaries(beneficiaries []Beneficiary) {
+	for i := range beneficiaries {
+		b := &beneficiaries[i]
+		b.Transactions = dedupeTransactions(b.Transactions)
+		b.TxCount, b.AvgAmount, b.MaxAmount = transactionStats(b.Amount, b.Transactions)
+	}
+}
+
+// DedupePayers collapses each payer's transaction entries that share a hash, summing their amounts
+func DedupePayers(payers []Payer) {
+	for i := range payers {
+		p := &payers[i]
+		p.Transactions = dedupeTransactions(p.Transactions)
+		p.TxCount, p.AvgAmount, p.MaxAmount = transactionStats(p.Amount, p.Transactions)
+	}
+}
+
+// dedupeTransactions merges entries with the same transaction ID into the first one, keeping its position
+func dedupeTransactions(transactions []TransactionDetails) []TransactionDetails {
+	index := make(map[string]int, len(transactions))
+	deduped := make([]TransactionDetails, 0, len(transactions))
+
+	for _, tx := range transactions {
+		i, seen := index[tx.TransactionID]
+		if !seen {
+			index[tx.TransactionID] = len(deduped)
+			deduped = append(deduped, tx)
+			continue
+		}
+
+		deduped[i].TxAmount += tx.TxAmount
+		deduped[i].USDValue += tx.USDValue
+		raw := new(big.Int)
+		addRawValue(raw, deduped[i].RawValue)
+		addRawValue(raw, tx.RawValue)
+		deduped[i].RawValue = raw.String()
+	}
+
+	return deduped
+}
+
+// transactionStats returns the count, average and largest amount of a counterparty's transactions
+func transactionStats(total float64, transactions []TransactionDetails) (count int, avg, max float64) {
+	for _, tx := range transactions {
+		max = math.Max(max, tx.TxAmount)
+	}
+	if len(transactions) > 0 {
+		avg = total / float64(len(transactions))
+	}
+	return len(transactions), avg, max
+}
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+func TestDedupeTransactions(t *testing.T) {
+	transactions := []TransactionDetails{
+		{TransactionID: "0xa1", TxAmount: 1, RawValue: "1000000000000000000", USDValue: 2000},
+		{TransactionID: "0xb1", TxAmount: 2, RawValue: "2000000000000000000"},
+		{TransactionID: "0xa1", TxAmount: 0.5, RawValue: "0x6f05b59d3b20000", USDValue: 1},
+	}
+
+	deduped := dedupeTransactions(transactions)
+	if len(deduped) != 2 || deduped[0].TransactionID != "0xa1" || deduped[1].TransactionID != "0xb1" {
+		t.Fatalf("deduped = %+v, want 0xa1 then 0xb1", deduped)
+	}
+	if got := deduped[0]; got.TxAmount != 1.5 || got.RawValue != "1500000000000000000" || got.USDValue != 2001 {
+		t.Errorf("0xa1 = %v (raw %s, %v USD), want 1.5 (raw 1500000000000000000, 2001 USD)", got.TxAmount, got.RawValue, got.USDValue)
+	}
+	if got := deduped[1]; got.TxAmount != 2 || got.RawValue != "2000000000000000000" {
+		t.Errorf("0xb1 = %v (raw %s), want it unchanged", got.TxAmount, got.RawValue)
+	}
+}
+
+func TestDedupeBeneficiariesHashInTwoLists(t *testing.T) {
+	// A swap: 1 ETH sent to alice's contract and 4 tokens from it in the same transaction
+	client := newTestClient(t, testTransactions{
+		normal: []etherscan.Transaction{
+			testTransaction("0xa1", testAddress, testAlice, "1000000000000000000"),
+			testTransaction("0xa2", testAddress, testAlice, "2000000000000000000"),
+		},
+		tokens: []etherscan.TokenTransfer{
+			testTokenTransfer("0xa1", testBob, testAddress, testAlice, "4000000000000000000"),
+		},
+	})
+
+	beneficiaries, err := NewBeneficiaryAnalyzer(client).AnalyzeBeneficiary(testAddress, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	DedupeBeneficiaries(beneficiaries)
+
+	alice := findBeneficiary(t, beneficiaries, testAlice)
+	if alice.TxCount != 2 || len(alice.Transactions) != 2 {
+		t.Fatalf("alice = %d transactions (%d listed), want 2", alice.TxCount, len(alice.Transactions))
+	}
+	if alice.Amount != 7 || alice.AvgAmount != 3.5 || alice.MaxAmount != 5 {
+		t.Errorf("alice amount %v, avg %v, max %v, want 7, 3.5 and 5", alice.Amount, alice.AvgAmount, alice.MaxAmount)
+	}
+	for _, tx := range alice.Transactions {
+		if tx.TransactionID == "0xa1" && tx.TxAmount != 5 {
+			t.Errorf("0xa1 = %v, want the normal and token entries summed to 5", tx.TxAmount)
+		}
+	}
+}
+
+func TestDedupePayersHashInTwoLists(t *testing.T) {
+	client := newTestClient(t, testTransactions{
+		normal: []etherscan.Transaction{
+			testTransaction("0xb1", testBob, testAddress, "1000000000000000000"),
+		},
+		internal: []etherscan.Transaction{
+			testTransaction("0xb1", testBob, testAddress, "3000000000000000000"),
+		},
+	})
+
+	payers, err := NewPayerAnalyzer(client).AnalyzePayer(testAddress, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	DedupePayers(payers)
+
+	bob := findPayer(t, payers, testBob)
+	if bob.TxCount != 1 || len(bob.Transactions) != 1 || bob.Transactions[0].TxAmount != 4 {
+		t.Errorf("bob = %d transactions %+v, want a single one of 4", bob.TxCount, bob.Transactions)
+	}
+	if bob.Amount != 4 || bob.MaxAmount != 4 {
+		t.Errorf("bob amount %v, max %v, want 4 and 4", bob.Amount, bob.MaxAmount)
+	}
+}
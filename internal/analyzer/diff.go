@@ -0,0 +1,37 @@
+package analyzer
+
+import "strings"
+
+// NewBeneficiaries returns the beneficiaries whose address is not among the known ones,
+// keeping their order
+func NewBeneficiaries(beneficiaries []Beneficiary, known []string) []Beneficiary {
+	seen := addressSet(known)
+	fresh := []Beneficiary{}
+	for _, b := range beneficiaries {
+		if !seen[strings.ToLower(b.Address)] {
+			fresh = append(fresh, b)
+		}
+	}
+	return fresh
+}
+
+// NewPayers returns the payers whose address is not among the known ones, keeping their order
+func NewPayers(payers []Payer, known []string) []Payer {
+	seen := addressSet(known)
+	fresh := []Payer{}
+	for _, p := range payers {
+		if !seen[strings.ToLower(p.Address)] {
+			fresh = append(fresh, p)
+		}
+	}
+	return fresh
+}
+
+// addressSet returns the lowercased addresses as a set
+func addressSet(addresses []string) map[string]bool {
+	set := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		set[strings.ToLower(address)] = true
+	}
+	return set
+}
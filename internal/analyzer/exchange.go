@@ -0,0 +1,76 @@
+package analyzer
+
+import "strings"
+
+// FlagExchangeInternal marks a counterparty whose flows with the analyzed address look like an
+// exchange moving funds between its own hot and cold wallets
+const FlagExchangeInternal = "exchange_internal"
+
+// exchangeMovementDigits is the significant digits of the round amounts exchange wallets move
+const exchangeMovementDigits = 2
+
+// ExchangeMovementThresholds configures the detection of internal exchange movements
+type ExchangeMovementThresholds struct {
+	// MinAmount is the smallest transfer between two unnamed exchange wallets treated as an
+	// internal movement
+	MinAmount float64
+	// RoundTolerance is the relative distance from a round number still treated as round
+	RoundTolerance float64
+}
+
+// IsExchangeInternal reports whether the flows between the analyzed address and a counterparty
+// are likely internal movements of one exchange. Built-in wallets of the same exchange always
+// are. Other exchange wallets, including the configured exchanges set whose owners are unnamed,
+// are when every transfer is large and most are round amounts.
+func IsExchangeInternal(address, counterparty string, exchanges map[string]bool, transactions []TransactionDetails, thresholds ExchangeMovementThresholds) bool {
+	address, counterparty = strings.ToLower(address), strings.ToLower(counterparty)
+	if address == counterparty {
+		return false
+	}
+
+	addressExchange, counterpartyExchange := exchangeName(address), exchangeName(counterparty)
+	if addressExchange != "" && addressExchange == counterpartyExchange {
+		return true
+	}
+	if addressExchange != "" && counterpartyExchange != "" {
+		return false // Wallets of two different exchanges
+	}
+	if !isExchangeWallet(address, exchanges) || !isExchangeWallet(counterparty, exchanges) {
+		return false
+	}
+	return largeRoundTransfers(transactions, thresholds)
+}
+
+// exchangeName returns the exchange operating a built-in exchange wallet, e.g. "Binance" for
+// "Binance 14", or "" when the address isn't one
+func exchangeName(address string) string {
+	if fields := strings.Fields(knownExchanges[address]); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// isExchangeWallet reports whether the address is a built-in or configured exchange wallet
+func isExchangeWallet(address string, exchanges map[string]bool) bool {
+	_, known := knownExchanges[address]
+	return known || exchanges[address]
+}
+
+// largeRoundTransfers reports whether every transfer is at least MinAmount and most of them
+// are round amounts, the pattern of an exchange rebalancing its wallets
+func largeRoundTransfers(transactions []TransactionDetails, thresholds ExchangeMovementThresholds) bool {
+	if len(transactions) == 0 {
+		return false
+	}
+
+	round := 0
+	for _, tx := range transactions {
+		if tx.TxAmount <= 0 || tx.TxAmount < thresholds.MinAmount {
+			return false
+		}
+		if isRoundToDigits(tx.TxAmount, exchangeMovementDigits, thresholds.RoundTolerance) {
+			round++
+		}
+	}
+	return round*2 > len(transactions)
+}
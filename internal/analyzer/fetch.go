@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"golang.org/x/sync/errgroup"
+)
+
+// transactionSet holds every transaction type fetched for an address
+type transactionSet struct {
+	normal   []etherscan.Transaction
+	internal []etherscan.Transaction
+	tokens   []etherscan.TokenTransfer
+}
+
+// fetchTransactionSet fetches normal transactions, internal transactions and token transfers
+// for an address within the options' block range concurrently
+func fetchTransactionSet(client *etherscan.Client, address string, opts Options) (*transactionSet, error) {
+	return fetchSet(client, address, opts, true)
+}
+
+// fetchTransferSet fetches only internal transactions and token transfers, for callers that
+// stream normal transactions separately
+func fetchTransferSet(client *etherscan.Client, address string, opts Options) (*transactionSet, error) {
+	return fetchSet(client, address, opts, false)
+}
+
+// fetchSet fetches the transaction types of an address concurrently, skipping the calls for
+// types the options leave out
+func fetchSet(client *etherscan.Client, address string, opts Options, includeNormal bool) (*transactionSet, error) {
+	txs := &transactionSet{}
+	eg := errgroup.Group{}
+
+	if includeNormal && opts.includes(MovementNormal) {
+		eg.Go(func() error {
+			normalTxs, err := client.GetNormalTransactions(address, opts.FromBlock, opts.ToBlock)
+			if err != nil {
+				return fmt.Errorf("error fetching normal transactions: %w", err)
+			}
+			txs.normal = normalTxs
+			return nil
+		})
+	}
+
+	if opts.includes(MovementInternal) {
+		eg.Go(func() error {
+			internalTxs, err := client.GetInternalTransactions(address, opts.FromBlock, opts.ToBlock)
+			if err != nil {
+				return fmt.Errorf("error fetching internal transactions: %w", err)
+			}
+			txs.internal = internalTxs
+			return nil
+		})
+	}
+
+	if opts.includes(MovementToken) {
+		eg.Go(func() error {
+			tokenTransfers, err := client.GetTokenTransfers(address, opts.FromBlock, opts.ToBlock)
+			if err != nil {
+				return fmt.Errorf("error fetching token transfers: %w", err)
+			}
+			txs.tokens = tokenTransfers
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// forEachNormalTransaction calls fn for each normal transaction of the address. In streaming
+// mode the complete history is walked page by page so only one page is held at a time;
+// otherwise the already fetched transactions are used.
+func forEachNormalTransaction(client *etherscan.Client, address string, opts Options, txs *transactionSet, fn func(tx etherscan.Transaction)) error {
+	if !opts.Stream || !opts.includes(MovementNormal) {
+		for _, tx := range txs.normal {
+			fn(tx)
+		}
+		return nil
+	}
+
+	err := client.StreamNormalTransactions(address, opts.FromBlock, opts.ToBlock, func(page []etherscan.Transaction) error {
+		for _, tx := range page {
+			fn(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error streaming normal transactions: %w", err)
+	}
+	return nil
+}
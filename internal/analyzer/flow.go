@@ -0,0 +1,23 @@
+package analyzer
+
+import (
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// responsible for analyzing the combined incoming and outgoing flow of an address
+type FlowAnalyzer struct {
+	etherscanClient *etherscan.Client
+	disabledTypes   map[string]bool
+}
+
+// creates a new flow analyzer
+func NewFlowAnalyzer(etherscanClient *etherscan.Client) *FlowAnalyzer {
+	return &FlowAnalyzer{
+		etherscanClient: etherscanClient,
+	}
+}
+
+// sets the transaction types (MovementInternal, MovementToken, ...) never fetched, whatever the request asks for
+func (fa *FlowAnalyzer) SetDisabledTypes(disabled map[string]bool) {
+	fa.disabledTypes = disabled
+}
@@ -0,0 +1,35 @@
+package analyzer
+
+import (
+	"math/big"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// GasBeneficiary is the pseudo-address of the synthetic beneficiary summing gas fees (include_gas)
+const GasBeneficiary = "gas"
+
+// gasLabel labels the gas beneficiary
+const gasLabel = "Network / Gas"
+
+// kindGas marks a transaction entry of the gas beneficiary
+const kindGas = "gas"
+
+// gasFee returns the fee in Wei a normal transaction paid (gas used times gas price), or an
+// empty string when it is unknown or zero
+func gasFee(tx etherscan.Transaction) string {
+	gasUsed, err := parseRawValue(tx.GasUsed)
+	if err != nil {
+		return ""
+	}
+	gasPrice, err := parseRawValue(tx.GasPrice)
+	if err != nil {
+		return ""
+	}
+
+	fee := new(big.Int).Mul(gasUsed, gasPrice)
+	if fee.Sign() == 0 {
+		return ""
+	}
+	return fee.String()
+}
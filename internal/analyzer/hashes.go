@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// hashWorkers bounds how many transactions of a hash list are mapped concurrently
+const hashWorkers = 5
+
+// maps the fund flow of each transaction in the list, in the order given, looking up the
+// timestamps of their blocks so their movements can be aggregated
+func (fa *FlowAnalyzer) AnalyzeTransactions(hashes []string) ([]TransactionFlow, error) {
+	flows := make([]TransactionFlow, len(hashes))
+
+	eg := errgroup.Group{}
+	eg.SetLimit(hashWorkers)
+	for i, hash := range hashes {
+		i, hash := i, hash
+		eg.Go(func() error {
+			flow, err := fa.AnalyzeTransaction(hash)
+			if err != nil {
+				return fmt.Errorf("transaction %s: %w", hash, err)
+			}
+			flows[i] = *flow
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Transactions of the same block share one lookup
+	timestamps := make(map[int64]string)
+	for _, flow := range flows {
+		timestamps[flow.BlockNumber] = ""
+	}
+	var mu sync.Mutex
+	eg = errgroup.Group{}
+	eg.SetLimit(hashWorkers)
+	for block := range timestamps {
+		block := block
+		eg.Go(func() error {
+			timestamp, err := fa.etherscanClient.GetBlockTimestamp(block)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			timestamps[block] = timestamp
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	for i := range flows {
+		flows[i].timestamp = timestamps[flows[i].BlockNumber]
+	}
+	return flows, nil
+}
+
+// aggregates the value movements of a list of transactions by recipient, like an address's
+// beneficiaries. NFT transfers carry a token ID rather than an amount and are left out.
+func (ba *BeneficiaryAnalyzer) BeneficiariesFromFlows(flows []TransactionFlow, opts Options) []Beneficiary {
+	// Transaction types disabled for the deployment are left out as in the address analyses
+	opts = opts.withoutTypes(ba.disabledTypes)
+	opts.nativeDecimals = ba.etherscanClient.NativeDecimals()
+
+	beneficiaryMap := make(map[string]*Beneficiary)
+	for _, flow := range flows {
+		for _, movement := range flow.Movements {
+			if !aggregatedMovement(movement, ba.spamContracts, opts) {
+				continue
+			}
+			ba.processBeneficiary(beneficiaryMap, movement.To, "", "", "", movement.RawValue, flow.Hash, flow.timestamp,
+				0, movementDecimals(movement, opts), opts.Location)
+		}
+	}
+
+	// Drop the user's own addresses and any excluded for this request
+	for addr := range beneficiaryMap {
+		if ba.ownAddresses[addr] || opts.Exclude[addr] {
+			delete(beneficiaryMap, addr)
+		}
+	}
+	// Funds sent to the zero address are burned rather than received by anyone
+	if burned, ok := beneficiaryMap[zeroAddress]; ok {
+		if opts.ExcludeBurns {
+			delete(beneficiaryMap, zeroAddress)
+		} else {
+			burned.Label = burnAddressLabel
+		}
+	}
+
+	beneficiaries := make([]Beneficiary, 0, len(beneficiaryMap))
+	for _, beneficiary := range beneficiaryMap {
+		beneficiary.Sanctioned = ba.sanctioned[beneficiary.Address]
+		beneficiaries = append(beneficiaries, *beneficiary)
+	}
+	sort.SliceStable(beneficiaries, func(i, j int) bool {
+		return beneficiaries[i].Amount > beneficiaries[j].Amount
+	})
+	return beneficiaries
+}
+
+// aggregates the value movements of a list of transactions by sender, like an address's payers.
+// NFT transfers carry a token ID rather than an amount and are left out.
+func (pa *PayerAnalyzer) PayersFromFlows(flows []TransactionFlow, opts Options) []Payer {
+	// Transaction types disabled for the deployment are left out as in the address analyses
+	opts = opts.withoutTypes(pa.disabledTypes)
+	opts.nativeDecimals = pa.etherscanClient.NativeDecimals()
+
+	payerMap := make(map[string]*Payer)
+	for _, flow := range flows {
+		for _, movement := range flow.Movements {
+			if !aggregatedMovement(movement, pa.spamContracts, opts) {
+				continue
+			}
+			pa.processPayer(payerMap, movement.From, "", "", "", "", movement.RawValue, flow.Hash, flow.timestamp,
+				0, movementDecimals(movement, opts), opts.Location)
+		}
+	}
+
+	// Drop the user's own addresses and any excluded for this request
+	for addr := range payerMap {
+		if pa.ownAddresses[addr] || opts.Exclude[addr] {
+			delete(payerMap, addr)
+		}
+	}
+
+	// Funds received from the zero address were minted rather than paid by anyone
+	if minted, ok := payerMap[zeroAddress]; ok {
+		minted.Label = mintAddressLabel
+	}
+
+	payers := make([]Payer, 0, len(payerMap))
+	for _, payer := range payerMap {
+		payer.Sanctioned = pa.sanctioned[payer.Address]
+		payers = append(payers, *payer)
+	}
+	sort.SliceStable(payers, func(i, j int) bool {
+		return payers[i].Amount > payers[j].Amount
+	})
+	return payers
+}
+
+// aggregatedMovement reports whether a movement counts towards the aggregated view: an Ether or
+// ERC-20 movement of an included type, not of a denylisted spam token unless asked for
+func aggregatedMovement(movement ValueMovement, spamContracts map[string]bool, opts Options) bool {
+	switch movement.Type {
+	case MovementNFT:
+		return false
+	case MovementToken:
+		if !opts.IncludeSpam && spamContracts[strings.ToLower(movement.TokenContract)] {
+			return false
+		}
+	}
+	return opts.includes(movement.Type)
+}
+
+// movementDecimals returns the decimals of a movement's raw value: the native token's for
+// Ether movements, and 18 for token transfers as in the address analyses
+func movementDecimals(movement ValueMovement, opts Options) int {
+	if movement.Type == MovementToken {
+		return etherDecimals
+	}
+	return opts.native()
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// Which transactions survive when a counterparty's transactions are capped
+const (
+	KeepLargest = "largest"
+	KeepRecent  = "recent"
+)
+
+// CapBeneficiaries limits the number of beneficiaries and the transactions kept per beneficiary.
+// Beneficiaries are expected to be sorted by significance so the most significant survive.
+// keep selects which transactions survive (KeepLargest or KeepRecent); totals and tx_count
+// still cover all of them. A limit of 0 means unlimited. It reports whether anything was dropped.
+func CapBeneficiaries(beneficiaries []Beneficiary, maxCounterparties, maxTransactions int, keep string) ([]Beneficiary, bool) {
+	truncated := false
+	if maxCounterparties > 0 && len(beneficiaries) > maxCounterparties {
+		beneficiaries = beneficiaries[:maxCounterparties]
+		truncated = true
+	}
+
+	for i := range beneficiaries {
+		var capped bool
+		beneficiaries[i].Transactions, capped = capTransactions(beneficiaries[i].Transactions, maxTransactions, keep)
+		truncated = truncated || capped
+	}
+
+	return beneficiaries, truncated
+}
+
+// CapPayers limits the number of payers and the transactions kept per payer.
+// Payers are expected to be sorted by significance so the most significant survive.
+// keep selects which transactions survive (KeepLargest or KeepRecent); totals and tx_count
+// still cover all of them. A limit of 0 means unlimited. It reports whether anything was dropped.
+func CapPayers(payers []Payer, maxCounterparties, maxTransactions int, keep string) ([]Payer, bool) {
+	truncated := false
+	if maxCounterparties > 0 && len(payers) > maxCounterparties {
+		payers = payers[:maxCounterparties]
+		truncated = true
+	}
+
+	for i := range payers {
+		var capped bool
+		payers[i].Transactions, capped = capTransactions(payers[i].Transactions, maxTransactions, keep)
+		truncated = truncated || capped
+	}
+
+	return payers, truncated
+}
+
+// capTransactions keeps the largest (or most recent) transactions when there are more than max
+func capTransactions(transactions []TransactionDetails, max int, keep string) ([]TransactionDetails, bool) {
+	if max <= 0 || len(transactions) <= max {
+		return transactions, false
+	}
+
+	kept := make([]TransactionDetails, len(transactions))
+	copy(kept, transactions)
+	if keep == KeepRecent {
+		sort.SliceStable(kept, func(i, j int) bool {
+			return transactionTime(kept[i]).After(transactionTime(kept[j]))
+		})
+	} else {
+		sort.SliceStable(kept, func(i, j int) bool {
+			return kept[i].TxAmount > kept[j].TxAmount
+		})
+	}
+
+	return kept[:max], true
+}
+
+// transactionTime returns when a transaction happened (the zero time if its date_time is unparseable)
+func transactionTime(tx TransactionDetails) time.Time {
+	t, _ := time.Parse(time.RFC3339, tx.DateTime)
+	return t
+}
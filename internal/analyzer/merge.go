@@ -0,0 +1,49 @@
+package analyzer
+
+import "math"
+
+// mergeBeneficiaryMaps adds the beneficiaries aggregated in src into dst. Transactions in src
+// follow those already in dst, and a label already set in dst is kept.
+func mergeBeneficiaryMaps(dst, src map[string]*Beneficiary) {
+	for addr, s := range src {
+		d, exists := dst[addr]
+		if !exists {
+			dst[addr] = s
+			continue
+		}
+
+		d.Amount += s.Amount
+		d.RawAmount.Add(d.RawAmount, s.RawAmount)
+		d.Transactions = append(d.Transactions, s.Transactions...)
+		d.USDValue += s.USDValue
+		d.TxCount += s.TxCount
+		d.AvgAmount = d.Amount / float64(d.TxCount)
+		d.MaxAmount = math.Max(d.MaxAmount, s.MaxAmount)
+		if d.Label == "" {
+			d.Label = s.Label
+		}
+	}
+}
+
+// mergePayerMaps adds the payers aggregated in src into dst. Transactions in src follow those
+// already in dst, and a label already set in dst is kept.
+func mergePayerMaps(dst, src map[string]*Payer) {
+	for addr, s := range src {
+		d, exists := dst[addr]
+		if !exists {
+			dst[addr] = s
+			continue
+		}
+
+		d.Amount += s.Amount
+		d.RawAmount.Add(d.RawAmount, s.RawAmount)
+		d.Transactions = append(d.Transactions, s.Transactions...)
+		d.USDValue += s.USDValue
+		d.TxCount += s.TxCount
+		d.AvgAmount = d.Amount / float64(d.TxCount)
+		d.MaxAmount = math.Max(d.MaxAmount, s.MaxAmount)
+		if d.Label == "" {
+			d.Label = s.Label
+		}
+	}
+}
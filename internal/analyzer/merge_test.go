@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// overlappingTransactions share hashes and counterparties across the three lists: alice is paid
+// 1 ETH, 0.5 ETH by an internal call of the same transaction and 4 tokens by it too, and pays 3
+// ETH and 5 tokens back; bob is paid 2 ETH and pays 1 ETH through an internal transaction
+func overlappingTransactions() testTransactions {
+	return testTransactions{
+		normal: []etherscan.Transaction{
+			testTransaction("0xa1", testAddress, testAlice, "1000000000000000000"),
+			testTransaction("0xb1", testAddress, testBob, "2000000000000000000"),
+			testTransaction("0xa2", testAlice, testAddress, "3000000000000000000"),
+		},
+		internal: []etherscan.Transaction{
+			testTransaction("0xa1", testAddress, testAlice, "500000000000000000"),
+			testTransaction("0xb2", testBob, testAddress, "1000000000000000000"),
+		},
+		tokens: []etherscan.TokenTransfer{
+			testTokenTransfer("0xa1", testBob, testAddress, testAlice, "4000000000000000000"),
+			testTokenTransfer("0xa3", testBob, testAlice, testAddress, "5000000000000000000"),
+		},
+	}
+}
+
+// analyzeConcurrently runs analyze from several goroutines at once, as concurrent requests
+// sharing an analyzer do, and returns every run's result
+func analyzeConcurrently[T any](t *testing.T, analyze func() ([]T, error)) [][]T {
+	t.Helper()
+
+	results := make([][]T, 8)
+	errs := make([]error, len(results))
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = analyze()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return results
+}
+
+func TestAnalyzeBeneficiaryMergesOverlappingLists(t *testing.T) {
+	ba := NewBeneficiaryAnalyzer(newTestClient(t, overlappingTransactions()))
+
+	for _, mergeInternal := range []bool{false, true} {
+		results := analyzeConcurrently(t, func() ([]Beneficiary, error) {
+			return ba.AnalyzeBeneficiary(testAddress, Options{MergeInternal: mergeInternal})
+		})
+		for _, beneficiaries := range results {
+			if len(beneficiaries) != 2 {
+				t.Errorf("merge_internal=%v: %d beneficiaries, want 2", mergeInternal, len(beneficiaries))
+			}
+			if alice := findBeneficiary(t, beneficiaries, testAlice); alice.Amount != 5.5 {
+				t.Errorf("merge_internal=%v: alice amount = %v, want 5.5", mergeInternal, alice.Amount)
+			}
+			if bob := findBeneficiary(t, beneficiaries, testBob); bob.Amount != 2 || bob.TxCount != 1 {
+				t.Errorf("merge_internal=%v: bob = %v in %d transactions, want 2 in 1", mergeInternal, bob.Amount, bob.TxCount)
+			}
+		}
+	}
+}
+
+func TestAnalyzeBeneficiaryCountsEveryListEntry(t *testing.T) {
+	ba := NewBeneficiaryAnalyzer(newTestClient(t, overlappingTransactions()))
+
+	beneficiaries, err := ba.AnalyzeBeneficiary(testAddress, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The normal transaction, its internal call and its token transfer are three entries
+	alice := findBeneficiary(t, beneficiaries, testAlice)
+	if alice.TxCount != 3 || len(alice.Transactions) != 3 || alice.MaxAmount != 4 {
+		t.Errorf("alice = %d transactions (%d listed), max %v, want 3, 3 and 4", alice.TxCount, len(alice.Transactions), alice.MaxAmount)
+	}
+	if alice.RawAmount.String() != "5500000000000000000" {
+		t.Errorf("alice raw amount = %s, want 5500000000000000000", alice.RawAmount)
+	}
+}
+
+func TestAnalyzePayerMergesOverlappingLists(t *testing.T) {
+	pa := NewPayerAnalyzer(newTestClient(t, overlappingTransactions()))
+
+	results := analyzeConcurrently(t, func() ([]Payer, error) {
+		return pa.AnalyzePayer(testAddress, Options{})
+	})
+	for _, payers := range results {
+		if len(payers) != 2 {
+			t.Errorf("%d payers, want 2", len(payers))
+		}
+		if alice := findPayer(t, payers, testAlice); alice.Amount != 8 || alice.TxCount != 2 {
+			t.Errorf("alice = %v in %d transactions, want 8 in 2", alice.Amount, alice.TxCount)
+		}
+		if bob := findPayer(t, payers, testBob); bob.Amount != 1 || bob.TxCount != 1 {
+			t.Errorf("bob = %v in %d transactions, want 1 in 1", bob.Amount, bob.TxCount)
+		}
+	}
+}
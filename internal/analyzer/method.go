@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// knownMethods maps the 4-byte selectors of common token, WETH and DEX router functions to their names
+var knownMethods = map[string]string{
+	"0xa9059cbb": "transfer",
+	"0x23b872dd": "transferFrom",
+	"0x095ea7b3": "approve",
+	"0x42842e0e": "safeTransferFrom",
+	"0xb88d4fde": "safeTransferFrom",
+	"0xa22cb465": "setApprovalForAll",
+	"0x40c10f19": "mint",
+	"0x42966c68": "burn",
+	"0xd0e30db0": "deposit",
+	"0x2e1a7d4d": "withdraw",
+	"0x7ff36ab5": "swapExactETHForTokens",
+	"0x18cbafe5": "swapExactTokensForETH",
+	"0x38ed1739": "swapExactTokensForTokens",
+	"0x8803dbee": "swapTokensForExactTokens",
+	"0xfb3bdb41": "swapETHForExactTokens",
+	"0x4a25d94a": "swapTokensForExactETH",
+	"0x414bf389": "exactInputSingle",
+	"0xc04b8d59": "exactInput",
+	"0xe8e33700": "addLiquidity",
+	"0xf305d719": "addLiquidityETH",
+	"0x02751cec": "removeLiquidityETH",
+	"0xac9650d8": "multicall",
+	"0x5ae401dc": "multicall",
+	"0x3593564c": "execute",
+}
+
+// decodeMethod names the function a normal transaction called from the selector in its
+// calldata, falling back to the function name Etherscan reports and then to the raw selector.
+// Plain Ether transfers without calldata have no method.
+func decodeMethod(tx etherscan.Transaction) string {
+	input := strings.ToLower(tx.Input)
+	if len(input) < 10 || !strings.HasPrefix(input, "0x") {
+		return ""
+	}
+
+	selector := input[:10]
+	if name, ok := knownMethods[selector]; ok {
+		return name
+	}
+	if name, _, _ := strings.Cut(tx.FunctionName, "("); name != "" {
+		return name
+	}
+	return selector
+}
+
+// Groups of transactions without a decoded method, used by SummarizeByMethod
+const (
+	MethodPlainTransfer = "plain_transfer"
+	MethodInternalCall  = "internal_call"
+	MethodGas           = "gas"
+)
+
+// MethodTotal is the combined outflow of the transactions calling one method
+type MethodTotal struct {
+	Method    string
+	Amount    float64
+	RawAmount *big.Int // Exact total in Wei
+	USDValue  float64
+	TxCount   int
+}
+
+// SummarizeByMethod totals the beneficiaries' transactions by the method they called, largest
+// first. Internal transactions and gas fees have their own groups; other transactions without
+// calldata, including token transfers, count as plain transfers.
+func SummarizeByMethod(beneficiaries []Beneficiary) []MethodTotal {
+	index := make(map[string]int)
+	var totals []MethodTotal
+
+	for _, b := range beneficiaries {
+		for _, tx := range b.Transactions {
+			method := transactionMethod(tx)
+			i, ok := index[method]
+			if !ok {
+				i = len(totals)
+				index[method] = i
+				totals = append(totals, MethodTotal{Method: method, RawAmount: new(big.Int)})
+			}
+
+			total := &totals[i]
+			total.Amount += tx.TxAmount
+			total.USDValue += tx.USDValue
+			total.TxCount++
+			addRawValue(total.RawAmount, tx.RawValue)
+		}
+	}
+
+	sort.SliceStable(totals, func(i, j int) bool {
+		return totals[i].Amount > totals[j].Amount
+	})
+	return totals
+}
+
+// transactionMethod returns the group a transaction is totaled under by SummarizeByMethod
+func transactionMethod(tx TransactionDetails) string {
+	switch {
+	case tx.Method != "":
+		return tx.Method
+	case tx.Kind == kindGas:
+		return MethodGas
+	case tx.CallType != "":
+		return MethodInternalCall
+	}
+	return MethodPlainTransfer
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// zeroAddress is the null address token contracts mint from and burn to
+const zeroAddress = "0x0000000000000000000000000000000000000000"
+
+// Labels of the zero address, which only ever receives burns and sends mints
+const (
+	burnAddressLabel = "Burn Address (0x0)"
+	mintAddressLabel = "Mint Address (0x0)"
+)
+
+// Transaction kinds of a token contract issuing and destroying its own token
+const (
+	kindMint = "mint"
+	kindBurn = "burn"
+)
+
+// mintBurnParty classifies a transfer of the analyzed address's own token: a transfer from the
+// zero address is a mint to its recipient, one to the zero address a burn by its sender. It
+// returns the real party and the kind, or empty strings for any other transfer.
+func mintBurnParty(address string, transfer etherscan.TokenTransfer) (party, kind string) {
+	if !strings.EqualFold(transfer.ContractAddress, address) {
+		return "", ""
+	}
+
+	switch {
+	case strings.EqualFold(transfer.From, zeroAddress):
+		return transfer.To, kindMint
+	case strings.EqualFold(transfer.To, zeroAddress):
+		return transfer.From, kindBurn
+	}
+	return "", ""
+}
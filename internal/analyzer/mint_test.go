@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+const oneEther = "1000000000000000000"
+
+func TestAnalyzeBeneficiaryMintKeepsLabel(t *testing.T) {
+	// The analyzed address is the token contract minting to alice
+	client := newTestClient(t, testTransactions{
+		tokens: []etherscan.TokenTransfer{testTokenTransfer("0xa1", testAddress, zeroAddress, testAlice, oneEther)},
+	})
+
+	beneficiaries, err := NewBeneficiaryAnalyzer(client).AnalyzeBeneficiary(testAddress, Options{MintBurn: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice := findBeneficiary(t, beneficiaries, testAlice)
+	if alice.Label != "" {
+		t.Errorf("label = %q, want none", alice.Label)
+	}
+	if len(alice.Transactions) != 1 || alice.Transactions[0].Kind != kindMint {
+		t.Errorf("transactions = %+v, want one of kind %q", alice.Transactions, kindMint)
+	}
+}
+
+func TestAnalyzePayerBurnKeepsLabel(t *testing.T) {
+	// The analyzed address is the token contract, whose token bob burns
+	client := newTestClient(t, testTransactions{
+		tokens: []etherscan.TokenTransfer{testTokenTransfer("0xb1", testAddress, testBob, zeroAddress, oneEther)},
+	})
+
+	payers, err := NewPayerAnalyzer(client).AnalyzePayer(testAddress, Options{MintBurn: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob := findPayer(t, payers, testBob)
+	if bob.Label != "" {
+		t.Errorf("label = %q, want none", bob.Label)
+	}
+	if len(bob.Transactions) != 1 || bob.Transactions[0].Kind != kindBurn {
+		t.Errorf("transactions = %+v, want one of kind %q", bob.Transactions, kindBurn)
+	}
+}
+
+func TestAnalyzeBeneficiaryWrapLabelsWETH(t *testing.T) {
+	// Wrapping still labels the WETH contract
+	weth := "0x4444444444444444444444444444444444444444"
+	client := newTestClient(t, testTransactions{
+		normal: []etherscan.Transaction{testTransaction("0xc1", testAddress, weth, oneEther)},
+	})
+	ba := NewBeneficiaryAnalyzer(client)
+	ba.SetWETHContract(weth)
+
+	beneficiaries, err := ba.AnalyzeBeneficiary(testAddress, Options{WETH: WETHLabel})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := findBeneficiary(t, beneficiaries, weth); got.Label != kindWrap || got.Transactions[0].Kind != kindWrap {
+		t.Errorf("label = %q, kind = %q, want %q for both", got.Label, got.Transactions[0].Kind, kindWrap)
+	}
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import "time"
+
+// Options controls how an analysis is performed
+type Options struct {
+	// Location is the time zone used for formatted timestamps (UTC when nil)
+	Location *time.Location
+
+	// FromBlock and ToBlock limit the fetched transactions to a block range (0 means unbounded)
+	FromBlock int
+	ToBlock   int
+
+	// ChainID selects the chain analyzed through the Etherscan V2 API (0 means the endpoint's default)
+	ChainID int
+
+	// Types is the set of transaction types fetched and processed (MovementNormal,
+	// MovementInternal, MovementToken); nil means all of them
+	Types map[string]bool
+
+	// ExcludeCallTypes is a set of internal transaction call types (e.g. "create", "suicide")
+	// whose transactions are skipped
+	ExcludeCallTypes map[string]bool
+
+	// MergeInternal folds internal transactions into the normal transaction sharing their
+	// hash instead of recording them as independent flows
+	MergeInternal bool
+
+	// DetectContracts tags each counterparty with whether it is a contract (one cached
+	// Etherscan lookup per new address)
+	DetectContracts bool
+
+	// Stream walks the complete normal transaction history page by page, aggregating each
+	// page as it arrives instead of loading a capped list up front (beneficiary and payer analysis)
+	Stream bool
+
+	// ResolveProxies attributes payments sent by known proxy contracts to the account that
+	// initiated the transaction (payer analysis; one lookup per proxied transaction)
+	ResolveProxies bool
+
+	// USD values transfers at the historical price on their day (one cached price lookup per
+	// asset and day) instead of only pricing stablecoins by their peg
+	USD bool
+
+	// ETHPriceUSD values Ether transfers at this USD price, e.g. the current price from
+	// Etherscan's stats module, instead of at historical prices (0 disables it)
+	ETHPriceUSD float64
+
+	// MintBurn treats mints and burns of the analyzed address's own token, when it is a token
+	// contract, as flows to the recipient and from the burner, labeled mint and burn
+	MintBurn bool
+
+	// ExcludeBurns drops transfers to the zero address from beneficiary analysis instead of
+	// reporting the zero address as a labeled burn beneficiary
+	ExcludeBurns bool
+
+	// IncludeGas adds a GasBeneficiary entry summing the gas fees of every transaction the
+	// address sent, so transferred value plus gas accounts for all Ether leaving it (beneficiary analysis)
+	IncludeGas bool
+
+	// IncludeSpam keeps token transfers from denylisted spam contracts
+	IncludeSpam bool
+
+	// MinConfirmations skips transactions with fewer confirmations, which may still be
+	// reorganized away (0 keeps every transaction)
+	MinConfirmations int
+
+	// confirmedBlock is the last block with MinConfirmations confirmations, set by withConfirmedBlock
+	confirmedBlock int
+
+	// nativeDecimals is the decimals of the analyzed chain's native token (0 means Ether's 18)
+	nativeDecimals int
+
+	// Exclude is a set of lowercase counterparty addresses dropped from the results, in
+	// addition to the analyzer's own addresses
+	Exclude map[string]bool
+
+	// WETH controls how Ether wrapped into or unwrapped from the WETH contract is reported:
+	// WETHFold drops it, WETHLabel tags it as wrap/unwrap, and "" lists it like any other flow
+	WETH string
+}
+
+// includes reports whether transactions of the given type should be fetched and processed
+func (o Options) includes(txType string) bool {
+	return o.Types == nil || o.Types[txType]
+}
+
+// native returns the decimals of the analyzed chain's native token
+func (o Options) native() int {
+	if o.nativeDecimals == 0 {
+		return etherDecimals
+	}
+	return o.nativeDecimals
+}
+
+// withoutTypes returns the options with the disabled transaction types left out of Types
+func (o Options) withoutTypes(disabled map[string]bool) Options {
+	if len(disabled) == 0 {
+		return o
+	}
+
+	types := make(map[string]bool)
+	for _, txType := range []string{MovementNormal, MovementInternal, MovementToken} {
+		if o.includes(txType) && !disabled[txType] {
+			types[txType] = true
+		}
+	}
+	o.Types = types
+	return o
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PathStep is one hop of a fund path: the flow from one address to the next
+type PathStep struct {
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	Amount       float64  `json:"amount"`
+	TxCount      int      `json:"tx_count"`
+	Transactions []string `json:"transactions"` // Hashes of the transactions moving the funds
+}
+
+// Path is the result of a shortest fund path search between two addresses
+type Path struct {
+	From         string     `json:"from"`
+	To           string     `json:"to"`
+	Depth        int        `json:"depth"`
+	Found        bool       `json:"found"`
+	NodesVisited int        `json:"nodes_visited"`
+	Truncated    bool       `json:"truncated,omitempty"` // The node limit stopped the search early
+	Steps        []PathStep `json:"steps"`
+}
+
+// finds the shortest chain of beneficiaries leading from one address to another within
+// maxDepth hops, searching breadth first like TraceBeneficiaries. At most maxNodes addresses
+// are analyzed (0 means unlimited) and each at most once. Without a path Steps is nil.
+func (ba *BeneficiaryAnalyzer) FindPath(from, to string, maxDepth, maxNodes int, opts Options) (*Path, error) {
+	source := strings.ToLower(from)
+	target := strings.ToLower(to)
+	path := &Path{From: source, To: target, Depth: maxDepth}
+
+	// parents records the hop through which each address was first reached
+	parents := make(map[string]PathStep)
+	visited := map[string]bool{source: true}
+	frontier := []string{source}
+
+	for level := 1; level <= maxDepth && len(frontier) > 0; level++ {
+		var next []string
+		for _, node := range frontier {
+			if maxNodes > 0 && path.NodesVisited >= maxNodes {
+				path.Truncated = true
+				return path, nil
+			}
+
+			beneficiaries, err := ba.AnalyzeBeneficiary(node, opts)
+			if err != nil {
+				return nil, fmt.Errorf("error searching path through %s: %w", node, err)
+			}
+			path.NodesVisited++
+
+			for _, b := range beneficiaries {
+				if b.Address == "" || b.Address == GasBeneficiary || visited[b.Address] {
+					continue // Contract creation, gas fees or already reached
+				}
+				visited[b.Address] = true
+				parents[b.Address] = pathStep(node, b)
+
+				if b.Address == target {
+					path.Found = true
+					path.Steps = walkBack(parents, source, target)
+					return path, nil
+				}
+				next = append(next, b.Address)
+			}
+		}
+		frontier = next
+	}
+
+	return path, nil
+}
+
+// pathStep builds the hop from an address to one of its beneficiaries
+func pathStep(from string, b Beneficiary) PathStep {
+	hashes := make([]string, 0, len(b.Transactions))
+	for _, tx := range b.Transactions {
+		hashes = append(hashes, tx.TransactionID)
+	}
+	return PathStep{
+		From:         from,
+		To:           b.Address,
+		Amount:       b.Amount,
+		TxCount:      b.TxCount,
+		Transactions: hashes,
+	}
+}
+
+// walkBack follows the recorded hops from the target back to the source and returns them in order
+func walkBack(parents map[string]PathStep, source, target string) []PathStep {
+	var steps []PathStep
+	for node := target; node != source; {
+		step := parents[node]
+		steps = append(steps, step)
+		node = step.From
+	}
+
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps
+}
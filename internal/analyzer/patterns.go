@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"math"
+	"strconv"
+)
+
+const (
+	// FlagRepeatedAmounts marks a counterparty receiving many transactions of an identical amount
+	FlagRepeatedAmounts = "repeated_amounts"
+
+	// FlagRoundAmounts marks a counterparty receiving many transactions of round-number amounts
+	FlagRoundAmounts = "round_amounts"
+)
+
+// StructuringThresholds configures the detection of structuring patterns
+type StructuringThresholds struct {
+	// MinRepeatedAmounts is the number of identical amounts that triggers FlagRepeatedAmounts
+	MinRepeatedAmounts int
+	// MinRoundAmounts is the number of round-number amounts that triggers FlagRoundAmounts
+	MinRoundAmounts int
+	// RoundTolerance is the relative distance from a round number still treated as round
+	RoundTolerance float64
+}
+
+// DetectStructuring inspects a counterparty's transactions for repeated or round-number
+// amounts that may indicate structuring, and returns the matching flags
+func DetectStructuring(transactions []TransactionDetails, thresholds StructuringThresholds) []string {
+	amountCounts := make(map[string]int)
+	maxRepeated := 0
+	roundCount := 0
+
+	for _, tx := range transactions {
+		if tx.TxAmount <= 0 {
+			continue
+		}
+
+		// Key on a fixed precision so float noise doesn't hide identical amounts
+		key := strconv.FormatFloat(tx.TxAmount, 'g', 12, 64)
+		amountCounts[key]++
+		if amountCounts[key] > maxRepeated {
+			maxRepeated = amountCounts[key]
+		}
+
+		if isRoundAmount(tx.TxAmount, thresholds.RoundTolerance) {
+			roundCount++
+		}
+	}
+
+	var flags []string
+	if thresholds.MinRepeatedAmounts > 0 && maxRepeated >= thresholds.MinRepeatedAmounts {
+		flags = append(flags, FlagRepeatedAmounts)
+	}
+	if thresholds.MinRoundAmounts > 0 && roundCount >= thresholds.MinRoundAmounts {
+		flags = append(flags, FlagRoundAmounts)
+	}
+	return flags
+}
+
+// isRoundAmount reports whether an amount is within tolerance of a single-significant-digit
+// number such as 0.5, 3, 10 or 2000
+func isRoundAmount(amount, tolerance float64) bool {
+	return isRoundToDigits(amount, 1, tolerance)
+}
+
+// isRoundToDigits reports whether an amount is within tolerance of a number with at most the
+// given significant digits, e.g. 1500 or 12000 for two
+func isRoundToDigits(amount float64, digits int, tolerance float64) bool {
+	magnitude := math.Pow(10, math.Floor(math.Log10(amount))-float64(digits-1))
+	nearest := math.Round(amount/magnitude) * magnitude
+	return math.Abs(amount-nearest)/amount <= tolerance
+}
@@ -1,23 +1,36 @@
 package analyzer
 
 import (
-	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/price"
 	"golang.org/x/sync/errgroup"
 )
 
 // responsible for analyzing transactions to identify payers
 type PayerAnalyzer struct {
 	etherscanClient *etherscan.Client
+	stablecoins     map[string]float64
+	spamContracts   map[string]bool
+	proxyContracts  map[string]bool
+	wethContract    string
+	ownAddresses    map[string]bool
+	sanctioned      map[string]bool
+	disabledTypes   map[string]bool
+	prices          price.Provider
+	budget          budget
 }
 
 // creates a new payer analyzer
 func NewPayerAnalyzer(etherscanClient *etherscan.Client) *PayerAnalyzer {
 	return &PayerAnalyzer{
 		etherscanClient: etherscanClient,
+		budget:          unlimitedBudget,
 	}
 }
 
@@ -25,98 +38,288 @@ func NewPayerAnalyzer(etherscanClient *etherscan.Client) *PayerAnalyzer {
 type Payer struct {
 	Address      string               `json:"payer_address"`
 	Amount       float64              `json:"amount"`
+	TxCount      int                  `json:"tx_count"`
+	AvgAmount    float64              `json:"avg_amount"`
+	MaxAmount    float64              `json:"max_amount"`
 	Transactions []TransactionDetails `json:"transactions"`
+	RawAmount    *big.Int             `json:"-"` // Exact total in Wei
+	Label        string               `json:"label,omitempty"`
+	USDValue     float64              `json:"usd_value,omitempty"`
+	IsContract   *bool                `json:"is_contract,omitempty"`
+	Flags        []string             `json:"flags,omitempty"`
+	Score        float64              `json:"score,omitempty"`
+	Sanctioned   bool                 `json:"sanctioned,omitempty"`
+}
+
+// sets the stablecoin contracts (lowercase address -> USD peg) used for USD-denominated totals
+func (pa *PayerAnalyzer) SetStablecoins(stablecoins map[string]float64) {
+	pa.stablecoins = stablecoins
+}
+
+// sets the denylisted spam/airdrop token contracts (lowercase address) whose transfers are skipped
+func (pa *PayerAnalyzer) SetSpamContracts(spamContracts map[string]bool) {
+	pa.spamContracts = spamContracts
+}
+
+// sets the known router/proxy contracts (lowercase address) that resolve_proxies sees through
+func (pa *PayerAnalyzer) SetProxyContracts(proxyContracts map[string]bool) {
+	pa.proxyContracts = proxyContracts
+}
+
+// sets the addresses (lowercase) the user controls, which are never reported as counterparties
+func (pa *PayerAnalyzer) SetOwnAddresses(ownAddresses map[string]bool) {
+	pa.ownAddresses = ownAddresses
+}
+
+// sets the transaction types (MovementInternal, MovementToken, ...) never fetched, whatever the request asks for
+func (pa *PayerAnalyzer) SetDisabledTypes(disabled map[string]bool) {
+	pa.disabledTypes = disabled
+}
+
+// sets the price feed used for historical USD values (usd=true)
+func (pa *PayerAnalyzer) SetPriceProvider(prices price.Provider) {
+	pa.prices = prices
+}
+
+// sets the Wrapped Ether contract (lowercase address) whose deposits and withdrawals are wrap/unwrap flows
+func (pa *PayerAnalyzer) SetWETHContract(wethContract string) {
+	pa.wethContract = wethContract
+}
+
+// sets the deadline (0 means none) and the total Etherscan retries (negative means unlimited)
+// of each analysis
+func (pa *PayerAnalyzer) SetBudget(timeout time.Duration, maxRetries int) {
+	pa.budget = budget{timeout: timeout, maxRetries: maxRetries}
 }
 
 // analyzes the transaction flow for a given address to identify payers
-func (pa *PayerAnalyzer) AnalyzePayer(address string) ([]Payer, error) {
-	// Fetch all transaction types concurrently
-	var normalTxs []etherscan.Transaction
-	var internalTxs []etherscan.Transaction
-	var tokenTransfers []etherscan.TokenTransfer
-	var err error
+func (pa *PayerAnalyzer) AnalyzePayer(address string, opts Options) ([]Payer, error) {
+	// Transaction types disabled for the deployment are never fetched
+	opts = opts.withoutTypes(pa.disabledTypes)
 
-	eg := errgroup.Group{}
+	// Every fetch of this analysis shares one deadline and retry budget
+	client, cancel := pa.budget.client(pa.etherscanClient, opts.ChainID)
+	defer cancel()
 
-	eg.Go(func() error {
-		normalTxs, err = pa.etherscanClient.GetNormalTransactions(address)
+	// Native values are scaled by the decimals of the analyzed chain's native token
+	opts.nativeDecimals = client.NativeDecimals()
+
+	// Fetch all transaction types concurrently; normal transactions are streamed below in streaming mode
+	fetch := fetchTransactionSet
+	if opts.Stream {
+		fetch = fetchTransferSet
+	}
+	txs, err := fetch(client, address, opts)
+	if err != nil {
+		return nil, err
+	}
+	internalTxs, tokenTransfers := txs.internal, txs.tokens
+
+	// Transactions are held to min_confirmations by their block against the current chain head
+	opts, err = withConfirmedBlock(client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Look one hop further back for value sent by known proxies, to the account that initiated the transaction
+	var originators map[string]string
+	if opts.ResolveProxies && len(pa.proxyContracts) > 0 {
+		originators, err = resolveOriginators(client, proxiedHashes(address, txs, pa.proxyContracts))
 		if err != nil {
-			return fmt.Errorf("error fetching normal transactions: %w", err)
+			return nil, err
 		}
-		return nil
-	})
+	}
+	payerOf := func(from, hash string) (payer, viaProxy string) {
+		if originator, ok := originators[hash]; ok && pa.proxyContracts[strings.ToLower(from)] {
+			return originator, strings.ToLower(from)
+		}
+		return from, ""
+	}
 
+	// Aggregate the three transaction lists concurrently, each into its own map, and merge the
+	// maps once all are done. Internal transactions folded into their parent need the normal
+	// transactions' map, so with merge_internal they follow on the same goroutine.
+	payerMap := make(map[string]*Payer)
+	internalMap := make(map[string]*Payer)
+	tokenMap := make(map[string]*Payer)
+
+	eg := errgroup.Group{}
 	eg.Go(func() error {
-		internalTxs, err = pa.etherscanClient.GetInternalTransactions(address)
+		parents, err := pa.processNormalTransactions(client, payerMap, address, opts, txs)
 		if err != nil {
-			return fmt.Errorf("error fetching internal transactions: %w", err)
+			return err
+		}
+		if opts.MergeInternal {
+			pa.processInternalTransactions(payerMap, parents, payerOf, address, opts, internalTxs)
 		}
 		return nil
 	})
-
+	if !opts.MergeInternal {
+		eg.Go(func() error {
+			pa.processInternalTransactions(internalMap, nil, payerOf, address, opts, internalTxs)
+			return nil
+		})
+	}
 	eg.Go(func() error {
-		tokenTransfers, err = pa.etherscanClient.GetTokenTransfers(address)
-		if err != nil {
-			return fmt.Errorf("error fetching token transfers: %w", err)
-		}
+		pa.processTokenTransfers(tokenMap, payerOf, address, opts, tokenTransfers)
 		return nil
 	})
-
 	if err := eg.Wait(); err != nil {
 		return nil, err
 	}
+	mergePayerMaps(payerMap, internalMap)
+	mergePayerMaps(payerMap, tokenMap)
 
-	// Process transactions to identify payers
-	payerMap := make(map[string]*Payer)
+	// Drop the user's own addresses and any excluded for this request, with their amounts
+	for addr := range payerMap {
+		if pa.ownAddresses[addr] || opts.Exclude[addr] {
+			delete(payerMap, addr)
+		}
+	}
 
-	// Process normal transactions
-	for _, tx := range normalTxs {
-		// Only consider incoming transactions (where this address is receiving)
-		if strings.EqualFold(tx.To, address) && tx.IsError == "0" {
-			pa.processPayer(payerMap, tx.From, tx.Value, tx.Hash, tx.TimeStamp)
+	// Funds received from the zero address were minted rather than paid by anyone
+	if minted, ok := payerMap[zeroAddress]; ok && minted.Label == "" {
+		minted.Label = mintAddressLabel
+	}
+
+	// Convert map to slice
+	payers := make([]Payer, 0, len(payerMap))
+	for _, payer := range payerMap {
+		payers = append(payers, *payer)
+	}
+
+	// Most significant counterparties first
+	sort.SliceStable(payers, func(i, j int) bool {
+		return payers[i].Amount > payers[j].Amount
+	})
+
+	if opts.DetectContracts {
+		addresses := make([]string, len(payers))
+		for i, p := range payers {
+			addresses[i] = p.Address
+		}
+
+		contracts, err := lookupContracts(client, addresses)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range payers {
+			info := contracts[strings.ToLower(payers[i].Address)]
+			payers[i].IsContract = &info.isContract
+			if payers[i].Label == "" {
+				payers[i].Label = info.name
+			}
+		}
+	}
+
+	// Screen the counterparties against the sanctions list
+	for i := range payers {
+		payers[i].Sanctioned = pa.sanctioned[strings.ToLower(payers[i].Address)]
+	}
+
+	return payers, nil
+}
+
+// aggregates the incoming normal transactions into the payer map, returning the payer each
+// transaction was attributed to by hash when internal transactions are merged
+func (pa *PayerAnalyzer) processNormalTransactions(client *etherscan.Client, payerMap map[string]*Payer, address string, opts Options, txs *transactionSet) (map[string]string, error) {
+	// Payer each normal transaction was attributed to, keyed by hash, so the
+	// internal transactions it spawned can be merged into it
+	parents := make(map[string]string)
+
+	err := forEachNormalTransaction(client, address, opts, txs, func(tx etherscan.Transaction) {
+		// Only consider incoming transactions (where this address is receiving) that can't be reorganized away
+		if strings.EqualFold(tx.To, address) && tx.IsError == "0" && opts.confirmed(tx.BlockNumber) {
+			kind, skip := wrapFlow(opts, pa.wethContract, tx.From, tx.To, tx.Value)
+			if skip {
+				return
+			}
+			pa.processPayer(payerMap, tx.From, "", kind, "", decodeMethod(tx), tx.Value, tx.Hash, tx.TimeStamp,
+				etherUSDValue(pa.prices, opts, tx.Value, tx.TimeStamp), opts.native(), opts.Location)
+			if opts.MergeInternal {
+				parents[tx.Hash] = tx.From
+			}
 		}
+	})
+	if err != nil {
+		return nil, err
 	}
+	return parents, nil
+}
 
-	// Process internal transactions
+// aggregates the incoming internal transactions into the payer map, folding those whose hash
+// is in parents into the payer of their normal transaction; payerOf attributes proxied payments
+func (pa *PayerAnalyzer) processInternalTransactions(payerMap map[string]*Payer, parents map[string]string,
+	payerOf func(from, hash string) (string, string), address string, opts Options, internalTxs []etherscan.Transaction) {
 	for _, tx := range internalTxs {
-		// Only consider incoming transactions
+		// Only consider incoming transactions; a contract calling itself moves nothing from a counterparty
 		if strings.EqualFold(tx.To, address) && tx.IsError == "0" {
-			pa.processPayer(payerMap, tx.From, tx.Value, tx.Hash, tx.TimeStamp)
+			if isSelfMove(address, tx.From, tx.To) || opts.ExcludeCallTypes[strings.ToLower(tx.Type)] || !opts.confirmed(tx.BlockNumber) {
+				continue
+			}
+			if parent, ok := parents[tx.Hash]; ok {
+				pa.mergeIntoParent(payerMap, parent, tx.Value, tx.Hash, etherUSDValue(pa.prices, opts, tx.Value, tx.TimeStamp), opts.native())
+				continue
+			}
+			kind, skip := wrapFlow(opts, pa.wethContract, tx.From, tx.To, tx.Value)
+			if skip {
+				continue
+			}
+			payer, viaProxy := payerOf(tx.From, tx.Hash)
+			pa.processPayer(payerMap, payer, viaProxy, kind, tx.Type, "", tx.Value, tx.Hash, tx.TimeStamp,
+				etherUSDValue(pa.prices, opts, tx.Value, tx.TimeStamp), opts.native(), opts.Location)
 		}
 	}
+}
 
-	// Process token transfers
+// aggregates the incoming token transfers into the payer map; payerOf attributes proxied payments
+func (pa *PayerAnalyzer) processTokenTransfers(payerMap map[string]*Payer, payerOf func(from, hash string) (string, string),
+	address string, opts Options, tokenTransfers []etherscan.TokenTransfer) {
 	for _, transfer := range tokenTransfers {
+		// Skip transfers of denylisted spam tokens unless asked for, and unconfirmed ones
+		if !opts.IncludeSpam && isSpamTransfer(pa.spamContracts, transfer) || !opts.confirmed(transfer.BlockNumber) {
+			continue
+		}
+
+		// Burns of the analyzed contract's own token come from their burner
+		if opts.MintBurn {
+			if party, kind := mintBurnParty(address, transfer); kind == kindBurn {
+				pa.processPayer(payerMap, party, "", kind, "", "", transfer.Value, transfer.Hash, transfer.TimeStamp,
+					tokenUSDValue(pa.prices, opts, pa.stablecoins, transfer), etherDecimals, opts.Location)
+				continue
+			}
+		}
+
 		// Only consider incoming transfers
 		if strings.EqualFold(transfer.To, address) {
-			pa.processPayer(payerMap, transfer.From, transfer.Value, transfer.Hash, transfer.TimeStamp)
+			payer, viaProxy := payerOf(transfer.From, transfer.Hash)
+			pa.processPayer(payerMap, payer, viaProxy, "", "", "", transfer.Value, transfer.Hash, transfer.TimeStamp,
+				tokenUSDValue(pa.prices, opts, pa.stablecoins, transfer), etherDecimals, opts.Location)
 		}
 	}
-
-	// Convert map to slice
-	payers := make([]Payer, 0, len(payerMap))
-	for _, payer := range payerMap {
-		payers = append(payers, *payer)
-	}
-
-	return payers, nil
 }
 
-// adds a transaction to the payer map
+// adds a transaction to the payer map; viaProxy is the proxy the value was routed through, if resolved,
+// kind its wrap/unwrap label, callType its internal call type and method the function a normal
+// transaction called, if any, and decimals those of its raw value
 func (pa *PayerAnalyzer) processPayer(payerMap map[string]*Payer, 
-	payerAddr, valueStr, hash, timestampStr string) {
+	payerAddr, viaProxy, kind, callType, method, valueStr, hash, timestampStr string, usdValue float64, decimals int, loc *time.Location) {
 		
-	// Convert value to float (from Wei to Ether)
-	value := new(big.Float)
-	value.SetString(valueStr)
-	divisor := new(big.Float)
-	divisor.SetString("1000000000000000000") // 10^18 (Wei to Ether)
-	value.Quo(value, divisor)
+	// Key and display by lowercase address so differently-cased inputs aggregate together
+	payerAddr = strings.ToLower(payerAddr)
+
+	// Keep raw values in 18 decimals whatever the native token's decimals
+	valueStr = toEtherUnits(valueStr, decimals)
 
-	amount, _ := value.Float64()
+	// Convert value to float (from Wei to Ether), skipping values that can't be represented
+	amount, err := weiToEther(valueStr)
+	if err != nil {
+		return
+	}
 
 	// Format timestamp
-	dateTime, err := etherscan.FormatTime(timestampStr)
+	dateTime, err := etherscan.FormatTime(timestampStr, loc)
 	if err != nil {
 		dateTime = timestampStr // Use original timestamp if formatting fails
 	}
@@ -126,17 +329,73 @@ func (pa *PayerAnalyzer) processPayer(payerMap map[string]*Payer,
 		TxAmount:      amount,
 		DateTime:      dateTime,
 		TransactionID: hash,
+		RawValue:      decimalValue(valueStr),
+		ViaProxy:      viaProxy,
+		CallType:      callType,
+		Method:        method,
+		Kind:          kind,
+		USDValue:      usdValue,
 	}
 
 	// Add to payer map
 	if p, exists := payerMap[payerAddr]; exists {
 		p.Amount += amount
+		addRawValue(p.RawAmount, valueStr)
 		p.Transactions = append(p.Transactions, txDetails)
+		p.USDValue += usdValue
+		p.TxCount++
+		p.AvgAmount = p.Amount / float64(p.TxCount)
+		p.MaxAmount = math.Max(p.MaxAmount, amount)
 	} else {
+		rawAmount := new(big.Int)
+		addRawValue(rawAmount, valueStr)
 		payerMap[payerAddr] = &Payer{
 			Address:      payerAddr,
 			Amount:       amount,
+			TxCount:      1,
+			AvgAmount:    amount,
+			MaxAmount:    amount,
+			RawAmount:    rawAmount,
 			Transactions: []TransactionDetails{txDetails},
+			USDValue:     usdValue,
 		}
 	}
-}
\ No newline at end of file
+
+	// Label the WETH contract by what the address did with it; burns only mark their transactions
+	if p := payerMap[payerAddr]; isWrapKind(kind) && p.Label == "" {
+		p.Label = kind
+	}
+}
+
+// folds the value of an internal transaction, with the given native decimals, into the parent
+// normal transaction in the payer map
+func (pa *PayerAnalyzer) mergeIntoParent(payerMap map[string]*Payer, parentAddr, valueStr, hash string, usdValue float64, decimals int) {
+	valueStr = toEtherUnits(valueStr, decimals)
+	amount, err := weiToEther(valueStr)
+	if err != nil {
+		return
+	}
+
+	p, exists := payerMap[strings.ToLower(parentAddr)]
+	if !exists {
+		return
+	}
+
+	for i := range p.Transactions {
+		if p.Transactions[i].TransactionID == hash {
+			p.Transactions[i].TxAmount += amount
+			p.Amount += amount
+			p.Transactions[i].USDValue += usdValue
+			p.USDValue += usdValue
+			p.AvgAmount = p.Amount / float64(p.TxCount)
+			p.MaxAmount = math.Max(p.MaxAmount, p.Transactions[i].TxAmount)
+
+			raw := new(big.Int)
+			addRawValue(raw, p.Transactions[i].RawValue)
+			addRawValue(raw, valueStr)
+			p.Transactions[i].RawValue = raw.String()
+			addRawValue(p.RawAmount, valueStr)
+			return
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+func TestAnalyzePayerAggregatesMixedCaseAddresses(t *testing.T) {
+	client := newTestClient(t, testTransactions{
+		normal: []etherscan.Transaction{
+			testTransaction("0xa1", testMixedSum, testAddress, "1000000000000000000"),
+			testTransaction("0xa2", testMixedLower, testAddress, "2000000000000000000"),
+		},
+		tokens: []etherscan.TokenTransfer{
+			testTokenTransfer("0xa3", testBob, testMixedUpper, testAddress, "500000000000000000"),
+		},
+	})
+
+	payers, err := NewPayerAnalyzer(client).AnalyzePayer(testAddress, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payers) != 1 {
+		t.Fatalf("%d payers, want the three spellings as one: %+v", len(payers), payers)
+	}
+	if p := payers[0]; p.Address != testMixedLower || p.Amount != 3.5 || p.TxCount != 3 {
+		t.Errorf("payer = %s with %v ETH in %d transactions, want %s with 3.5 in 3", p.Address, p.Amount, p.TxCount, testMixedLower)
+	}
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// Profile represents headline activity stats of an analyzed address
+type Profile struct {
+	Address             string `json:"address"`
+	OutgoingTxCount     int    `json:"outgoing_tx_count"`
+	IncomingTxCount     int    `json:"incoming_tx_count"`
+	UniqueBeneficiaries int    `json:"unique_beneficiaries"`
+	UniquePayers        int    `json:"unique_payers"`
+	FirstActivity       string `json:"first_activity,omitempty"`
+	LastActivity        string `json:"last_activity,omitempty"`
+
+	CircularFlows []CircularFlow `json:"circular_flows,omitempty"`
+}
+
+// profileBuilder accumulates profile stats across transaction types
+type profileBuilder struct {
+	address       string
+	profile       Profile
+	beneficiaries map[string]bool
+	payers        map[string]bool
+	first, last   int64
+}
+
+// analyzes the activity of a given address to build its profile
+func (fa *FlowAnalyzer) AnalyzeProfile(address string, opts Options) (*Profile, error) {
+	txs, err := fetchTransactionSet(fa.etherscanClient, address, opts.withoutTypes(fa.disabledTypes))
+	if err != nil {
+		return nil, err
+	}
+
+	pb := &profileBuilder{
+		address:       address,
+		profile:       Profile{Address: address},
+		beneficiaries: make(map[string]bool),
+		payers:        make(map[string]bool),
+	}
+
+	for _, tx := range txs.normal {
+		if tx.IsError == "0" {
+			pb.add(tx.From, tx.To, tx.TimeStamp)
+		}
+	}
+
+	for _, tx := range txs.internal {
+		if tx.IsError == "0" {
+			pb.add(tx.From, tx.To, tx.TimeStamp)
+		}
+	}
+
+	for _, transfer := range txs.tokens {
+		pb.add(transfer.From, transfer.To, transfer.TimeStamp)
+	}
+
+	pb.profile.UniqueBeneficiaries = len(pb.beneficiaries)
+	pb.profile.UniquePayers = len(pb.payers)
+	if pb.first != 0 {
+		pb.profile.FirstActivity, _ = etherscan.FormatTime(strconv.FormatInt(pb.first, 10), opts.Location)
+		pb.profile.LastActivity, _ = etherscan.FormatTime(strconv.FormatInt(pb.last, 10), opts.Location)
+	}
+
+	pb.profile.CircularFlows = detectCircularFlows(address, txs)
+
+	return &pb.profile, nil
+}
+
+// add records a single transaction in the profile
+func (pb *profileBuilder) add(from, to, timestampStr string) {
+	outgoing := strings.EqualFold(from, pb.address)
+	incoming := strings.EqualFold(to, pb.address)
+	if !outgoing && !incoming {
+		return
+	}
+
+	if outgoing {
+		pb.profile.OutgoingTxCount++
+		pb.beneficiaries[strings.ToLower(to)] = true
+	}
+	if incoming {
+		pb.profile.IncomingTxCount++
+		pb.payers[strings.ToLower(from)] = true
+	}
+
+	timestamp, err := stringToInt64(timestampStr)
+	if err != nil {
+		return
+	}
+	if pb.first == 0 || timestamp < pb.first {
+		pb.first = timestamp
+	}
+	if timestamp > pb.last {
+		pb.last = timestamp
+	}
+}
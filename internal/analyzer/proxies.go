@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"golang.org/x/sync/errgroup"
+)
+
+// resolveOriginators looks up the sender of each transaction, keyed by hash, so value routed
+// through a proxy can be attributed to the account that initiated it
+func resolveOriginators(client *etherscan.Client, hashes []string) (map[string]string, error) {
+	originators := make(map[string]string, len(hashes))
+	var mu sync.Mutex
+
+	eg := errgroup.Group{}
+	eg.SetLimit(contractLookupWorkers)
+
+	for _, hash := range hashes {
+		hash := hash
+		eg.Go(func() error {
+			tx, err := client.GetTransactionByHash(hash)
+			if err != nil {
+				return fmt.Errorf("error resolving originator of %s: %w", hash, err)
+			}
+
+			mu.Lock()
+			originators[hash] = strings.ToLower(tx.From)
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return originators, nil
+}
+
+// proxiedHashes returns the hashes of the address's incoming internal transactions and token
+// transfers sent by a known proxy contract
+func proxiedHashes(address string, txs *transactionSet, proxies map[string]bool) []string {
+	seen := make(map[string]bool)
+	var hashes []string
+
+	add := func(from, to, hash string) {
+		if !strings.EqualFold(to, address) || !proxies[strings.ToLower(from)] || seen[hash] {
+			return
+		}
+		seen[hash] = true
+		hashes = append(hashes, hash)
+	}
+
+	for _, tx := range txs.internal {
+		if tx.IsError == "0" {
+			add(tx.From, tx.To, tx.Hash)
+		}
+	}
+	for _, transfer := range txs.tokens {
+		add(transfer.From, transfer.To, transfer.Hash)
+	}
+
+	return hashes
+}
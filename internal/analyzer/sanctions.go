@@ -0,0 +1,31 @@
+package analyzer
+
+// sets the sanctioned addresses (lowercase) whose beneficiaries are marked sanctioned
+func (ba *BeneficiaryAnalyzer) SetSanctionedAddresses(sanctioned map[string]bool) {
+	ba.sanctioned = sanctioned
+}
+
+// sets the sanctioned addresses (lowercase) whose payers are marked sanctioned
+func (pa *PayerAnalyzer) SetSanctionedAddresses(sanctioned map[string]bool) {
+	pa.sanctioned = sanctioned
+}
+
+// HasSanctionedBeneficiary reports whether any beneficiary is on the sanctions list
+func HasSanctionedBeneficiary(beneficiaries []Beneficiary) bool {
+	for _, b := range beneficiaries {
+		if b.Sanctioned {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSanctionedPayer reports whether any payer is on the sanctions list
+func HasSanctionedPayer(payers []Payer) bool {
+	for _, p := range payers {
+		if p.Sanctioned {
+			return true
+		}
+	}
+	return false
+}
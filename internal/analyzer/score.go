@@ -0,0 +1,107 @@
+package analyzer
+
+import "time"
+
+// ScoreWeights configures how much each component contributes to a counterparty's significance score
+type ScoreWeights struct {
+	// Amount weights the counterparty's total amount relative to the largest total
+	Amount float64
+	// Count weights the counterparty's transaction count relative to the highest count
+	Count float64
+	// Recency weights how recently the counterparty was last active relative to the others
+	Recency float64
+}
+
+// ScoreBeneficiaries computes a normalized significance score for each beneficiary
+func ScoreBeneficiaries(beneficiaries []Beneficiary, weights ScoreWeights) {
+	transactions := make([][]TransactionDetails, len(beneficiaries))
+	amounts := make([]float64, len(beneficiaries))
+	for i, b := range beneficiaries {
+		transactions[i] = b.Transactions
+		amounts[i] = b.Amount
+	}
+
+	for i, score := range computeScores(amounts, transactions, weights) {
+		beneficiaries[i].Score = score
+	}
+}
+
+// ScorePayers computes a normalized significance score for each payer
+func ScorePayers(payers []Payer, weights ScoreWeights) {
+	transactions := make([][]TransactionDetails, len(payers))
+	amounts := make([]float64, len(payers))
+	for i, p := range payers {
+		transactions[i] = p.Transactions
+		amounts[i] = p.Amount
+	}
+
+	for i, score := range computeScores(amounts, transactions, weights) {
+		payers[i].Score = score
+	}
+}
+
+// computeScores combines each counterparty's amount, transaction count and last activity,
+// each normalized across all counterparties to [0, 1], into a weighted score in [0, 1]
+func computeScores(amounts []float64, transactions [][]TransactionDetails, weights ScoreWeights) []float64 {
+	scores := make([]float64, len(amounts))
+	totalWeight := weights.Amount + weights.Count + weights.Recency
+	if len(amounts) == 0 || totalWeight <= 0 {
+		return scores
+	}
+
+	lastSeen := make([]time.Time, len(amounts))
+	var maxAmount float64
+	var maxCount int
+	var oldest, newest time.Time
+	for i := range amounts {
+		if amounts[i] > maxAmount {
+			maxAmount = amounts[i]
+		}
+		if len(transactions[i]) > maxCount {
+			maxCount = len(transactions[i])
+		}
+
+		lastSeen[i] = lastActivity(transactions[i])
+		if lastSeen[i].IsZero() {
+			continue
+		}
+		if oldest.IsZero() || lastSeen[i].Before(oldest) {
+			oldest = lastSeen[i]
+		}
+		if lastSeen[i].After(newest) {
+			newest = lastSeen[i]
+		}
+	}
+
+	span := newest.Sub(oldest)
+	for i := range amounts {
+		var amount, count, recency float64
+		if maxAmount > 0 {
+			amount = amounts[i] / maxAmount
+		}
+		if maxCount > 0 {
+			count = float64(len(transactions[i])) / float64(maxCount)
+		}
+		if !lastSeen[i].IsZero() {
+			recency = 1 // Everyone was last active at the same time
+			if span > 0 {
+				recency = float64(lastSeen[i].Sub(oldest)) / float64(span)
+			}
+		}
+
+		scores[i] = (weights.Amount*amount + weights.Count*count + weights.Recency*recency) / totalWeight
+	}
+
+	return scores
+}
+
+// lastActivity returns the time of the most recent transaction, or the zero time if none can be parsed
+func lastActivity(transactions []TransactionDetails) time.Time {
+	var last time.Time
+	for _, tx := range transactions {
+		if t := transactionTime(tx); t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
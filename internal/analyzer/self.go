@@ -0,0 +1,10 @@
+package analyzer
+
+import "strings"
+
+// isSelfMove reports whether a transaction both leaves and returns to the analyzed address,
+// such as an internal call a contract makes to itself. Its value never reaches a counterparty,
+// so recording it would count the address as its own beneficiary and payer.
+func isSelfMove(address, from, to string) bool {
+	return strings.EqualFold(from, address) && strings.EqualFold(to, address)
+}
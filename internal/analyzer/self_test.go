@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+func TestIsSelfMove(t *testing.T) {
+	for _, tc := range []struct {
+		from, to string
+		want     bool
+	}{
+		{testMixedLower, testMixedLower, true},
+		{testMixedSum, testMixedUpper, true},
+		{testMixedLower, testAlice, false},
+		{testAlice, testMixedLower, false},
+		{testAlice, testAlice, false},
+	} {
+		if got := isSelfMove(testMixedLower, tc.from, tc.to); got != tc.want {
+			t.Errorf("isSelfMove(%s -> %s) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+// selfCallTransactions has the analyzed contract (testMixedLower) call itself with 5 ETH, in
+// the transaction paying alice 1 ETH, and receive 2 ETH from bob
+func selfCallTransactions() testTransactions {
+	return testTransactions{
+		normal: []etherscan.Transaction{
+			testTransaction("0xa1", testMixedLower, testAlice, "1000000000000000000"),
+			testTransaction("0xb1", testBob, testMixedLower, "2000000000000000000"),
+		},
+		internal: []etherscan.Transaction{
+			testTransaction("0xa1", testMixedSum, testMixedLower, "5000000000000000000"),
+		},
+	}
+}
+
+func TestAnalyzeBeneficiarySkipsSelfReferencingInternalTransactions(t *testing.T) {
+	ba := NewBeneficiaryAnalyzer(newTestClient(t, selfCallTransactions()))
+
+	for _, mergeInternal := range []bool{false, true} {
+		beneficiaries, err := ba.AnalyzeBeneficiary(testMixedLower, Options{MergeInternal: mergeInternal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(beneficiaries) != 1 {
+			t.Fatalf("merge_internal=%v: beneficiaries = %+v, want only alice", mergeInternal, beneficiaries)
+		}
+		if alice := beneficiaries[0]; alice.Address != testAlice || alice.Amount != 1 || alice.TxCount != 1 {
+			t.Errorf("merge_internal=%v: beneficiary %s with %v ETH in %d transactions, want alice with 1 in 1",
+				mergeInternal, alice.Address, alice.Amount, alice.TxCount)
+		}
+	}
+}
+
+func TestAnalyzePayerSkipsSelfReferencingInternalTransactions(t *testing.T) {
+	pa := NewPayerAnalyzer(newTestClient(t, selfCallTransactions()))
+
+	payers, err := pa.AnalyzePayer(testMixedLower, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payers) != 1 {
+		t.Fatalf("payers = %+v, want only bob", payers)
+	}
+	if bob := payers[0]; bob.Address != testBob || bob.Amount != 2 || bob.TxCount != 1 {
+		t.Errorf("payer %s with %v ETH in %d transactions, want bob with 2 in 1", bob.Address, bob.Amount, bob.TxCount)
+	}
+}
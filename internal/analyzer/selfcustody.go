@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// FlagSelfCustody marks a beneficiary that looks like another address of the same entity: a
+// fresh address that forwarded most of what it received onward soon after
+const FlagSelfCustody = "likely_self_custody"
+
+// selfCustodyHistory is how many of a beneficiary's earliest transactions the lookahead reads
+const selfCustodyHistory = 100
+
+// SelfCustodyThresholds configures the detection of likely self-custody transfers
+type SelfCustodyThresholds struct {
+	// MinForwardRatio is the share (0-1) of the Ether received from the analyzed address the
+	// beneficiary must have sent onward
+	MinForwardRatio float64
+	// Window is how long after its first receipt the beneficiary must have forwarded it within
+	Window time.Duration
+	// MaxLookups bounds the beneficiaries looked ahead, largest first (0 means all of them)
+	MaxLookups int
+}
+
+// flags beneficiaries that are likely self-custody transfers rather than true outflows, with a
+// one-hop lookahead into each beneficiary's earliest transactions (one request per beneficiary).
+// A beneficiary is flagged when its history starts with Ether received from the address and it
+// forwarded at least MinForwardRatio of the Ether received from it to other addresses within
+// Window of the first receipt. Contracts, known exchanges, gas fees and the zero address are
+// never flagged. Flagged entries are kept; FlagSelfCustody is added to their Flags.
+func (ba *BeneficiaryAnalyzer) DetectSelfCustody(address string, beneficiaries []Beneficiary, thresholds SelfCustodyThresholds, opts Options) error {
+	address = strings.ToLower(address)
+
+	client, cancel := ba.budget.client(ba.etherscanClient, opts.ChainID)
+	defer cancel()
+
+	// Look ahead from the largest beneficiaries, so the lookup bound keeps the most significant
+	candidates := make([]int, 0, len(beneficiaries))
+	for i, b := range beneficiaries {
+		if ba.selfCustodyCandidate(b) {
+			candidates = append(candidates, i)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return beneficiaries[candidates[i]].Amount > beneficiaries[candidates[j]].Amount
+	})
+	if thresholds.MaxLookups > 0 && len(candidates) > thresholds.MaxLookups {
+		candidates = candidates[:thresholds.MaxLookups]
+	}
+
+	for _, i := range candidates {
+		b := &beneficiaries[i]
+		history, err := client.GetEarliestNormalTransactions(b.Address, selfCustodyHistory)
+		if err != nil {
+			return fmt.Errorf("error looking ahead from %s: %w", b.Address, err)
+		}
+		if forwardsFunds(address, b.Address, history, thresholds) {
+			if ba.debug {
+				fmt.Printf("DEBUG: %s looks like a self-custody transfer\n", b.Address)
+			}
+			b.Flags = append(b.Flags, FlagSelfCustody)
+		}
+	}
+	return nil
+}
+
+// selfCustodyCandidate reports whether a beneficiary could be an address of the same entity
+func (ba *BeneficiaryAnalyzer) selfCustodyCandidate(b Beneficiary) bool {
+	if b.Address == "" || b.Address == GasBeneficiary || b.Address == zeroAddress {
+		return false
+	}
+	if b.IsContract != nil && *b.IsContract {
+		return false
+	}
+	return ba.beneficiaryCategory(b.Address) != CategoryCEX
+}
+
+// forwardsFunds reports whether the beneficiary's history, oldest first, starts with Ether
+// received from the address and shows it forwarding enough of it onward within the window
+func forwardsFunds(address, beneficiary string, history []etherscan.Transaction, thresholds SelfCustodyThresholds) bool {
+	if len(history) == 0 || !strings.EqualFold(history[0].From, address) || !strings.EqualFold(history[0].To, beneficiary) {
+		return false // Not fresh: the beneficiary was active before receiving from the address
+	}
+	firstReceipt, err := stringToInt64(history[0].TimeStamp)
+	if err != nil {
+		return false
+	}
+	deadline := firstReceipt + int64(thresholds.Window/time.Second)
+
+	received, forwarded := new(big.Int), new(big.Int)
+	for _, tx := range history {
+		timestamp, err := stringToInt64(tx.TimeStamp)
+		if err != nil || timestamp > deadline {
+			break
+		}
+		if tx.IsError == "1" {
+			continue
+		}
+		switch {
+		case strings.EqualFold(tx.From, address) && strings.EqualFold(tx.To, beneficiary):
+			addRawValue(received, tx.Value)
+		case strings.EqualFold(tx.From, beneficiary) && !strings.EqualFold(tx.To, address) && !strings.EqualFold(tx.To, beneficiary):
+			addRawValue(forwarded, tx.Value)
+		}
+	}
+	if received.Sign() == 0 {
+		return false
+	}
+
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(forwarded), new(big.Float).SetInt(received)).Float64()
+	return ratio >= thresholds.MinForwardRatio
+}
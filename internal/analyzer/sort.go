@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sort keys for counterparty results
+const (
+	SortAmount = "amount"
+	SortCount  = "count"
+	SortRecent = "recent"
+	SortScore  = "score"
+)
+
+// SortBeneficiaries orders beneficiaries by total amount, transaction count, last activity
+// or significance score (which must already be computed). Ties keep their existing order.
+func SortBeneficiaries(beneficiaries []Beneficiary, by string, ascending bool) {
+	keys := make(map[string]float64, len(beneficiaries))
+	for _, b := range beneficiaries {
+		keys[strings.ToLower(b.Address)] = sortKey(by, b.Amount, b.Score, b.Transactions)
+	}
+
+	sort.SliceStable(beneficiaries, func(i, j int) bool {
+		return keyLess(keys[strings.ToLower(beneficiaries[i].Address)], keys[strings.ToLower(beneficiaries[j].Address)], ascending)
+	})
+}
+
+// SortPayers orders payers by total amount, transaction count, last activity or
+// significance score (which must already be computed). Ties keep their existing order.
+func SortPayers(payers []Payer, by string, ascending bool) {
+	keys := make(map[string]float64, len(payers))
+	for _, p := range payers {
+		keys[strings.ToLower(p.Address)] = sortKey(by, p.Amount, p.Score, p.Transactions)
+	}
+
+	sort.SliceStable(payers, func(i, j int) bool {
+		return keyLess(keys[strings.ToLower(payers[i].Address)], keys[strings.ToLower(payers[j].Address)], ascending)
+	})
+}
+
+// sortKey returns the value a counterparty is ordered by
+func sortKey(by string, amount, score float64, transactions []TransactionDetails) float64 {
+	switch by {
+	case SortCount:
+		return float64(len(transactions))
+	case SortRecent:
+		last := lastActivity(transactions)
+		if last.IsZero() {
+			return 0
+		}
+		return float64(last.Unix())
+	case SortScore:
+		return score
+	default:
+		return amount
+	}
+}
+
+// keyLess compares two sort keys in the requested direction
+func keyLess(a, b float64, ascending bool) bool {
+	if ascending {
+		return a < b
+	}
+	return a > b
+}
@@ -0,0 +1,13 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// isSpamTransfer reports whether a token transfer comes from a denylisted spam or airdrop
+// contract. The denylist is keyed by lowercase contract address.
+func isSpamTransfer(denylist map[string]bool, transfer etherscan.TokenTransfer) bool {
+	return denylist[strings.ToLower(transfer.ContractAddress)]
+}
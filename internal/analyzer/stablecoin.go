@@ -0,0 +1,29 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// stablecoinUSDValue returns the approximate USD value of a token transfer when its contract
+// is a known stablecoin, or 0 otherwise. Pegs are keyed by lowercase contract address.
+func stablecoinUSDValue(pegs map[string]float64, transfer etherscan.TokenTransfer) float64 {
+	peg, ok := pegs[strings.ToLower(transfer.ContractAddress)]
+	if !ok {
+		return 0
+	}
+
+	decimals, err := strconv.Atoi(transfer.TokenDecimal)
+	if err != nil {
+		return 0
+	}
+
+	amount, err := scaleAmount(transfer.Value, decimals)
+	if err != nil {
+		return 0
+	}
+
+	return amount * peg
+}
@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Interval is the size of a time-series bucket
+type Interval string
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+// ParseInterval validates a time-series interval name
+func ParseInterval(s string) (Interval, error) {
+	switch Interval(s) {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return Interval(s), nil
+	default:
+		return "", fmt.Errorf("interval must be 'day', 'week', or 'month'")
+	}
+}
+
+// TimeBucket represents the flow totals of an address within one time period
+type TimeBucket struct {
+	Period   string  `json:"period"`
+	TotalOut float64 `json:"total_out"`
+	TotalIn  float64 `json:"total_in"`
+	TxCount  int     `json:"tx_count"`
+}
+
+// analyzes the flow of a given address bucketed along the time axis
+func (fa *FlowAnalyzer) AnalyzeTimeSeries(address string, interval Interval, opts Options) ([]TimeBucket, error) {
+	txs, err := fetchTransactionSet(fa.etherscanClient, address, opts.withoutTypes(fa.disabledTypes))
+	if err != nil {
+		return nil, err
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	bucketMap := make(map[string]*TimeBucket)
+
+	for _, tx := range txs.normal {
+		if tx.IsError == "0" {
+			addToTimeBucket(bucketMap, address, tx.From, tx.To, tx.Value, tx.TimeStamp, interval, loc)
+		}
+	}
+
+	for _, tx := range txs.internal {
+		if tx.IsError == "0" {
+			addToTimeBucket(bucketMap, address, tx.From, tx.To, tx.Value, tx.TimeStamp, interval, loc)
+		}
+	}
+
+	for _, transfer := range txs.tokens {
+		addToTimeBucket(bucketMap, address, transfer.From, transfer.To, transfer.Value, transfer.TimeStamp, interval, loc)
+	}
+
+	// Convert map to slice ordered by period
+	buckets := make([]TimeBucket, 0, len(bucketMap))
+	for _, bucket := range bucketMap {
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Period < buckets[j].Period
+	})
+
+	return buckets, nil
+}
+
+// adds a transaction to the time bucket it falls in
+func addToTimeBucket(bucketMap map[string]*TimeBucket, address, from, to, valueStr, timestampStr string,
+	interval Interval, loc *time.Location) {
+
+	outgoing := strings.EqualFold(from, address)
+	incoming := strings.EqualFold(to, address)
+	if !outgoing && !incoming {
+		return
+	}
+
+	amount, err := weiToEther(valueStr)
+	if err != nil {
+		return
+	}
+
+	timestamp, err := stringToInt64(timestampStr)
+	if err != nil {
+		return
+	}
+
+	period := periodStart(time.Unix(timestamp, 0).In(loc), interval)
+	bucket, exists := bucketMap[period]
+	if !exists {
+		bucket = &TimeBucket{Period: period}
+		bucketMap[period] = bucket
+	}
+
+	bucket.TxCount++
+	if outgoing {
+		bucket.TotalOut += amount
+	}
+	if incoming {
+		bucket.TotalIn += amount
+	}
+}
+
+// periodStart returns the label of the period containing t: the date for days,
+// the Monday starting the week for weeks, and the year-month for months
+func periodStart(t time.Time, interval Interval) string {
+	switch interval {
+	case IntervalWeek:
+		// Weeks start on Monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset).Format("2006-01-02")
+	case IntervalMonth:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// ErrInvalidTraceState is returned when a trace state to resume from is inconsistent
+var ErrInvalidTraceState = errors.New("invalid trace state")
+
+// TraceEdge is an aggregated flow from one traced address to one of its beneficiaries
+type TraceEdge struct {
+	From    string  `json:"from"`
+	To      string  `json:"to"`
+	Amount  float64 `json:"amount"`
+	TxCount int     `json:"tx_count"`
+	Depth   int     `json:"depth"` // Hops from the root; edges leaving the root are at depth 1
+}
+
+// Trace is the multi-hop outflow graph rooted at an address
+type Trace struct {
+	Root         string      `json:"root"`
+	Depth        int         `json:"depth"`
+	NodesVisited int         `json:"nodes_visited"`
+	Truncated    bool        `json:"truncated,omitempty"`   // The node limit stopped the trace early
+	Interrupted  string      `json:"interrupted,omitempty"` // The analysis error that stopped the trace early
+	Edges        []TraceEdge `json:"edges"`
+
+	// State is the checkpoint to resume the trace from while addresses remain to be analyzed
+	State *TraceState `json:"state,omitempty"`
+}
+
+// TraceNode is an address waiting to be analyzed in a trace
+type TraceNode struct {
+	Address string `json:"address"`
+	Level   int    `json:"level"` // Hops from the root; the root is at level 0
+}
+
+// TraceState is a checkpoint of a trace: the addresses reached so far and the queue of those
+// still to analyze, so a later request can continue the trace instead of starting over
+type TraceState struct {
+	Root         string      `json:"root"`
+	Depth        int         `json:"depth"`
+	NodesVisited int         `json:"nodes_visited"`
+	Visited      []string    `json:"visited"` // Every address reached, analyzed or queued
+	Queue        []TraceNode `json:"queue"`   // Nearest first
+}
+
+// traces funds outward from an address, breadth first, following beneficiaries up to depth hops.
+// Each visited address costs one beneficiary analysis, so at most maxNodes addresses are
+// analyzed (0 means unlimited); addresses reached again are not analyzed twice.
+func (ba *BeneficiaryAnalyzer) TraceBeneficiaries(address string, depth, maxNodes int, opts Options) (*Trace, error) {
+	root := strings.ToLower(address)
+	state := &TraceState{
+		Root:    root,
+		Visited: []string{root},
+		Queue:   []TraceNode{{Address: root}},
+	}
+	return ba.runTrace(state, depth, maxNodes, opts)
+}
+
+// resumes a trace from its checkpoint, extending its depth by additionalDepth hops. At most
+// maxNodes more addresses are analyzed (0 means unlimited); the returned trace holds only the
+// edges found since the checkpoint.
+func (ba *BeneficiaryAnalyzer) ResumeTrace(state *TraceState, additionalDepth, maxNodes int, opts Options) (*Trace, error) {
+	if err := state.validate(); err != nil {
+		return nil, err
+	}
+	if additionalDepth < 0 {
+		return nil, fmt.Errorf("%w: additional depth must not be negative", ErrInvalidTraceState)
+	}
+	return ba.runTrace(state, state.Depth+additionalDepth, maxNodes, opts)
+}
+
+// runs a trace from its state until the queue is exhausted up to depth, maxNodes addresses
+// were analyzed or an analysis fails. A failure before any progress is returned as an error;
+// later ones interrupt the trace, which keeps its checkpoint.
+func (ba *BeneficiaryAnalyzer) runTrace(state *TraceState, depth, maxNodes int, opts Options) (*Trace, error) {
+	trace := &Trace{Root: state.Root, Depth: depth, NodesVisited: state.NodesVisited, Edges: []TraceEdge{}}
+
+	visited := make(map[string]bool, len(state.Visited))
+	for _, address := range state.Visited {
+		visited[strings.ToLower(address)] = true
+	}
+	queue := make([]TraceNode, len(state.Queue))
+	for i, node := range state.Queue {
+		queue[i] = TraceNode{Address: strings.ToLower(node.Address), Level: node.Level}
+	}
+
+	analyzed := 0
+	for len(queue) > 0 && queue[0].Level < depth {
+		if maxNodes > 0 && analyzed >= maxNodes {
+			trace.Truncated = true
+			break
+		}
+
+		node := queue[0]
+		beneficiaries, err := ba.AnalyzeBeneficiary(node.Address, opts)
+		if err != nil {
+			err = fmt.Errorf("error tracing %s: %w", node.Address, err)
+			if analyzed == 0 {
+				return nil, err
+			}
+			trace.Interrupted = err.Error()
+			break
+		}
+		queue = queue[1:]
+		analyzed++
+		trace.NodesVisited++
+
+		for _, b := range beneficiaries {
+			if b.Address == "" || b.Address == GasBeneficiary {
+				continue // Contract creation or gas fees
+			}
+			trace.Edges = append(trace.Edges, TraceEdge{
+				From:    node.Address,
+				To:      b.Address,
+				Amount:  b.Amount,
+				TxCount: b.TxCount,
+				Depth:   node.Level + 1,
+			})
+			if !visited[b.Address] {
+				visited[b.Address] = true
+				queue = append(queue, TraceNode{Address: b.Address, Level: node.Level + 1})
+			}
+		}
+	}
+
+	if len(queue) > 0 {
+		trace.State = &TraceState{
+			Root:         trace.Root,
+			Depth:        depth,
+			NodesVisited: trace.NodesVisited,
+			Visited:      make([]string, 0, len(visited)),
+			Queue:        queue,
+		}
+		for address := range visited {
+			trace.State.Visited = append(trace.State.Visited, address)
+		}
+		sort.Strings(trace.State.Visited)
+	}
+
+	return trace, nil
+}
+
+// validate checks that the state can be resumed: valid addresses and a breadth-first queue
+// within the trace's depth
+func (s *TraceState) validate() error {
+	if s == nil {
+		return fmt.Errorf("%w: missing state", ErrInvalidTraceState)
+	}
+	if !etherscan.IsValidAddress(s.Root) {
+		return fmt.Errorf("%w: root is not a valid Ethereum address", ErrInvalidTraceState)
+	}
+	if s.Depth < 1 || s.NodesVisited < 0 {
+		return fmt.Errorf("%w: depth must be positive and nodes_visited not negative", ErrInvalidTraceState)
+	}
+	for _, address := range s.Visited {
+		if !etherscan.IsValidAddress(address) {
+			return fmt.Errorf("%w: visited address %q is not a valid Ethereum address", ErrInvalidTraceState, address)
+		}
+	}
+
+	level := 0
+	for _, node := range s.Queue {
+		if !etherscan.IsValidAddress(node.Address) {
+			return fmt.Errorf("%w: queued address %q is not a valid Ethereum address", ErrInvalidTraceState, node.Address)
+		}
+		if node.Level < level || node.Level > s.Depth {
+			return fmt.Errorf("%w: queue levels must be ascending and within the depth", ErrInvalidTraceState)
+		}
+		level = node.Level
+	}
+	return nil
+}
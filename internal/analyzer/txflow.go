@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"golang.org/x/sync/errgroup"
+)
+
+// transferEventTopic is the keccak256 hash of Transfer(address,address,uint256), shared by
+// ERC-20 and ERC-721 transfer events
+const transferEventTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// Movement types
+const (
+	MovementNormal   = "normal"
+	MovementInternal = "internal"
+	MovementToken    = "token"
+	MovementNFT      = "nft"
+)
+
+// ValueMovement represents a single movement of value triggered by a transaction
+type ValueMovement struct {
+	Type string `json:"type"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Amount is the value in Ether for native movements; token amounts are only available
+	// raw since event logs carry no decimals
+	Amount        float64 `json:"amount,omitempty"`
+	RawValue      string  `json:"raw_value"`
+	TokenContract string  `json:"token_contract,omitempty"`
+}
+
+// TransactionFlow represents every value movement triggered by a single transaction
+type TransactionFlow struct {
+	Hash        string          `json:"hash"`
+	BlockNumber int64           `json:"block_number"`
+	Success     bool            `json:"success"`
+	Movements   []ValueMovement `json:"movements"`
+
+	// timestamp is the block's Unix timestamp, only looked up when aggregating a list of transactions
+	timestamp string
+}
+
+// maps the fund flow of a single transaction: its own value transfer, the internal
+// transactions it spawned and the token transfers emitted in its receipt logs
+func (fa *FlowAnalyzer) AnalyzeTransaction(hash string) (*TransactionFlow, error) {
+	var tx *etherscan.ProxyTransaction
+	var receipt *etherscan.TransactionReceipt
+	var internalTxs []etherscan.Transaction
+	eg := errgroup.Group{}
+
+	eg.Go(func() error {
+		var err error
+		tx, err = fa.etherscanClient.GetTransactionByHash(hash)
+		return err
+	})
+
+	eg.Go(func() error {
+		var err error
+		receipt, err = fa.etherscanClient.GetTransactionReceipt(hash)
+		return err
+	})
+
+	eg.Go(func() error {
+		var err error
+		internalTxs, err = fa.etherscanClient.GetInternalTransactionsByHash(hash)
+		if err != nil {
+			return fmt.Errorf("error fetching internal transactions: %w", err)
+		}
+		return nil
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	flow := &TransactionFlow{
+		Hash:      tx.Hash,
+		Success:   receipt.Status == "0x1",
+		Movements: []ValueMovement{},
+	}
+	if blockNumber, err := hexToBigInt(receipt.BlockNumber); err == nil {
+		flow.BlockNumber = blockNumber.Int64()
+	}
+
+	// A reverted transaction moved nothing
+	if !flow.Success {
+		return flow, nil
+	}
+
+	if value, err := hexToBigInt(tx.Value); err == nil && value.Sign() > 0 {
+		flow.Movements = append(flow.Movements, nativeMovement(MovementNormal, tx.From, tx.To, value.String()))
+	}
+
+	for _, itx := range internalTxs {
+		if itx.IsError == "0" && itx.Value != "0" {
+			flow.Movements = append(flow.Movements, nativeMovement(MovementInternal, itx.From, itx.To, itx.Value))
+		}
+	}
+
+	for _, log := range receipt.Logs {
+		if movement, ok := transferMovement(log); ok {
+			flow.Movements = append(flow.Movements, movement)
+		}
+	}
+
+	return flow, nil
+}
+
+// nativeMovement builds an Ether value movement from a decimal Wei value
+func nativeMovement(movementType, from, to, valueStr string) ValueMovement {
+	amount, _ := weiToEther(valueStr)
+	return ValueMovement{
+		Type:     movementType,
+		From:     from,
+		To:       to,
+		Amount:   amount,
+		RawValue: valueStr,
+	}
+}
+
+// transferMovement decodes a Transfer event log. ERC-20 transfers carry the amount in the
+// data, while ERC-721 transfers index the token ID as a fourth topic.
+func transferMovement(log etherscan.Log) (ValueMovement, bool) {
+	if len(log.Topics) < 3 || !strings.EqualFold(log.Topics[0], transferEventTopic) {
+		return ValueMovement{}, false
+	}
+
+	movement := ValueMovement{
+		Type:          MovementToken,
+		From:          topicAddress(log.Topics[1]),
+		To:            topicAddress(log.Topics[2]),
+		TokenContract: log.Address,
+	}
+
+	raw := log.Data
+	if len(log.Topics) == 4 {
+		movement.Type = MovementNFT
+		raw = log.Topics[3]
+	}
+
+	value, err := hexToBigInt(raw)
+	if err != nil {
+		return ValueMovement{}, false
+	}
+	movement.RawValue = value.String()
+
+	return movement, true
+}
+
+// topicAddress extracts the address from a 32-byte indexed topic
+func topicAddress(topic string) string {
+	topic = strings.TrimPrefix(topic, "0x")
+	if len(topic) < 40 {
+		return "0x" + topic
+	}
+	return "0x" + topic[len(topic)-40:]
+}
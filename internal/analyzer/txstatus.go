@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"golang.org/x/sync/errgroup"
+)
+
+// Transaction execution statuses
+const (
+	TxStatusSuccess = "success"
+	TxStatusFailed  = "failed"
+	TxStatusPending = "pending"
+	TxStatusUnknown = "unknown" // Mined before receipts carried a status (pre-Byzantium)
+)
+
+// TransactionStatus is the outcome and basic details of a single transaction
+type TransactionStatus struct {
+	Hash        string  `json:"hash"`
+	Status      string  `json:"status"`
+	BlockNumber int64   `json:"block_number,omitempty"`
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Value       float64 `json:"value"` // Ether
+	RawValue    string  `json:"raw_value"`
+	GasUsed     int64   `json:"gas_used,omitempty"`
+}
+
+// looks up whether a transaction succeeded, together with its block, gas used, parties and value.
+// A transaction not yet mined is reported as pending.
+func (fa *FlowAnalyzer) TransactionStatus(hash string) (*TransactionStatus, error) {
+	var tx *etherscan.ProxyTransaction
+	var receipt *etherscan.TransactionReceipt
+	var receiptStatus string
+	eg := errgroup.Group{}
+
+	eg.Go(func() error {
+		var err error
+		tx, err = fa.etherscanClient.GetTransactionByHash(hash)
+		return err
+	})
+
+	eg.Go(func() error {
+		var err error
+		receiptStatus, err = fa.etherscanClient.GetTransactionReceiptStatus(hash)
+		return err
+	})
+
+	eg.Go(func() error {
+		var err error
+		receipt, err = fa.etherscanClient.GetTransactionReceipt(hash)
+		if errors.Is(err, etherscan.ErrTransactionNotFound) {
+			return nil // Pending transactions have no receipt yet
+		}
+		return err
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	status := &TransactionStatus{
+		Hash:     tx.Hash,
+		From:     tx.From,
+		To:       tx.To,
+		RawValue: decimalValue(tx.Value),
+	}
+	if value, err := weiToEther(tx.Value); err == nil {
+		status.Value = value
+	}
+	if blockNumber, err := hexToBigInt(tx.BlockNumber); err == nil {
+		status.BlockNumber = blockNumber.Int64()
+	}
+
+	switch {
+	case receipt == nil || tx.BlockNumber == "":
+		status.Status = TxStatusPending
+	case receiptStatus == "1":
+		status.Status = TxStatusSuccess
+	case receiptStatus == "0":
+		status.Status = TxStatusFailed
+	default:
+		status.Status = TxStatusUnknown
+	}
+
+	if receipt != nil {
+		if gasUsed, err := hexToBigInt(receipt.GasUsed); err == nil {
+			status.GasUsed = gasUsed.Int64()
+		}
+	}
+
+	return status, nil
+}
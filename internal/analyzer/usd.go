@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/price"
+)
+
+// etherUSDValue returns the USD value of a native transfer at ETHPriceUSD when set, at its
+// timestamp when historical prices were requested, or 0 when neither was or the feed has no price
+func etherUSDValue(prices price.Provider, opts Options, valueStr, timestampStr string) float64 {
+	if opts.ETHPriceUSD <= 0 && (!opts.USD || prices == nil) {
+		return 0
+	}
+
+	amount, err := scaleAmount(valueStr, opts.native())
+	if err != nil {
+		return 0
+	}
+	if opts.ETHPriceUSD > 0 {
+		return amount * opts.ETHPriceUSD
+	}
+	value, _ := historicalUSDValue(prices, price.Ether, amount, timestampStr)
+	return value
+}
+
+// tokenUSDValue returns the USD value of a token transfer, priced at its timestamp when
+// historical prices were requested and available, and by its stablecoin peg otherwise
+func tokenUSDValue(prices price.Provider, opts Options, pegs map[string]float64, transfer etherscan.TokenTransfer) float64 {
+	if opts.USD && prices != nil {
+		decimals, err := strconv.Atoi(transfer.TokenDecimal)
+		if err == nil {
+			amount, err := scaleAmount(transfer.Value, decimals)
+			if err == nil {
+				if value, ok := historicalUSDValue(prices, strings.ToLower(transfer.ContractAddress), amount, transfer.TimeStamp); ok {
+					return value
+				}
+			}
+		}
+	}
+	return stablecoinUSDValue(pegs, transfer)
+}
+
+// historicalUSDValue prices an amount of an asset on the day of a Unix timestamp, reporting
+// false when no price can be found
+func historicalUSDValue(prices price.Provider, asset string, amount float64, timestampStr string) (float64, bool) {
+	timestamp, err := stringToInt64(timestampStr)
+	if err != nil {
+		return 0, false
+	}
+
+	usdPrice, err := prices.USDPrice(asset, time.Unix(timestamp, 0))
+	if err != nil {
+		return 0, false
+	}
+	return amount * usdPrice, true
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import "strings"
+
+// How wrap/unwrap flows are reported (Options.WETH)
+const (
+	WETHFold  = "fold"
+	WETHLabel = "label"
+)
+
+// Transaction kinds of Ether moving into and out of WETH
+const (
+	kindWrap   = "wrap"
+	kindUnwrap = "unwrap"
+)
+
+// wrapKind classifies a native transfer to the WETH contract as a wrap (deposit) and one from
+// it as an unwrap (withdrawal). Zero-value calls such as approvals are not classified.
+func wrapKind(weth, from, to, valueStr string) string {
+	if weth == "" {
+		return ""
+	}
+	if value, err := parseRawValue(valueStr); err != nil || value.Sign() == 0 {
+		return ""
+	}
+
+	switch {
+	case strings.EqualFold(to, weth):
+		return kindWrap
+	case strings.EqualFold(from, weth):
+		return kindUnwrap
+	}
+	return ""
+}
+
+// isWrapKind reports whether the transaction kind is a WETH wrap or unwrap
+func isWrapKind(kind string) bool {
+	return kind == kindWrap || kind == kindUnwrap
+}
+
+// wrapFlow applies the requested WETH handling to a native transfer, returning the kind to
+// record it with and whether it should be skipped entirely
+func wrapFlow(opts Options, weth, from, to, valueStr string) (kind string, skip bool) {
+	if opts.WETH == "" {
+		return "", false
+	}
+
+	kind = wrapKind(weth, from, to, valueStr)
+	if kind != "" && opts.WETH == WETHFold {
+		return "", true
+	}
+	return kind, false
+}
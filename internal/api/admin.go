@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+)
+
+// ModeRequest represents the request body for the admin mode endpoint
+type ModeRequest struct {
+	Mode string `json:"mode"`
+}
+
+// ModeResponse represents the response format for the admin mode endpoint
+type ModeResponse struct {
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	Mode      string `json:"mode"`
+}
+
+// handleSetMode handles the /admin/mode endpoint, switching the analysis mode without a restart.
+// The change lasts until the next restart, which falls back to ANALYSIS_MODE. The endpoint is
+// only registered when API_AUTH_TOKEN is set.
+func (r *Router) handleSetMode(w http.ResponseWriter, req *http.Request) {
+	var body ModeRequest
+	if err := decodeJSONBody(w, req, r.handler.config.MaxBodyBytes, &body); err != nil {
+		r.handler.respondWithError(w, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !config.ValidAnalysisMode(body.Mode) {
+		r.handler.respondWithError(w, req, http.StatusBadRequest, "mode must be 'beneficiary', 'payer', or 'both'")
+		return
+	}
+
+	r.SetAnalysisMode(body.Mode)
+	requestLogger(r.logger, req).Infof("Analysis mode changed to: %s", body.Mode)
+
+	r.handler.respondWithJSON(w, req, http.StatusOK, ModeResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(req.Context()),
+		Mode:      body.Mode,
+	})
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
+)
+
+// newTestRouter returns the routes of a server running with cfg against an unreachable Etherscan
+func newTestRouter(cfg *config.Config) http.Handler {
+	client := etherscan.NewClient("TESTKEY", "http://127.0.0.1:0")
+	router := NewRouter(cfg, client, analyzer.NewBeneficiaryAnalyzer(client), analyzer.NewPayerAnalyzer(client),
+		analyzer.NewFlowAnalyzer(client), logger.NewLogger())
+	return router.Setup()
+}
+
+// postMode posts a mode change with the given Authorization header (empty sends none)
+func postMode(routes http.Handler, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/admin/mode", strings.NewReader(`{"mode":"payer"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminModeNotRegisteredWithoutToken(t *testing.T) {
+	cfg := testConfig()
+	cfg.AnalysisMode = "both"
+
+	if rec := postMode(newTestRouter(cfg), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("status %d without API_AUTH_TOKEN, want 404: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminModeRequiresToken(t *testing.T) {
+	cfg := testConfig()
+	cfg.AnalysisMode = "both"
+	cfg.APIAuthToken = "secret"
+	routes := newTestRouter(cfg)
+
+	if rec := postMode(routes, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status %d without a bearer token, want 401", rec.Code)
+	}
+	if rec := postMode(routes, "Bearer wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status %d with a wrong bearer token, want 401", rec.Code)
+	}
+	if rec := postMode(routes, "Bearer secret"); rec.Code != http.StatusOK {
+		t.Errorf("status %d with the bearer token, want 200: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// maxBatchSize is the maximum number of addresses accepted in a single batch request
+	maxBatchSize = 50
+
+	// batchWorkers bounds how many addresses of a batch are analyzed concurrently
+	batchWorkers = 5
+)
+
+// BatchRequest represents the request body for batch endpoints
+type BatchRequest struct {
+	Addresses []string `json:"addresses"`
+}
+
+// BatchBeneficiaryResult represents the analysis result for a single address in a batch
+type BatchBeneficiaryResult struct {
+	Data  []BeneficiaryData `json:"data"`
+	Error string            `json:"error,omitempty"`
+}
+
+// BatchBeneficiaryResponse represents the response format for the batch beneficiary endpoint
+type BatchBeneficiaryResponse struct {
+	Message   string                            `json:"message"`
+	RequestID string                            `json:"request_id,omitempty"`
+	Results   map[string]BatchBeneficiaryResult `json:"results"`
+}
+
+// HandleBatchBeneficiary handles the /batch/beneficiary endpoint
+func (h *Handler) HandleBatchBeneficiary(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := decodeJSONBody(w, r, h.config.MaxBodyBytes, &req); err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(req.Addresses) == 0 {
+		h.respondWithError(w, r, http.StatusBadRequest, "addresses must contain at least one address")
+		return
+	}
+	if len(req.Addresses) > maxBatchSize {
+		h.respondWithError(w, r, http.StatusBadRequest, fmt.Sprintf("batch size exceeds maximum of %d addresses", maxBatchSize))
+		return
+	}
+
+	addresses, err := sanitizeAddresses(req.Addresses)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	precise := r.URL.Query().Get("precise") == "true"
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Analyzing beneficiaries for batch of %d addresses", len(addresses))
+
+	results := make(map[string]BatchBeneficiaryResult, len(addresses))
+	var mu sync.Mutex
+
+	// Analyze addresses on a bounded worker pool so large batches don't flood Etherscan
+	eg := errgroup.Group{}
+	eg.SetLimit(batchWorkers)
+
+	for _, address := range addresses {
+		address := address
+		eg.Go(func() error {
+			var result BatchBeneficiaryResult
+
+			beneficiaries, err := h.beneficiaryAnalyzer.AnalyzeBeneficiary(address, opts)
+			if err != nil {
+				log.Errorf("Error analyzing beneficiary for %s: %v", address, err)
+				result.Error = err.Error()
+			} else {
+				result.Data = toBeneficiaryData(beneficiaries, h.amountFormat(precise))
+			}
+
+			mu.Lock()
+			results[address] = result
+			mu.Unlock()
+			return nil
+		})
+	}
+	eg.Wait()
+
+	h.respondWithJSON(w, r, http.StatusOK, BatchBeneficiaryResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Results:   results,
+	})
+}
+
+// sanitizeAddresses trims and lowercases the batch addresses, dropping duplicates, and rejects
+// the batch if any of them is not a valid Ethereum address
+func sanitizeAddresses(addresses []string) ([]string, error) {
+	seen := make(map[string]bool, len(addresses))
+	sanitized := make([]string, 0, len(addresses))
+	for i, address := range addresses {
+		address = strings.ToLower(strings.TrimSpace(address))
+		if !etherscan.IsValidAddress(address) {
+			return nil, fmt.Errorf("addresses[%d] is not a valid Ethereum address: %q", i, addresses[i])
+		}
+		if !seen[address] {
+			seen[address] = true
+			sanitized = append(sanitized, address)
+		}
+	}
+	return sanitized, nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// decodeJSONBody strictly decodes a JSON request body into dst. The body is limited to
+// limit bytes (0 means unlimited), unknown fields are rejected and only a single JSON value
+// is accepted. The returned error is suitable for a 400 response.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, limit int64, dst interface{}) error {
+	if limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			return fmt.Errorf("request body must not exceed %d bytes", maxBytesErr.Limit)
+		case errors.Is(err, io.EOF):
+			return fmt.Errorf("request body must not be empty")
+		default:
+			return fmt.Errorf("invalid request body: %v", err)
+		}
+	}
+
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("request body must contain a single JSON object")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+)
+
+// breakdownByMethod is the breakdown mode totaling outgoing value by the method called
+const breakdownByMethod = "by_method"
+
+// MethodBreakdownResponse represents the response format for the beneficiary endpoint with breakdown=by_method
+type MethodBreakdownResponse struct {
+	Message   string                `json:"message"`
+	RequestID string                `json:"request_id,omitempty"`
+	Data      []MethodBreakdownData `json:"data"`
+
+	// HasSanctionedInteraction is set when SANCTIONS_LIST_PATH is configured
+	HasSanctionedInteraction *bool `json:"has_sanctioned_interaction,omitempty"`
+}
+
+// MethodBreakdownData represents the outflow of the transactions calling one method
+type MethodBreakdownData struct {
+	Method   string  `json:"method"`
+	Amount   Decimal `json:"amount"`
+	USDValue float64 `json:"usd_value,omitempty"`
+	TxCount  int     `json:"tx_count"`
+
+	// PrecisionWarning is set when the amount exceeded PRECISION_LIMIT and is written exactly
+	PrecisionWarning bool `json:"precision_warning,omitempty"`
+}
+
+// respondWithMethodBreakdown totals the beneficiaries' transactions by the method each called,
+// largest first. Totals cover every beneficiary matching the filters, so top and the result
+// caps don't apply.
+func (h *Handler) respondWithMethodBreakdown(w http.ResponseWriter, r *http.Request, beneficiaries []analyzer.Beneficiary, sanctioned bool) {
+	format := h.amountFormat(r.URL.Query().Get("precise") == "true")
+	response := MethodBreakdownResponse{
+		Message:                  "success",
+		RequestID:                requestIDFromContext(r.Context()),
+		Data:                     []MethodBreakdownData{},
+		HasSanctionedInteraction: h.sanctionScreening(sanctioned),
+	}
+
+	for _, total := range analyzer.SummarizeByMethod(beneficiaries) {
+		amount := newDecimal(total.Amount, total.RawAmount, format)
+		response.Data = append(response.Data, MethodBreakdownData{
+			Method:           total.Method,
+			Amount:           amount,
+			PrecisionWarning: amount.imprecise,
+			USDValue:         total.USDValue,
+			TxCount:          total.TxCount,
+		})
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, response)
+}
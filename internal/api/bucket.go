@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+)
+
+// BucketedBeneficiaryResponse represents the response format for the beneficiary endpoint with bucket=true
+type BucketedBeneficiaryResponse struct {
+	Message   string                  `json:"message"`
+	RequestID string                  `json:"request_id,omitempty"`
+	Truncated bool                    `json:"truncated,omitempty"`
+	Data      []BeneficiaryBucketData `json:"data"`
+
+	// HasSanctionedInteraction is set when SANCTIONS_LIST_PATH is configured
+	HasSanctionedInteraction *bool `json:"has_sanctioned_interaction,omitempty"`
+}
+
+// BeneficiaryBucketData represents one bucket of beneficiaries with its totals
+type BeneficiaryBucketData struct {
+	Bucket        string            `json:"bucket"`
+	Amount        Decimal           `json:"amount"`
+	USDValue      float64           `json:"usd_value,omitempty"`
+	Count         int               `json:"count"`
+	Beneficiaries []BeneficiaryData `json:"beneficiaries"`
+
+	// PrecisionWarning is set when the amount exceeded PRECISION_LIMIT and is written exactly
+	PrecisionWarning bool `json:"precision_warning,omitempty"`
+}
+
+// respondWithBuckets groups the beneficiaries into exchanges, contracts and wallets. Bucket
+// totals cover every beneficiary matching the filters; each bucket lists only those among
+// the listed (top) beneficiaries, under the result caps.
+func (h *Handler) respondWithBuckets(w http.ResponseWriter, r *http.Request, beneficiaries, listed []analyzer.Beneficiary, sanctioned bool, txCap transactionCap) {
+	format := h.amountFormat(r.URL.Query().Get("precise") == "true")
+	response := BucketedBeneficiaryResponse{
+		Message:                  "success",
+		RequestID:                requestIDFromContext(r.Context()),
+		HasSanctionedInteraction: h.sanctionScreening(sanctioned),
+	}
+
+	inTop := make(map[string]bool, len(listed))
+	for _, b := range listed {
+		inTop[b.Address] = true
+	}
+
+	for _, bucket := range h.beneficiaryAnalyzer.BucketBeneficiaries(beneficiaries) {
+		var entries []analyzer.Beneficiary
+		for _, b := range bucket.Beneficiaries {
+			if inTop[b.Address] {
+				entries = append(entries, b)
+			}
+		}
+		entries, truncated := analyzer.CapBeneficiaries(entries, h.config.MaxCounterparties, txCap.max, txCap.keep)
+		response.Truncated = response.Truncated || truncated
+		amount := newDecimal(bucket.Amount, bucket.RawAmount, format)
+		response.Data = append(response.Data, BeneficiaryBucketData{
+			Bucket:           bucket.Name,
+			Amount:           amount,
+			PrecisionWarning: amount.imprecise,
+			USDValue:         bucket.USDValue,
+			Count:            bucket.Count,
+			Beneficiaries:    toBeneficiaryData(entries, format),
+		})
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, response)
+}
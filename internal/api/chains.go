@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxChains bounds how many chains one multi-chain analysis may span
+const maxChains = 10
+
+// parseChains reads chains, a comma-separated list of chain IDs to analyze and merge. Chain
+// selection goes through the chainid parameter of the Etherscan V2 API, so it is rejected
+// unless ETHERSCAN_BASE_URL points at a V2 endpoint.
+func (h *Handler) parseChains(r *http.Request) ([]int, error) {
+	value := r.URL.Query().Get("chains")
+	if value == "" {
+		return nil, nil
+	}
+	if !strings.Contains(h.config.EtherscanBaseURL, "/v2/") {
+		return nil, fmt.Errorf("chains requires the Etherscan V2 API, e.g. ETHERSCAN_BASE_URL=https://api.etherscan.io/v2/api")
+	}
+
+	seen := make(map[int]bool)
+	var chains []int
+	for _, entry := range strings.Split(value, ",") {
+		chainID, err := strconv.Atoi(strings.TrimSpace(entry))
+		if err != nil || chainID < 1 {
+			return nil, fmt.Errorf("chains must be a comma-separated list of positive chain IDs")
+		}
+		if !seen[chainID] {
+			seen[chainID] = true
+			chains = append(chains, chainID)
+		}
+	}
+	if len(chains) > maxChains {
+		return nil, fmt.Errorf("chains must not list more than %d chains", maxChains)
+	}
+	return chains, nil
+}
+
+// analyzeBeneficiaryChains runs the beneficiary analysis on each chain concurrently and merges
+// the results, breaking each counterparty's flow down per chain
+func (h *Handler) analyzeBeneficiaryChains(address string, opts analyzer.Options, chains []int) ([]analyzer.Beneficiary, error) {
+	results := make([][]analyzer.Beneficiary, len(chains))
+	eg := errgroup.Group{}
+
+	for i, chainID := range chains {
+		i, chainOpts := i, opts
+		chainOpts.ChainID = chainID
+		eg.Go(func() error {
+			beneficiaries, err := h.beneficiaryAnalyzer.AnalyzeBeneficiary(address, chainOpts)
+			if err != nil {
+				return fmt.Errorf("chain %d: %w", chainOpts.ChainID, err)
+			}
+			results[i] = beneficiaries
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return analyzer.MergeChainBeneficiaries(chains, results), nil
+}
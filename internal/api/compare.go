@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"golang.org/x/sync/errgroup"
+)
+
+// CompareResponse represents the response format for the compare endpoint
+type CompareResponse struct {
+	Message   string               `json:"message"`
+	RequestID string               `json:"request_id,omitempty"`
+	Data      *analyzer.Comparison `json:"data"`
+}
+
+// HandleCompare handles the /compare endpoint, analyzing the beneficiaries of both addresses
+// concurrently and comparing the results
+func (h *Handler) HandleCompare(w http.ResponseWriter, r *http.Request) {
+	a := r.URL.Query().Get("a")
+	b := r.URL.Query().Get("b")
+	if a == "" || b == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "a and b parameters are required")
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Comparing beneficiaries of %s and %s", a, b)
+
+	var beneficiariesA, beneficiariesB []analyzer.Beneficiary
+	eg := errgroup.Group{}
+	eg.Go(func() error {
+		var err error
+		beneficiariesA, err = h.beneficiaryAnalyzer.AnalyzeBeneficiary(a, opts)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		beneficiariesB, err = h.beneficiaryAnalyzer.AnalyzeBeneficiary(b, opts)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		log.Errorf("Error comparing addresses: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, CompareResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Data:      analyzer.CompareBeneficiaries(a, b, beneficiariesA, beneficiariesB),
+	})
+}
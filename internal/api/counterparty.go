@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// CounterpartyResponse represents the response format for the counterparty endpoint
+type CounterpartyResponse struct {
+	Message   string                     `json:"message"`
+	RequestID string                     `json:"request_id,omitempty"`
+	Data      *analyzer.CounterpartyFlow `json:"data"`
+}
+
+// HandleCounterparty handles the /counterparty endpoint
+func (h *Handler) HandleCounterparty(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "from and to parameters are required")
+		return
+	}
+	if !etherscan.IsValidAddress(from) || !etherscan.IsValidAddress(to) {
+		h.respondWithError(w, r, http.StatusBadRequest, "from and to must be Ethereum addresses")
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeReverse := r.URL.Query().Get("reverse") == "true"
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Analyzing flow from %s to %s", from, to)
+
+	flow, err := h.flowAnalyzer.AnalyzeCounterparty(from, to, includeReverse, opts)
+	if err != nil {
+		log.Errorf("Error analyzing counterparty: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, CounterpartyResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Data:      flow,
+	})
+}
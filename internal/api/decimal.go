@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+)
+
+// amountFormat controls how amounts are written: as exact decimal strings in precise mode,
+// and otherwise as floats unless they exceed the precision limit
+type amountFormat struct {
+	precise        bool
+	precisionLimit float64 // 0 means no limit
+}
+
+// amountFormat returns the format for a response's amounts under the configured PRECISION_LIMIT
+func (h *Handler) amountFormat(precise bool) amountFormat {
+	return amountFormat{precise: precise, precisionLimit: h.config.PrecisionLimit}
+}
+
+// exceedsLimit reports whether a float amount is too large to be written as a float
+func (f amountFormat) exceedsLimit(value float64) bool {
+	return f.precisionLimit > 0 && math.Abs(value) > f.precisionLimit
+}
+
+// Decimal is an amount serialized as a JSON number by default, or as an exact decimal
+// string in precise mode so consumers don't see float64 rounding artifacts
+type Decimal struct {
+	value float64
+	exact string
+
+	// imprecise is set when the float exceeded the precision limit outside precise mode
+	imprecise bool
+}
+
+// newDecimal creates an amount from its float value and its exact raw Wei value. Amounts
+// beyond the precision limit are written exactly even outside precise mode, and flagged.
+func newDecimal(value float64, raw *big.Int, format amountFormat) Decimal {
+	d := Decimal{value: value}
+	if !format.precise && format.exceedsLimit(value) {
+		d.imprecise = true
+	} else if !format.precise {
+		return d
+	}
+	if raw != nil {
+		d.exact = analyzer.FormatEther(raw)
+	}
+	return d
+}
+
+// MarshalJSON writes the exact decimal string when set, and the float otherwise
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d.exact != "" {
+		return json.Marshal(d.exact)
+	}
+	return json.Marshal(d.value)
+}
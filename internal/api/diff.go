@@ -0,0 +1,129 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// CounterpartySnapshot is an address's known counterparties: the body of the diff endpoint,
+// and the snapshot it returns to send next time
+type CounterpartySnapshot struct {
+	Address       string   `json:"address"`
+	Beneficiaries []string `json:"beneficiaries"`
+	Payers        []string `json:"payers"`
+}
+
+// DiffResponse represents the response format for the diff endpoint
+type DiffResponse struct {
+	Message   string   `json:"message"`
+	RequestID string   `json:"request_id,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+	Data      DiffData `json:"data"`
+
+	// HasSanctionedInteraction is set when SANCTIONS_LIST_PATH is configured and covers the
+	// new counterparties only
+	HasSanctionedInteraction *bool `json:"has_sanctioned_interaction,omitempty"`
+}
+
+// DiffData represents the counterparties missing from the prior snapshot
+type DiffData struct {
+	NewBeneficiaries []BeneficiaryData    `json:"new_beneficiaries,omitempty"`
+	NewPayers        []PayerData          `json:"new_payers,omitempty"`
+	Snapshot         CounterpartySnapshot `json:"snapshot"`
+}
+
+// handleDiff handles the /diff endpoint. It analyzes the address's beneficiaries and/or payers
+// (per the current analysis mode) and returns only those missing from the snapshot in the
+// body, with the current snapshot to diff against next time.
+func (r *Router) handleDiff(w http.ResponseWriter, req *http.Request) {
+	h := r.handler
+
+	var snapshot CounterpartySnapshot
+	if err := decodeJSONBody(w, req, h.config.MaxBodyBytes, &snapshot); err != nil {
+		h.respondWithError(w, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	address := strings.ToLower(strings.TrimSpace(snapshot.Address))
+	if !etherscan.IsValidAddress(address) {
+		h.respondWithError(w, req, http.StatusBadRequest, "address must be an Ethereum address")
+		return
+	}
+	knownBeneficiaries, err := sanitizeAddresses(snapshot.Beneficiaries)
+	if err != nil {
+		h.respondWithError(w, req, http.StatusBadRequest, fmt.Sprintf("beneficiaries: %v", err))
+		return
+	}
+	knownPayers, err := sanitizeAddresses(snapshot.Payers)
+	if err != nil {
+		h.respondWithError(w, req, http.StatusBadRequest, fmt.Sprintf("payers: %v", err))
+		return
+	}
+
+	opts, err := parseAnalysisOptions(req)
+	if err != nil {
+		h.respondWithError(w, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	txCap, err := parseTransactionCap(req, h.config.MaxTxPerCounterparty)
+	if err != nil {
+		h.respondWithError(w, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := h.amountFormat(req.URL.Query().Get("precise") == "true")
+
+	log := requestLogger(h.logger, req)
+	log.Infof("Diffing counterparties of %s against %d known beneficiaries and %d known payers",
+		address, len(knownBeneficiaries), len(knownPayers))
+
+	response := DiffResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(req.Context()),
+		Data:      DiffData{Snapshot: CounterpartySnapshot{Address: address, Beneficiaries: []string{}, Payers: []string{}}},
+	}
+	sanctioned := false
+	mode := r.mode()
+
+	if mode == "beneficiary" || mode == "both" {
+		beneficiaries, err := h.beneficiaryAnalyzer.AnalyzeBeneficiary(address, opts)
+		if err != nil {
+			log.Errorf("Error analyzing beneficiary: %v", err)
+			h.respondWithAnalysisError(w, req, err)
+			return
+		}
+		for _, b := range beneficiaries {
+			response.Data.Snapshot.Beneficiaries = append(response.Data.Snapshot.Beneficiaries, strings.ToLower(b.Address))
+		}
+
+		fresh, truncated := analyzer.CapBeneficiaries(analyzer.NewBeneficiaries(beneficiaries, knownBeneficiaries),
+			h.config.MaxCounterparties, txCap.max, txCap.keep)
+		response.Truncated = response.Truncated || truncated
+		sanctioned = sanctioned || analyzer.HasSanctionedBeneficiary(fresh)
+		response.Data.NewBeneficiaries = toBeneficiaryData(fresh, format)
+	}
+
+	if mode == "payer" || mode == "both" {
+		payers, err := h.payerAnalyzer.AnalyzePayer(address, opts)
+		if err != nil {
+			log.Errorf("Error analyzing payer: %v", err)
+			h.respondWithAnalysisError(w, req, err)
+			return
+		}
+		for _, p := range payers {
+			response.Data.Snapshot.Payers = append(response.Data.Snapshot.Payers, strings.ToLower(p.Address))
+		}
+
+		fresh, truncated := analyzer.CapPayers(analyzer.NewPayers(payers, knownPayers),
+			h.config.MaxCounterparties, txCap.max, txCap.keep)
+		response.Truncated = response.Truncated || truncated
+		sanctioned = sanctioned || analyzer.HasSanctionedPayer(fresh)
+		response.Data.NewPayers = toPayerData(fresh, format)
+	}
+
+	response.HasSanctionedInteraction = h.sanctionScreening(sanctioned)
+	h.respondWithJSON(w, req, http.StatusOK, response)
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+)
+
+// applyETHPrice fetches the current native token price for eth_price=true and sets it on the
+// options, so Ether transfers are valued at it. It returns 0 when the price wasn't requested.
+func (h *Handler) applyETHPrice(r *http.Request, opts *analyzer.Options) (float64, error) {
+	if r.URL.Query().Get("eth_price") != "true" {
+		return 0, nil
+	}
+
+	price, err := h.etherscanClient.GetETHPrice()
+	if err != nil {
+		return 0, err
+	}
+	opts.ETHPriceUSD = price
+	return price, nil
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+
+	formatNDJSON = "ndjson"
+
+	// formatReport wraps the JSON result in a tamper-evident report (see report.go)
+	formatReport = "report"
+
+	// ndjsonContentType is the media type of newline-delimited JSON, also accepted in the Accept header
+	ndjsonContentType = "application/x-ndjson"
+
+	// csvFlushInterval is the number of CSV rows written between flushes to the client
+	csvFlushInterval = 100
+)
+
+// numberFormat describes how a locale writes numbers in CSV output. Locales using a decimal
+// comma also separate fields with semicolons, as spreadsheets in those locales expect.
+type numberFormat struct {
+	decimal   string
+	group     string // Thousands separator, empty for no grouping
+	delimiter rune   // CSV field separator
+}
+
+// numberFormats maps the supported CSV locales to their number formatting
+var numberFormats = map[string]numberFormat{
+	config.CSVLocaleEnglish:   {decimal: ".", delimiter: ','},
+	config.CSVLocaleEnglishUS: {decimal: ".", group: ",", delimiter: ','},
+	config.CSVLocaleGerman:    {decimal: ",", group: ".", delimiter: ';'},
+	config.CSVLocaleFrench:    {decimal: ",", group: " ", delimiter: ';'},
+}
+
+// csvCounterparty is the direction-agnostic view of a beneficiary or payer used for CSV export
+type csvCounterparty struct {
+	address      string
+	amount       float64
+	usdValue     float64
+	transactions []analyzer.TransactionDetails
+}
+
+// parseFormat validates the requested output format. Without a format parameter, an Accept
+// header asking for NDJSON selects it; otherwise the default is JSON.
+func parseFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+		if strings.Contains(r.Header.Get("Accept"), ndjsonContentType) {
+			return formatNDJSON, nil
+		}
+		return formatJSON, nil
+	case formatJSON, formatCSV, formatNDJSON, formatReport:
+		return format, nil
+	default:
+		return "", fmt.Errorf("format must be 'json', 'csv', 'ndjson' or 'report'")
+	}
+}
+
+// parseLocale returns the number formatting for CSV output, from the locale parameter or else
+// the configured default. JSON output always uses canonical numbers and ignores it.
+func parseLocale(r *http.Request, defaultLocale string) (numberFormat, error) {
+	locale := strings.ToLower(r.URL.Query().Get("locale"))
+	if locale == "" {
+		locale = defaultLocale
+	}
+	format, ok := numberFormats[locale]
+	if !ok {
+		return numberFormat{}, fmt.Errorf("locale must be 'en', 'en-us', 'de' or 'fr'")
+	}
+	return format, nil
+}
+
+// beneficiaryCSVRows adapts beneficiaries for CSV export
+func beneficiaryCSVRows(beneficiaries []analyzer.Beneficiary) []csvCounterparty {
+	rows := make([]csvCounterparty, len(beneficiaries))
+	for i, b := range beneficiaries {
+		rows[i] = csvCounterparty{address: b.Address, amount: b.Amount, usdValue: b.USDValue, transactions: b.Transactions}
+	}
+	return rows
+}
+
+// payerCSVRows adapts payers for CSV export
+func payerCSVRows(payers []analyzer.Payer) []csvCounterparty {
+	rows := make([]csvCounterparty, len(payers))
+	for i, p := range payers {
+		rows[i] = csvCounterparty{address: p.Address, amount: p.Amount, usdValue: p.USDValue, transactions: p.Transactions}
+	}
+	return rows
+}
+
+// streamCSV writes one CSV row per transaction directly to the response, flushing
+// periodically so memory stays flat and the client starts receiving data immediately
+func (h *Handler) streamCSV(w http.ResponseWriter, r *http.Request, filename, addressColumn string, counterparties []csvCounterparty, numbers numberFormat) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	cw := csv.NewWriter(w)
+	cw.Comma = numbers.delimiter
+	log := requestLogger(h.logger, r)
+
+	header := []string{addressColumn, "amount", "usd_value", "tx_amount", "date_time", "transaction_id"}
+	if err := cw.Write(header); err != nil {
+		log.Errorf("Error writing CSV header: %v", err)
+		return
+	}
+
+	rows := 0
+	for _, c := range counterparties {
+		for _, tx := range c.transactions {
+			record := []string{
+				c.address,
+				numbers.format(c.amount),
+				numbers.format(c.usdValue),
+				numbers.format(tx.TxAmount),
+				tx.DateTime,
+				tx.TransactionID,
+			}
+			if err := cw.Write(record); err != nil {
+				log.Errorf("Error writing CSV row: %v", err)
+				return
+			}
+
+			rows++
+			if rows%csvFlushInterval == 0 {
+				cw.Flush()
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Errorf("Error flushing CSV: %v", err)
+	}
+}
+
+// streamNDJSON writes one JSON object per line, flushing after each so consumers can process
+// entries as they arrive
+func streamNDJSON[T any](h *Handler, w http.ResponseWriter, r *http.Request, rows []T) {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	naming := h.fieldNaming(r)
+	for _, row := range rows {
+		line, err := marshalJSON(row, naming)
+		if err == nil {
+			_, err = w.Write(append(line, '\n'))
+		}
+		if err != nil {
+			requestLogger(h.logger, r).Errorf("Error writing NDJSON row: %v", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// format writes an amount without exponent notation, using the locale's decimal and thousands separators
+func (nf numberFormat) format(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if nf.decimal == "." && nf.group == "" {
+		return s
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(s, ".")
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && nf.group != "" && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(nf.group)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if hasFraction {
+		return sign + grouped.String() + nf.decimal + fraction
+	}
+	return sign + grouped.String()
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+)
+
+// dateLayout is the layout of the from_date and to_date parameters
+const dateLayout = "2006-01-02"
+
+// resultFilter is the server-side filtering and pagination of counterparty results, letting a
+// client ask for exactly the rows it shows. Whatever the order of the query parameters, the
+// steps always run in this order:
+//
+//  1. from_date/to_date keep each counterparty's transactions within the dates and recompute
+//     its totals from them, dropping counterparties left without transactions
+//  2. active_within drops counterparties whose latest remaining transaction is too old
+//  3. min_amount drops counterparties whose remaining total is below it
+//  4. the counterparties are sorted by sort and order
+//  5. top keeps the first N sorted counterparties as the listed rows
+//  6. MAX_COUNTERPARTIES and the transaction caps bound what is returned
+//
+// So top picks among the counterparties that matched every filter, and amounts are ranked
+// and compared to min_amount by their totals within the dates. Totals (total_usd_value and
+// the bucket, summary and breakdown figures) are computed after step 3, so top and the caps
+// only limit the rows listed, and sanctions screening runs before step 1 on every counterparty.
+type resultFilter struct {
+	from, to    time.Time // to is exclusive; zero bounds are open
+	activeSince time.Time
+	minAmount   float64
+	top         int // 0 means no limit
+}
+
+// parseResultFilter reads from_date and to_date (inclusive dates in loc), active_within,
+// min_amount and top
+func parseResultFilter(r *http.Request, loc *time.Location) (resultFilter, error) {
+	query := r.URL.Query()
+	var filter resultFilter
+
+	if value := query.Get("from_date"); value != "" {
+		from, err := time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return filter, fmt.Errorf("from_date must be a date like 2024-01-31")
+		}
+		filter.from = from
+	}
+	if value := query.Get("to_date"); value != "" {
+		to, err := time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return filter, fmt.Errorf("to_date must be a date like 2024-01-31")
+		}
+		filter.to = to.AddDate(0, 0, 1)
+	}
+	if !filter.from.IsZero() && !filter.to.IsZero() && !filter.from.Before(filter.to) {
+		return filter, fmt.Errorf("from_date must not be after to_date")
+	}
+
+	activeSince, err := parseActiveWithin(r)
+	if err != nil {
+		return filter, err
+	}
+	filter.activeSince = activeSince
+
+	if value := query.Get("min_amount"); value != "" {
+		minAmount, err := strconv.ParseFloat(value, 64)
+		if err != nil || minAmount < 0 {
+			return filter, fmt.Errorf("min_amount must be a non-negative number")
+		}
+		filter.minAmount = minAmount
+	}
+
+	if value := query.Get("top"); value != "" {
+		top, err := strconv.Atoi(value)
+		if err != nil || top < 1 {
+			return filter, fmt.Errorf("top must be a positive integer")
+		}
+		filter.top = top
+	}
+
+	return filter, nil
+}
+
+// beneficiaries applies the filtering steps (1-3) to the beneficiaries
+func (f resultFilter) beneficiaries(beneficiaries []analyzer.Beneficiary) []analyzer.Beneficiary {
+	if !f.from.IsZero() || !f.to.IsZero() {
+		beneficiaries = analyzer.FilterBeneficiariesByDate(beneficiaries, f.from, f.to)
+	}
+	if !f.activeSince.IsZero() {
+		beneficiaries = analyzer.FilterActiveBeneficiaries(beneficiaries, f.activeSince)
+	}
+	if f.minAmount > 0 {
+		beneficiaries = analyzer.FilterBeneficiariesByAmount(beneficiaries, f.minAmount)
+	}
+	return beneficiaries
+}
+
+// payers applies the filtering steps (1-3) to the payers
+func (f resultFilter) payers(payers []analyzer.Payer) []analyzer.Payer {
+	if !f.from.IsZero() || !f.to.IsZero() {
+		payers = analyzer.FilterPayersByDate(payers, f.from, f.to)
+	}
+	if !f.activeSince.IsZero() {
+		payers = analyzer.FilterActivePayers(payers, f.activeSince)
+	}
+	if f.minAmount > 0 {
+		payers = analyzer.FilterPayersByAmount(payers, f.minAmount)
+	}
+	return payers
+}
+
+// limit returns how many of n sorted counterparties top keeps (step 5)
+func (f resultFilter) limit(n int) int {
+	if f.top > 0 && f.top < n {
+		return f.top
+	}
+	return n
+}
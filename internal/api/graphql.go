@@ -0,0 +1,439 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+const (
+	// graphQLMaxDepth bounds query nesting so a single request can't ask for unbounded work
+	graphQLMaxDepth = 5
+
+	// graphQLMaxParallelism bounds how many root fields of a query are analyzed concurrently,
+	// as batchWorkers does for the batch endpoints
+	graphQLMaxParallelism = batchWorkers
+)
+
+// graphQLSchema exposes the beneficiary and payer analyses. Several addresses can be analyzed
+// in one request by aliasing the query fields; the lists are nullable so a failed analysis only
+// nulls its own field.
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	# Counterparties the address sent funds to, largest first
+	beneficiaries(address: String!, minAmount: Float, top: Int): [Counterparty!]
+	# Counterparties the address received funds from, largest first
+	payers(address: String!, minAmount: Float, top: Int): [Counterparty!]
+}
+
+type Counterparty {
+	address: String!
+	amount: Float!
+	txCount: Int!
+	avgAmount: Float!
+	maxAmount: Float!
+	label: String
+	usdValue: Float!
+	isContract: Boolean
+	sanctioned: Boolean!
+	transactions: [Transaction!]!
+}
+
+type Transaction {
+	hash: String!
+	amount: Float!
+	dateTime: String!
+	method: String
+	callType: String
+	kind: String
+	usdValue: Float!
+}
+`
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"`
+}
+
+// newGraphQLSchema parses the GraphQL schema with resolvers backed by the handler's analyzers
+func newGraphQLSchema(h *Handler) *graphql.Schema {
+	return graphql.MustParseSchema(graphQLSchema, &graphQLResolver{handler: h}, graphql.MaxDepth(graphQLMaxDepth), graphql.MaxParallelism(graphQLMaxParallelism))
+}
+
+// HandleGraphQL handles the /graphql endpoint. Query errors, including failed analyses, are
+// reported in the response's errors array as GraphQL requires.
+func (h *Handler) HandleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	if err := decodeJSONBody(w, r, h.config.MaxBodyBytes, &req); err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "query is required")
+		return
+	}
+	// Each root field is an analysis, so aliases are capped like the addresses of a batch
+	if fields := countRootFields(req.Query); fields > maxBatchSize {
+		h.respondWithError(w, r, http.StatusBadRequest, fmt.Sprintf("query selects %d root fields, exceeding the maximum of %d", fields, maxBatchSize))
+		return
+	}
+
+	response := h.graphqlSchema.Exec(r.Context(), req.Query, req.OperationName, req.Variables)
+	for _, err := range response.Errors {
+		requestLogger(h.logger, r).Warnf("GraphQL error: %v", err)
+	}
+	// Field names are the ones the query asked for, so they are never renamed
+	h.writeJSON(w, r, http.StatusOK, response, config.FieldNamingSnakeCase)
+}
+
+// graphQLResolver resolves the GraphQL query fields
+type graphQLResolver struct {
+	handler *Handler
+}
+
+// counterpartyArgs are the arguments of the beneficiaries and payers queries
+type counterpartyArgs struct {
+	Address   string
+	MinAmount *float64
+	Top       *int32
+}
+
+// valid checks the arguments, returning an error for the GraphQL response when they are unusable
+func (args counterpartyArgs) valid() error {
+	if !etherscan.IsValidAddress(args.Address) {
+		return errInvalidGraphQLAddress
+	}
+	if args.Top != nil && *args.Top < 1 {
+		return errInvalidGraphQLTop
+	}
+	return nil
+}
+
+// Argument errors reported in the GraphQL response
+var (
+	errInvalidGraphQLAddress = errors.New("address must be an Ethereum address")
+	errInvalidGraphQLTop     = errors.New("top must be a positive integer")
+)
+
+// Beneficiaries resolves the beneficiaries query
+func (res *graphQLResolver) Beneficiaries(ctx context.Context, args counterpartyArgs) (*[]*counterpartyResolver, error) {
+	if err := args.valid(); err != nil {
+		return nil, err
+	}
+
+	h := res.handler
+	beneficiaries, err := h.beneficiaryAnalyzer.AnalyzeBeneficiary(args.Address, analyzer.Options{Location: time.UTC})
+	if err != nil {
+		return nil, err
+	}
+	beneficiaries, _ = analyzer.CapBeneficiaries(beneficiaries, h.config.MaxCounterparties, h.config.MaxTxPerCounterparty, analyzer.KeepLargest)
+
+	resolvers := make([]*counterpartyResolver, 0, len(beneficiaries))
+	for _, b := range beneficiaries {
+		resolvers = append(resolvers, &counterpartyResolver{
+			address:      b.Address,
+			amount:       b.Amount,
+			txCount:      b.TxCount,
+			avgAmount:    b.AvgAmount,
+			maxAmount:    b.MaxAmount,
+			label:        b.Label,
+			usdValue:     b.USDValue,
+			isContract:   b.IsContract,
+			sanctioned:   b.Sanctioned,
+			transactions: b.Transactions,
+		})
+	}
+	resolvers = filterCounterparties(resolvers, args)
+	return &resolvers, nil
+}
+
+// Payers resolves the payers query
+func (res *graphQLResolver) Payers(ctx context.Context, args counterpartyArgs) (*[]*counterpartyResolver, error) {
+	if err := args.valid(); err != nil {
+		return nil, err
+	}
+
+	h := res.handler
+	payers, err := h.payerAnalyzer.AnalyzePayer(args.Address, analyzer.Options{Location: time.UTC})
+	if err != nil {
+		return nil, err
+	}
+	payers, _ = analyzer.CapPayers(payers, h.config.MaxCounterparties, h.config.MaxTxPerCounterparty, analyzer.KeepLargest)
+
+	resolvers := make([]*counterpartyResolver, 0, len(payers))
+	for _, p := range payers {
+		resolvers = append(resolvers, &counterpartyResolver{
+			address:      p.Address,
+			amount:       p.Amount,
+			txCount:      p.TxCount,
+			avgAmount:    p.AvgAmount,
+			maxAmount:    p.MaxAmount,
+			label:        p.Label,
+			usdValue:     p.USDValue,
+			isContract:   p.IsContract,
+			sanctioned:   p.Sanctioned,
+			transactions: p.Transactions,
+		})
+	}
+	resolvers = filterCounterparties(resolvers, args)
+	return &resolvers, nil
+}
+
+// filterCounterparties drops counterparties below minAmount and keeps the top largest, relying
+// on the analyzers returning them largest first
+func filterCounterparties(counterparties []*counterpartyResolver, args counterpartyArgs) []*counterpartyResolver {
+	if args.MinAmount != nil {
+		kept := counterparties[:0]
+		for _, c := range counterparties {
+			if c.amount >= *args.MinAmount {
+				kept = append(kept, c)
+			}
+		}
+		counterparties = kept
+	}
+	if args.Top != nil && int(*args.Top) < len(counterparties) {
+		counterparties = counterparties[:*args.Top]
+	}
+	return counterparties
+}
+
+// counterpartyResolver resolves the fields of a beneficiary or payer
+type counterpartyResolver struct {
+	address      string
+	amount       float64
+	txCount      int
+	avgAmount    float64
+	maxAmount    float64
+	label        string
+	usdValue     float64
+	isContract   *bool
+	sanctioned   bool
+	transactions []analyzer.TransactionDetails
+}
+
+func (c *counterpartyResolver) Address() string    { return c.address }
+func (c *counterpartyResolver) Amount() float64    { return c.amount }
+func (c *counterpartyResolver) TxCount() int32     { return int32(c.txCount) }
+func (c *counterpartyResolver) AvgAmount() float64 { return c.avgAmount }
+func (c *counterpartyResolver) MaxAmount() float64 { return c.maxAmount }
+func (c *counterpartyResolver) Label() *string     { return optionalString(c.label) }
+func (c *counterpartyResolver) UsdValue() float64  { return c.usdValue }
+func (c *counterpartyResolver) IsContract() *bool  { return c.isContract }
+func (c *counterpartyResolver) Sanctioned() bool   { return c.sanctioned }
+
+// Transactions resolves the counterparty's transactions
+func (c *counterpartyResolver) Transactions() []*transactionResolver {
+	resolvers := make([]*transactionResolver, len(c.transactions))
+	for i := range c.transactions {
+		resolvers[i] = &transactionResolver{tx: c.transactions[i]}
+	}
+	return resolvers
+}
+
+// transactionResolver resolves the fields of a counterparty's transaction
+type transactionResolver struct {
+	tx analyzer.TransactionDetails
+}
+
+func (t *transactionResolver) Hash() string      { return t.tx.TransactionID }
+func (t *transactionResolver) Amount() float64   { return t.tx.TxAmount }
+func (t *transactionResolver) DateTime() string  { return t.tx.DateTime }
+func (t *transactionResolver) Method() *string   { return optionalString(t.tx.Method) }
+func (t *transactionResolver) CallType() *string { return optionalString(t.tx.CallType) }
+func (t *transactionResolver) Kind() *string     { return optionalString(t.tx.Kind) }
+func (t *transactionResolver) UsdValue() float64 { return t.tx.USDValue }
+
+// optionalString returns nil for an empty string so it resolves to GraphQL null
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// countRootFields counts the fields an operation of the query selects at its root, every alias
+// counting as a field and fragments counting the fields they select. With several operations
+// it returns the largest count. It only scans the query's tokens: a malformed query gets some
+// count and is then rejected by the schema.
+func countRootFields(query string) int {
+	tokens := graphQLTokens(query)
+	counter := rootFieldCounter{fragments: make(map[string][]string), expanding: make(map[string]bool)}
+
+	var operations [][]string
+	for i := 0; i < len(tokens); {
+		start, end := selectionSet(tokens, i)
+		if start < 0 {
+			break
+		}
+		if tokens[i] == "fragment" && i+1 < len(tokens) {
+			counter.fragments[tokens[i+1]] = tokens[start : end+1]
+		} else {
+			operations = append(operations, tokens[start:end+1])
+		}
+		i = end + 1
+	}
+
+	most := 0
+	for _, operation := range operations {
+		if n := counter.count(operation); n > most {
+			most = n
+		}
+	}
+	return most
+}
+
+// rootFieldCounter counts the fields of selection sets, expanding the query's named fragments
+type rootFieldCounter struct {
+	fragments map[string][]string
+	expanding map[string]bool
+}
+
+// count returns the number of fields the selection set selects directly
+func (c *rootFieldCounter) count(set []string) int {
+	fields := 0
+	depth, parens := 0, 0
+	for i := 0; i < len(set); i++ {
+		token := set[i]
+		switch {
+		case token == "(":
+			parens++
+		case token == ")":
+			parens--
+		case parens > 0:
+			// Arguments and their values
+		case token == "{":
+			depth++
+		case token == "}":
+			depth--
+		case depth != 1:
+			// Selections of a field's sub-selection
+		case token == "@":
+			i++ // the directive's name
+		case token == "..." && i+1 < len(set) && set[i+1] != "on" && set[i+1] != "@" && set[i+1] != "{":
+			i++
+			name := set[i]
+			// A fragment spreading itself is rejected by the schema; don't recurse forever
+			if !c.expanding[name] {
+				c.expanding[name] = true
+				fields += c.count(c.fragments[name])
+				delete(c.expanding, name)
+			}
+		case token == "...":
+			start, end := selectionSet(set, i+1)
+			if start < 0 {
+				return fields
+			}
+			fields += c.count(set[start : end+1])
+			i = end
+		case i+1 < len(set) && set[i+1] == ":":
+			fields++
+			i += 2 // the alias's field
+		default:
+			fields++
+		}
+	}
+	return fields
+}
+
+// selectionSet finds the first selection set from tokens[from], outside any arguments, returning
+// the indexes of its braces, or -1 when there is none
+func selectionSet(tokens []string, from int) (int, int) {
+	parens := 0
+	for i := from; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "(":
+			parens++
+		case ")":
+			parens--
+		case "{":
+			if parens > 0 {
+				continue
+			}
+			depth := 0
+			for j := i; j < len(tokens); j++ {
+				switch tokens[j] {
+				case "{":
+					depth++
+				case "}":
+					depth--
+					if depth == 0 {
+						return i, j
+					}
+				}
+			}
+			return i, len(tokens) - 1
+		}
+	}
+	return -1, -1
+}
+
+// graphQLTokens splits a query into names and punctuators, dropping whitespace, commas and
+// comments. Strings become a single `"` token and numbers are split up, which is enough to count
+// fields since both only appear in arguments.
+func graphQLTokens(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		switch ch := query[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == ',':
+			i++
+		case ch == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], `"""`):
+			i += 3
+			for i < len(query) && !strings.HasPrefix(query[i:], `"""`) {
+				if strings.HasPrefix(query[i:], `\"""`) {
+					i += 4
+				} else {
+					i++
+				}
+			}
+			i += 3
+			tokens = append(tokens, `"`)
+		case ch == '"':
+			i++
+			for i < len(query) && query[i] != '"' && query[i] != '\n' {
+				if query[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, `"`)
+		case strings.HasPrefix(query[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case isGraphQLNameChar(ch):
+			start := i
+			for i < len(query) && isGraphQLNameChar(query[i]) {
+				i++
+			}
+			tokens = append(tokens, query[start:i])
+		default:
+			tokens = append(tokens, query[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+// isGraphQLNameChar reports whether ch can be part of a GraphQL name
+func isGraphQLNameChar(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
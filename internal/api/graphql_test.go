@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCountRootFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"fields", `{ beneficiaries(address: "0x1") { address } payers(address: "0x1") { address } }`, 2},
+		{"aliases", `{ a: beneficiaries(address: "0x1") { address } b: beneficiaries(address: "0x2") { address } c: payers(address: "0x3") { amount } }`, 3},
+		{"nested fields", `{ beneficiaries(address: "0x1") { address transactions { hash amount } } }`, 1},
+		{"named operation", `query Flows($a: String!, $min: Float = 1.5) { beneficiaries(address: $a, minAmount: $min) @include(if: true) { address } }`, 1},
+		{"fragment spread", `query { ...Both c: payers(address: "0x3") { address } } fragment Both on Query { a: beneficiaries(address: "0x1") { address } b: payers(address: "0x2") { address } }`, 3},
+		{"inline fragment", `{ ... on Query { a: beneficiaries(address: "0x1") { address } b: payers(address: "0x2") { address } } }`, 2},
+		{"recursive fragment", `{ ...Loop } fragment Loop on Query { a: payers(address: "0x1") { address } ...Loop }`, 1},
+		{"strings and comments", `{ # b: payers(address: "0x2") { address }
+			a: payers(address: "} { x: y") { address } }`, 1},
+		{"largest operation", `query One { a: payers(address: "0x1") { address } } query Two { a: payers(address: "0x1") { address } b: payers(address: "0x2") { address } }`, 2},
+	}
+	for _, tt := range tests {
+		if got := countRootFields(tt.query); got != tt.want {
+			t.Errorf("%s: countRootFields = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGraphQLRejectsTooManyAliases(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	var fields []string
+	for i := 0; i <= maxBatchSize; i++ {
+		fields = append(fields, fmt.Sprintf(`a%d: beneficiaries(address: \"%s\") { address }`, i, testAddress))
+	}
+	body := `{"query": "{ ` + strings.Join(fields, " ") + ` }"}`
+
+	rec := httptest.NewRecorder()
+	h.HandleGraphQL(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("%d aliases: status %d, want 400", len(fields), rec.Code)
+	}
+}
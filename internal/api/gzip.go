@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// gzipMinSize is the response size below which compression isn't worth the overhead.
+// Small responses such as the plain-text /health "OK" are sent as is.
+const gzipMinSize = 1024
+
+// gzipMiddleware compresses responses of at least gzipMinSize bytes for clients that send
+// Accept-Encoding: gzip
+func (r *Router) gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if req.Method == http.MethodHead || !acceptsGzip(req) || isWebSocketUpgrade(req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		defer gw.Close()
+		next.ServeHTTP(gw, req)
+	})
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip
+func acceptsGzip(req *http.Request) bool {
+	for _, encoding := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// isWebSocketUpgrade reports whether the request asks to switch to the WebSocket protocol,
+// whose connection is hijacked and must not be wrapped
+func isWebSocketUpgrade(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
+
+// gzipResponseWriter buffers the start of a response until it knows whether it reaches
+// gzipMinSize, then either compresses the rest or writes the buffered bytes unchanged
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         bytes.Buffer
+	gz          *gzip.Writer
+}
+
+// WriteHeader records the status code; it is sent once the compression decision is made
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf.Write(p)
+	if w.buf.Len() >= gzipMinSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what has been written so far, committing to compression so streamed
+// responses such as CSV exports reach the client without waiting for the whole body
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close writes any buffered bytes and finishes the gzip stream
+func (w *gzipResponseWriter) Close() error {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
+
+// decide writes the header, compressing when asked to and when the response allows it,
+// and then sends the buffered bytes
+func (w *gzipResponseWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+
+	// Leave alone responses that are already encoded or must not have a body
+	if header.Get("Content-Encoding") != "" || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		compress = false
+	}
+
+	if compress {
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", http.DetectContentType(w.buf.Bytes()))
+		}
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+
+		// The compressed representation differs byte-wise, so a strong ETag must not match it
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
@@ -1,158 +1,670 @@
 package api
 
 import (
-	"encoding/json"
+	"errors"
+	"math/big"
 	"net/http"
+	"sync"
+	"time"
 
+	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
 	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
 )
 
 // Handler handles API requests
 type Handler struct {
+	config              *config.Config
+	etherscanClient     *etherscan.Client
 	beneficiaryAnalyzer *analyzer.BeneficiaryAnalyzer
 	payerAnalyzer       *analyzer.PayerAnalyzer
+	flowAnalyzer        *analyzer.FlowAnalyzer
 	logger              logger.Logger
+	graphqlSchema       *graphql.Schema
+
+	// ready holds the last /ready check, reused for readinessTTL
+	ready readinessCache
+}
+
+// readinessTTL is how long a /ready result is reused, so probes polling it every few seconds
+// don't each cost an Etherscan request
+const readinessTTL = 30 * time.Second
+
+// readinessCache is the result of the last readiness check with the time it was made
+type readinessCache struct {
+	mu        sync.Mutex
+	err       error
+	checkedAt time.Time
 }
 
 // NewHandler creates a new API handler
-func NewHandler(beneficiaryAnalyzer *analyzer.BeneficiaryAnalyzer, payerAnalyzer *analyzer.PayerAnalyzer, logger logger.Logger) *Handler {
-	return &Handler{
+func NewHandler(config *config.Config, etherscanClient *etherscan.Client, beneficiaryAnalyzer *analyzer.BeneficiaryAnalyzer, payerAnalyzer *analyzer.PayerAnalyzer, flowAnalyzer *analyzer.FlowAnalyzer, logger logger.Logger) *Handler {
+	h := &Handler{
+		config:              config,
+		etherscanClient:     etherscanClient,
 		beneficiaryAnalyzer: beneficiaryAnalyzer,
 		payerAnalyzer:       payerAnalyzer,
+		flowAnalyzer:        flowAnalyzer,
 		logger:              logger,
 	}
+	h.graphqlSchema = newGraphQLSchema(h)
+	return h
 }
 
 // BeneficiaryData represents a single beneficiary entry in the response
 type BeneficiaryData struct {
 	BeneficiaryAddress string                  `json:"beneficiary_address"`
-	Amount             float64                 `json:"amount"`
+	Amount             Decimal                 `json:"amount"`
+	TxCount            int                     `json:"tx_count"`
+	AvgAmount          float64                 `json:"avg_amount"`
+	MaxAmount          float64                 `json:"max_amount"`
 	Transactions       []TransactionDetails    `json:"transactions"`
+	Label              string                  `json:"label,omitempty"`
+	USDValue           float64                 `json:"usd_value,omitempty"`
+	IsContract         *bool                   `json:"is_contract,omitempty"`
+	Flags              []string                `json:"flags,omitempty"`
+	Score              float64                 `json:"score,omitempty"`
+	Chains             []analyzer.ChainAmount  `json:"chains,omitempty"`
+	Sanctioned         bool                    `json:"sanctioned,omitempty"`
+	PrecisionWarning   bool                    `json:"precision_warning,omitempty"` // Amount exceeded PRECISION_LIMIT and is written exactly
 }
 
 // PayerData represents a single payer entry in the response
 type PayerData struct {
 	PayerAddress     string               `json:"payer_address"`
-	Amount           float64              `json:"amount"`
+	Amount           Decimal              `json:"amount"`
+	TxCount          int                  `json:"tx_count"`
+	AvgAmount        float64              `json:"avg_amount"`
+	MaxAmount        float64              `json:"max_amount"`
 	Transactions     []TransactionDetails `json:"transactions"`
+	Label            string               `json:"label,omitempty"`
+	USDValue         float64              `json:"usd_value,omitempty"`
+	IsContract       *bool                `json:"is_contract,omitempty"`
+	Flags            []string             `json:"flags,omitempty"`
+	Score            float64              `json:"score,omitempty"`
+	Sanctioned       bool                 `json:"sanctioned,omitempty"`
+	PrecisionWarning bool                 `json:"precision_warning,omitempty"` // Amount exceeded PRECISION_LIMIT and is written exactly
 }
 
 // TransactionDetails represents transaction details in the response
 type TransactionDetails struct {
-	TxAmount      float64 `json:"tx_amount"`
+	TxAmount      Decimal `json:"tx_amount"`
 	DateTime      string  `json:"date_time"`
 	TransactionID string  `json:"transaction_id"`
+	ChainID       int     `json:"chain_id,omitempty"`
+	CallType      string  `json:"call_type,omitempty"`
+	Method        string  `json:"method,omitempty"`
+	Kind          string  `json:"kind,omitempty"`
+	USDValue      float64 `json:"usd_value,omitempty"`
+	ViaProxy      string  `json:"via_proxy,omitempty"`
+
+	// PrecisionWarning is set when the amount exceeded PRECISION_LIMIT and is written exactly
+	PrecisionWarning bool `json:"precision_warning,omitempty"`
 }
 
 // BeneficiaryResponse represents the response format for the beneficiary endpoint
 type BeneficiaryResponse struct {
-	Message string          `json:"message"`
-	Data    []BeneficiaryData `json:"data"`
+	Message        string            `json:"message"`
+	RequestID      string            `json:"request_id,omitempty"`
+	Truncated      bool              `json:"truncated,omitempty"`
+	TotalAvailable int               `json:"total_available,omitempty"`
+	Data           []BeneficiaryData `json:"data"`
+
+	// ETHPriceUSD and TotalUSDValue are set with eth_price=true: the current price Ether
+	// transfers were valued at and the USD value of every beneficiary before the result caps
+	ETHPriceUSD   float64 `json:"eth_price_usd,omitempty"`
+	TotalUSDValue float64 `json:"total_usd_value,omitempty"`
+
+	// HasSanctionedInteraction is set when SANCTIONS_LIST_PATH is configured
+	HasSanctionedInteraction *bool `json:"has_sanctioned_interaction,omitempty"`
 }
 
 // PayerResponse represents the response format for the payer endpoint
 type PayerResponse struct {
-	Message string     `json:"message"`
-	Data    []PayerData `json:"data"`
+	Message        string      `json:"message"`
+	RequestID      string      `json:"request_id,omitempty"`
+	Truncated      bool        `json:"truncated,omitempty"`
+	TotalAvailable int         `json:"total_available,omitempty"`
+	Data           []PayerData `json:"data"`
+
+	// ETHPriceUSD and TotalUSDValue are set with eth_price=true: the current price Ether
+	// transfers were valued at and the USD value of every payer before the result caps
+	ETHPriceUSD   float64 `json:"eth_price_usd,omitempty"`
+	TotalUSDValue float64 `json:"total_usd_value,omitempty"`
+
+	// HasSanctionedInteraction is set when SANCTIONS_LIST_PATH is configured
+	HasSanctionedInteraction *bool `json:"has_sanctioned_interaction,omitempty"`
 }
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Message string `json:"message"`
-	Error   string `json:"error"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	Error     string `json:"error"`
 }
 
 // HandleBeneficiary handles the /beneficiary endpoint
 func (h *Handler) HandleBeneficiary(w http.ResponseWriter, r *http.Request) {
 	address := r.URL.Query().Get("address")
 	if address == "" {
-		h.respondWithError(w, http.StatusBadRequest, "address parameter is required")
+		h.respondWithError(w, r, http.StatusBadRequest, "address parameter is required")
+		return
+	}
+	if !etherscan.IsValidAddress(address) {
+		h.respondWithError(w, r, http.StatusBadRequest, "address must be an Ethereum address")
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	h.logger.Infof("Analyzing beneficiaries for address: %s", address)
+	format, err := parseFormat(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	beneficiaries, err := h.beneficiaryAnalyzer.AnalyzeBeneficiary(address)
+	sortBy, err := parseSort(r)
 	if err != nil {
-		h.logger.Errorf("Error analyzing beneficiary: %v", err)
-		h.respondWithError(w, http.StatusInternalServerError, err.Error())
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Convert the analyzer.Beneficiary objects to BeneficiaryData objects
-	responseData := make([]BeneficiaryData, len(beneficiaries))
-	for i, b := range beneficiaries {
-		txDetails := make([]TransactionDetails, len(b.Transactions))
-		for j, tx := range b.Transactions {
-			txDetails[j] = TransactionDetails{
-				TxAmount:      tx.TxAmount,
-				DateTime:      tx.DateTime,
-				TransactionID: tx.TransactionID,
+	numbers, err := parseLocale(r, h.config.CSVLocale)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	txCap, err := parseTransactionCap(r, h.config.MaxTxPerCounterparty)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, err := parseResultFilter(r, opts.Location)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	chains, err := h.parseChains(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Bucketing tells contracts from wallets, so it needs contract detection
+	bucket := r.URL.Query().Get("bucket") == "true"
+	if bucket && format != formatJSON {
+		h.respondWithError(w, r, http.StatusBadRequest, "bucket=true is only supported with format=json")
+		return
+	}
+	if bucket {
+		opts.DetectContracts = true
+	}
+
+	summary := r.URL.Query().Get("summary")
+	if summary != "" && summary != summaryByCategory {
+		h.respondWithError(w, r, http.StatusBadRequest, "summary must be "+summaryByCategory)
+		return
+	}
+	if summary != "" && (format != formatJSON || bucket) {
+		h.respondWithError(w, r, http.StatusBadRequest, "summary=by_category is only supported with format=json and without bucket=true")
+		return
+	}
+
+	breakdown := r.URL.Query().Get("breakdown")
+	if breakdown != "" && breakdown != breakdownByMethod {
+		h.respondWithError(w, r, http.StatusBadRequest, "breakdown must be "+breakdownByMethod)
+		return
+	}
+	if breakdown != "" && (format != formatJSON || bucket || summary != "") {
+		h.respondWithError(w, r, http.StatusBadRequest, "breakdown=by_method is only supported with format=json and without bucket=true or summary")
+		return
+	}
+
+	// Both price every native transfer as Ether, which is wrong on chains with another native token
+	if len(chains) > 0 && r.URL.Query().Get("eth_price") == "true" {
+		h.respondWithError(w, r, http.StatusBadRequest, "eth_price=true cannot be combined with chains")
+		return
+	}
+	if len(chains) > 0 && opts.USD {
+		h.respondWithError(w, r, http.StatusBadRequest, "usd=true cannot be combined with chains")
+		return
+	}
+	if len(chains) > 0 && r.URL.Query().Get("self_custody") == "true" {
+		h.respondWithError(w, r, http.StatusBadRequest, "self_custody=true cannot be combined with chains")
+		return
+	}
+	ethPrice, err := h.applyETHPrice(r, &opts)
+	if err != nil {
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Analyzing beneficiaries for address: %s", address)
+
+	var beneficiaries []analyzer.Beneficiary
+	if len(chains) > 0 {
+		beneficiaries, err = h.analyzeBeneficiaryChains(address, opts, chains)
+	} else {
+		beneficiaries, err = h.beneficiaryAnalyzer.AnalyzeBeneficiary(address, opts)
+	}
+	if err != nil {
+		log.Errorf("Error analyzing beneficiary: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	// Optionally collapse entries sharing a hash, e.g. a swap seen as both a normal and a token transfer
+	if r.URL.Query().Get("dedupe") == "true" {
+		analyzer.DedupeBeneficiaries(beneficiaries)
+	}
+
+	// Screen every counterparty, including those the filters leave out of the response
+	sanctioned := analyzer.HasSanctionedBeneficiary(beneficiaries)
+
+	// Filter server-side before sorting and limiting, in the order resultFilter documents
+	beneficiaries = filter.beneficiaries(beneficiaries)
+
+	// Optionally flag counterparties showing structuring patterns
+	if r.URL.Query().Get("flags") == "true" {
+		thresholds := h.structuringThresholds()
+		movements := h.exchangeMovementThresholds()
+		for i := range beneficiaries {
+			beneficiaries[i].Flags = analyzer.DetectStructuring(beneficiaries[i].Transactions, thresholds)
+			if analyzer.IsExchangeInternal(address, beneficiaries[i].Address, h.config.ExchangeAddresses, beneficiaries[i].Transactions, movements) {
+				beneficiaries[i].Flags = append(beneficiaries[i].Flags, analyzer.FlagExchangeInternal)
 			}
 		}
-		
-		responseData[i] = BeneficiaryData{
-			BeneficiaryAddress: b.Address,
-			Amount:             b.Amount,
-			Transactions:       txDetails,
+	}
+
+	// Optionally flag beneficiaries that look like fresh addresses of the same entity
+	if r.URL.Query().Get("self_custody") == "true" {
+		if err := h.beneficiaryAnalyzer.DetectSelfCustody(address, beneficiaries, h.selfCustodyThresholds(), opts); err != nil {
+			log.Errorf("Error detecting self-custody transfers: %v", err)
+			h.respondWithAnalysisError(w, r, err)
+			return
 		}
 	}
 
-	h.respondWithJSON(w, http.StatusOK, BeneficiaryResponse{
-		Message: "success",
-		Data:    responseData,
-	})
+	// Order the results as requested, scoring counterparties first when ranking by significance
+	if sortBy.by == analyzer.SortScore {
+		analyzer.ScoreBeneficiaries(beneficiaries, h.scoreWeights())
+	}
+	analyzer.SortBeneficiaries(beneficiaries, sortBy.by, sortBy.ascending)
+
+	// Keep the top N rows, remembering how many matched for total_available; totals still
+	// cover every matching beneficiary
+	matched := len(beneficiaries)
+	listed := beneficiaries[:filter.limit(matched)]
+
+	if format == formatCSV {
+		h.streamCSV(w, r, "beneficiaries-"+address+".csv", "beneficiary_address", beneficiaryCSVRows(listed), numbers)
+		return
+	}
+
+	if bucket {
+		h.respondWithBuckets(w, r, beneficiaries, listed, sanctioned, txCap)
+		return
+	}
+
+	if summary == summaryByCategory {
+		h.respondWithCategorySummary(w, r, beneficiaries, sanctioned)
+		return
+	}
+
+	if breakdown == breakdownByMethod {
+		h.respondWithMethodBreakdown(w, r, beneficiaries, sanctioned)
+		return
+	}
+
+	// Cap the response size, keeping the most significant entries
+	response := BeneficiaryResponse{
+		Message:                  "success",
+		RequestID:                requestIDFromContext(r.Context()),
+		HasSanctionedInteraction: h.sanctionScreening(sanctioned),
+	}
+	if ethPrice > 0 {
+		response.ETHPriceUSD = ethPrice
+		for _, b := range beneficiaries {
+			response.TotalUSDValue += b.USDValue
+		}
+	}
+	listed, response.Truncated = analyzer.CapBeneficiaries(listed, h.config.MaxCounterparties, txCap.max, txCap.keep)
+	if response.Truncated || len(listed) < matched {
+		response.TotalAvailable = matched
+	}
+	response.Data = toBeneficiaryData(listed, h.amountFormat(r.URL.Query().Get("precise") == "true"))
+
+	if format == formatNDJSON {
+		streamNDJSON(h, w, r, response.Data)
+		return
+	}
+
+	if format == formatReport {
+		h.respondWithReport(w, r, "beneficiary", address, response)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // HandlePayer handles the /payer endpoint
 func (h *Handler) HandlePayer(w http.ResponseWriter, r *http.Request) {
 	address := r.URL.Query().Get("address")
 	if address == "" {
-		h.respondWithError(w, http.StatusBadRequest, "address parameter is required")
+		h.respondWithError(w, r, http.StatusBadRequest, "address parameter is required")
+		return
+	}
+	if !etherscan.IsValidAddress(address) {
+		h.respondWithError(w, r, http.StatusBadRequest, "address must be an Ethereum address")
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	h.logger.Infof("Analyzing payers for address: %s", address)
+	format, err := parseFormat(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	payers, err := h.payerAnalyzer.AnalyzePayer(address)
+	sortBy, err := parseSort(r)
 	if err != nil {
-		h.logger.Errorf("Error analyzing payer: %v", err)
-		h.respondWithError(w, http.StatusInternalServerError, err.Error())
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Convert the analyzer.Payer objects to PayerData objects
-	responseData := make([]PayerData, len(payers))
-	for i, p := range payers {
-		txDetails := make([]TransactionDetails, len(p.Transactions))
-		for j, tx := range p.Transactions {
-			txDetails[j] = TransactionDetails{
-				TxAmount:      tx.TxAmount,
-				DateTime:      tx.DateTime,
-				TransactionID: tx.TransactionID,
+	numbers, err := parseLocale(r, h.config.CSVLocale)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	txCap, err := parseTransactionCap(r, h.config.MaxTxPerCounterparty)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, err := parseResultFilter(r, opts.Location)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ethPrice, err := h.applyETHPrice(r, &opts)
+	if err != nil {
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Analyzing payers for address: %s", address)
+
+	payers, err := h.payerAnalyzer.AnalyzePayer(address, opts)
+	if err != nil {
+		log.Errorf("Error analyzing payer: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	// Optionally collapse entries sharing a hash, e.g. a swap seen as both a normal and a token transfer
+	if r.URL.Query().Get("dedupe") == "true" {
+		analyzer.DedupePayers(payers)
+	}
+
+	// Screen every counterparty, including those the filters leave out of the response
+	sanctioned := analyzer.HasSanctionedPayer(payers)
+
+	// Filter server-side before sorting and limiting, in the order resultFilter documents
+	payers = filter.payers(payers)
+
+	// Optionally flag counterparties showing structuring patterns
+	if r.URL.Query().Get("flags") == "true" {
+		thresholds := h.structuringThresholds()
+		movements := h.exchangeMovementThresholds()
+		for i := range payers {
+			payers[i].Flags = analyzer.DetectStructuring(payers[i].Transactions, thresholds)
+			if analyzer.IsExchangeInternal(address, payers[i].Address, h.config.ExchangeAddresses, payers[i].Transactions, movements) {
+				payers[i].Flags = append(payers[i].Flags, analyzer.FlagExchangeInternal)
 			}
 		}
-		
+	}
+
+	// Order the results as requested, scoring counterparties first when ranking by significance
+	if sortBy.by == analyzer.SortScore {
+		analyzer.ScorePayers(payers, h.scoreWeights())
+	}
+	analyzer.SortPayers(payers, sortBy.by, sortBy.ascending)
+
+	// Keep the top N rows, remembering how many matched for total_available; totals still
+	// cover every matching payer
+	matched := len(payers)
+	listed := payers[:filter.limit(matched)]
+
+	if format == formatCSV {
+		h.streamCSV(w, r, "payers-"+address+".csv", "payer_address", payerCSVRows(listed), numbers)
+		return
+	}
+
+	// Cap the response size, keeping the most significant entries
+	response := PayerResponse{
+		Message:                  "success",
+		RequestID:                requestIDFromContext(r.Context()),
+		HasSanctionedInteraction: h.sanctionScreening(sanctioned),
+	}
+	if ethPrice > 0 {
+		response.ETHPriceUSD = ethPrice
+		for _, p := range payers {
+			response.TotalUSDValue += p.USDValue
+		}
+	}
+	listed, response.Truncated = analyzer.CapPayers(listed, h.config.MaxCounterparties, txCap.max, txCap.keep)
+	if response.Truncated || len(listed) < matched {
+		response.TotalAvailable = matched
+	}
+	response.Data = toPayerData(listed, h.amountFormat(r.URL.Query().Get("precise") == "true"))
+
+	if format == formatNDJSON {
+		streamNDJSON(h, w, r, response.Data)
+		return
+	}
+
+	if format == formatReport {
+		h.respondWithReport(w, r, "payer", address, response)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// structuringThresholds returns the configured structuring detection thresholds
+func (h *Handler) structuringThresholds() analyzer.StructuringThresholds {
+	return analyzer.StructuringThresholds{
+		MinRepeatedAmounts: h.config.StructuringMinRepeated,
+		MinRoundAmounts:    h.config.StructuringMinRound,
+		RoundTolerance:     h.config.StructuringRoundTolerance,
+	}
+}
+
+// exchangeMovementThresholds returns the configured internal exchange movement detection thresholds
+func (h *Handler) exchangeMovementThresholds() analyzer.ExchangeMovementThresholds {
+	return analyzer.ExchangeMovementThresholds{
+		MinAmount:      h.config.ExchangeInternalMinAmount,
+		RoundTolerance: h.config.StructuringRoundTolerance,
+	}
+}
+
+// selfCustodyThresholds returns the configured self-custody detection thresholds
+func (h *Handler) selfCustodyThresholds() analyzer.SelfCustodyThresholds {
+	return analyzer.SelfCustodyThresholds{
+		MinForwardRatio: h.config.SelfCustodyMinForwardPercent / 100,
+		Window:          h.config.SelfCustodyWindow,
+		MaxLookups:      h.config.SelfCustodyMaxLookups,
+	}
+}
+
+// scoreWeights returns the configured counterparty significance score weights
+func (h *Handler) scoreWeights() analyzer.ScoreWeights {
+	return analyzer.ScoreWeights{
+		Amount:  h.config.ScoreWeightAmount,
+		Count:   h.config.ScoreWeightCount,
+		Recency: h.config.ScoreWeightRecency,
+	}
+}
+
+// sanctionScreening returns the has_sanctioned_interaction value of a response, or nil when
+// no sanctions list is configured so the field is left out
+func (h *Handler) sanctionScreening(sanctioned bool) *bool {
+	if len(h.config.SanctionedAddresses) == 0 {
+		return nil
+	}
+	return &sanctioned
+}
+
+// toBeneficiaryData converts analyzer beneficiaries to their response representation,
+// with exact decimal string amounts in precise mode
+func toBeneficiaryData(beneficiaries []analyzer.Beneficiary, format amountFormat) []BeneficiaryData {
+	responseData := make([]BeneficiaryData, len(beneficiaries))
+	for i, b := range beneficiaries {
+		amount := newDecimal(b.Amount, b.RawAmount, format)
+		responseData[i] = BeneficiaryData{
+			BeneficiaryAddress: b.Address,
+			Amount:             amount,
+			TxCount:            b.TxCount,
+			AvgAmount:          b.AvgAmount,
+			MaxAmount:          b.MaxAmount,
+			Transactions:       toTransactionDetails(b.Transactions, format),
+			Label:              b.Label,
+			USDValue:           b.USDValue,
+			IsContract:         b.IsContract,
+			Flags:              b.Flags,
+			Score:              b.Score,
+			Chains:             b.Chains,
+			Sanctioned:         b.Sanctioned,
+			PrecisionWarning:   amount.imprecise,
+		}
+	}
+	return responseData
+}
+
+// toPayerData converts analyzer payers to their response representation,
+// with exact decimal string amounts in precise mode
+func toPayerData(payers []analyzer.Payer, format amountFormat) []PayerData {
+	responseData := make([]PayerData, len(payers))
+	for i, p := range payers {
+		amount := newDecimal(p.Amount, p.RawAmount, format)
 		responseData[i] = PayerData{
-			PayerAddress:     p.Address,
-			Amount:           p.Amount,
-			Transactions:     txDetails,
+			PayerAddress: p.Address,
+			Amount:       amount,
+			TxCount:      p.TxCount,
+			AvgAmount:    p.AvgAmount,
+			MaxAmount:    p.MaxAmount,
+			Transactions: toTransactionDetails(p.Transactions, format),
+			Label:        p.Label,
+			USDValue:     p.USDValue,
+			IsContract:   p.IsContract,
+			Flags:        p.Flags,
+			Score:        p.Score,
+			Sanctioned:   p.Sanctioned,
+
+			PrecisionWarning: amount.imprecise,
 		}
 	}
+	return responseData
+}
 
-	h.respondWithJSON(w, http.StatusOK, PayerResponse{
-		Message: "success",
-		Data:    responseData,
-	})
+// toTransactionDetails converts analyzer transaction details to their response representation
+func toTransactionDetails(transactions []analyzer.TransactionDetails, format amountFormat) []TransactionDetails {
+	txDetails := make([]TransactionDetails, len(transactions))
+	for i, tx := range transactions {
+		var raw *big.Int
+		if format.precise || format.exceedsLimit(tx.TxAmount) {
+			raw, _ = new(big.Int).SetString(tx.RawValue, 10)
+		}
+		amount := newDecimal(tx.TxAmount, raw, format)
+		txDetails[i] = TransactionDetails{
+			TxAmount:      amount,
+			DateTime:      tx.DateTime,
+			TransactionID: tx.TransactionID,
+			ChainID:       tx.ChainID,
+			CallType:      tx.CallType,
+			Method:        tx.Method,
+			Kind:          tx.Kind,
+			USDValue:      tx.USDValue,
+			ViaProxy:      tx.ViaProxy,
+
+			PrecisionWarning: amount.imprecise,
+		}
+	}
+	return txDetails
+}
+
+// HandleReady handles the /ready endpoint by checking that Etherscan accepts the API key
+func (h *Handler) HandleReady(w http.ResponseWriter, r *http.Request) {
+	if err := h.checkReady(); err != nil {
+		requestLogger(h.logger, r).Warnf("Readiness check failed: %v", err)
+		h.respondWithError(w, r, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("READY"))
+}
+
+// checkReady validates the API key, reusing the last result, failed or not, for readinessTTL.
+// Concurrent checks wait for the one in progress instead of each calling Etherscan.
+func (h *Handler) checkReady() error {
+	h.ready.mu.Lock()
+	defer h.ready.mu.Unlock()
+
+	if !h.ready.checkedAt.IsZero() && time.Since(h.ready.checkedAt) < readinessTTL {
+		return h.ready.err
+	}
+	h.ready.err = h.etherscanClient.ValidateAPIKey()
+	h.ready.checkedAt = time.Now()
+	return h.ready.err
+}
+
+// respondWithAnalysisError writes an error response for a failed analysis,
+// mapping known upstream failures to a clear message
+func (h *Handler) respondWithAnalysisError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, etherscan.ErrInvalidAPIKey) {
+		h.respondWithError(w, r, http.StatusInternalServerError, "server misconfigured: "+etherscan.ErrInvalidAPIKey.Error())
+		return
+	}
+	if errors.Is(err, etherscan.ErrBudgetExhausted) {
+		h.respondWithError(w, r, http.StatusGatewayTimeout, "analysis timed out: "+err.Error())
+		return
+	}
+
+	h.respondWithError(w, r, http.StatusInternalServerError, err.Error())
 }
 
 // respondWithJSON writes a JSON response
-func (h *Handler) respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	response, err := json.Marshal(payload)
+func (h *Handler) respondWithJSON(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	h.writeJSON(w, r, code, payload, h.fieldNaming(r))
+}
+
+// writeJSON writes a JSON response with the given field naming
+func (h *Handler) writeJSON(w http.ResponseWriter, r *http.Request, code int, payload interface{}, naming string) {
+	response, err := marshalJSON(payload, naming)
 	if err != nil {
-		h.logger.Errorf("Error marshaling response: %v", err)
-		h.respondWithError(w, http.StatusInternalServerError, "Error creating response")
+		requestLogger(h.logger, r).Errorf("Error marshaling response: %v", err)
+		h.respondWithError(w, r, http.StatusInternalServerError, "Error creating response")
 		return
 	}
 
@@ -162,9 +674,10 @@ func (h *Handler) respondWithJSON(w http.ResponseWriter, code int, payload inter
 }
 
 // respondWithError writes an error response
-func (h *Handler) respondWithError(w http.ResponseWriter, code int, message string) {
-	h.respondWithJSON(w, code, ErrorResponse{
-		Message: "error",
-		Error:   message,
+func (h *Handler) respondWithError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	h.respondWithJSON(w, r, code, ErrorResponse{
+		Message:   "error",
+		RequestID: requestIDFromContext(r.Context()),
+		Error:     message,
 	})
 }
\ No newline at end of file
@@ -0,0 +1,441 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
+)
+
+const (
+	testAddress    = "0x1111111111111111111111111111111111111111"
+	testLarge      = "0x2222222222222222222222222222222222222222"
+	testMedium     = "0x3333333333333333333333333333333333333333"
+	testSanctioned = "0x4444444444444444444444444444444444444444"
+	testOld        = "0x5555555555555555555555555555555555555555"
+)
+
+// testTx is a normal transaction served by the stub Etherscan
+type testTx struct {
+	from, to string
+	ether    int64
+	at       time.Time
+}
+
+// newTestHandler returns a handler backed by a stub Etherscan serving txs as the address's
+// normal transactions, no internal transactions or token transfers, every address as an
+// externally-owned account, an ETH price of 2000 USD and block 500 as the latest
+func newTestHandler(t *testing.T, cfg *config.Config, txs []testTx) *Handler {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("action") {
+		case "txlist":
+			result := make([]etherscan.Transaction, len(txs))
+			for i, tx := range txs {
+				result[i] = etherscan.Transaction{
+					Hash:          fmt.Sprintf("0x%064x", i+1),
+					BlockNumber:   fmt.Sprint(100 + i),
+					TimeStamp:     fmt.Sprint(tx.at.Unix()),
+					From:          tx.from,
+					To:            tx.to,
+					Value:         fmt.Sprintf("%d000000000000000000", tx.ether),
+					IsError:       "0",
+					Confirmations: "100",
+				}
+			}
+			json.NewEncoder(w).Encode(etherscan.TransactionResponse{Status: "1", Message: "OK", Result: result})
+		case "eth_getCode":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x"}`)
+		case "eth_blockNumber":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x1f4"}`)
+		case "ethprice":
+			fmt.Fprint(w, `{"status":"1","message":"OK","result":{"ethbtc":"0.05","ethbtc_timestamp":"1710072000","ethusd":"2000","ethusd_timestamp":"1710072000"}}`)
+		default:
+			fmt.Fprint(w, `{"status":"0","message":"No transactions found","result":[]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := etherscan.NewClient("TESTKEY", server.URL)
+	beneficiaryAnalyzer := analyzer.NewBeneficiaryAnalyzer(client)
+	beneficiaryAnalyzer.SetSanctionedAddresses(cfg.SanctionedAddresses)
+	payerAnalyzer := analyzer.NewPayerAnalyzer(client)
+	payerAnalyzer.SetSanctionedAddresses(cfg.SanctionedAddresses)
+	return NewHandler(cfg, client, beneficiaryAnalyzer, payerAnalyzer, analyzer.NewFlowAnalyzer(client), logger.NewLogger())
+}
+
+// testConfig returns the configuration the handler tests run with, screening testSanctioned
+func testConfig() *config.Config {
+	return &config.Config{
+		MaxCounterparties:    100,
+		MaxTxPerCounterparty: 100,
+		SanctionedAddresses:  map[string]bool{testSanctioned: true},
+		FieldNaming:          config.FieldNamingSnakeCase,
+		CSVLocale:            "en",
+	}
+}
+
+// outgoingTxs are the address's outflows: 13 ETH to testLarge (10 of them long ago), 4 to
+// testMedium, 1 to the sanctioned address and 2 to testOld long ago
+func outgoingTxs() []testTx {
+	recent := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	return []testTx{
+		{testAddress, testLarge, 3, recent},
+		{testAddress, testLarge, 10, old},
+		{testAddress, testMedium, 4, recent.Add(time.Hour)},
+		{testAddress, testSanctioned, 1, recent.Add(2 * time.Hour)},
+		{testAddress, testOld, 2, old},
+	}
+}
+
+// listedCounterparty is a counterparty row of a response as a client decodes it
+type listedCounterparty struct {
+	Address string  `json:"beneficiary_address"`
+	Amount  float64 `json:"amount"`
+}
+
+// beneficiaryResult is a /beneficiary response as a client decodes it
+type beneficiaryResult struct {
+	Data           []listedCounterparty `json:"data"`
+	TotalAvailable int                  `json:"total_available"`
+	Sanctioned     *bool                `json:"has_sanctioned_interaction"`
+}
+
+// addresses returns the listed addresses, in order
+func (r beneficiaryResult) addresses() []string {
+	var addresses []string
+	for _, c := range r.Data {
+		addresses = append(addresses, c.Address)
+	}
+	return addresses
+}
+
+// get serves the request and decodes the JSON response into v
+func get(t *testing.T, handle http.HandlerFunc, target string, v interface{}) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handle(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", target, rec.Code, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("GET %s: decoding response: %v", target, err)
+	}
+}
+
+func TestBeneficiaryTopKeepsSanctionScreening(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	var response beneficiaryResult
+	get(t, h.HandleBeneficiary, "/beneficiary?address="+testAddress+"&top=1", &response)
+
+	if got := response.addresses(); len(got) != 1 || got[0] != testLarge {
+		t.Fatalf("listed %v, want only %s", got, testLarge)
+	}
+	if response.TotalAvailable != 4 {
+		t.Errorf("total_available = %d, want 4", response.TotalAvailable)
+	}
+	if response.Sanctioned == nil || !*response.Sanctioned {
+		t.Errorf("has_sanctioned_interaction = %v, want true for a sanctioned beneficiary outside top", response.Sanctioned)
+	}
+}
+
+func TestBeneficiaryFiltersKeepSanctionScreening(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	var response beneficiaryResult
+	get(t, h.HandleBeneficiary, "/beneficiary?address="+testAddress+"&min_amount=2", &response)
+
+	for _, address := range response.addresses() {
+		if address == testSanctioned {
+			t.Fatalf("min_amount=2 listed the 1 ETH sanctioned beneficiary")
+		}
+	}
+	if response.Sanctioned == nil || !*response.Sanctioned {
+		t.Errorf("has_sanctioned_interaction = %v, want true for a sanctioned beneficiary below min_amount", response.Sanctioned)
+	}
+}
+
+func TestBeneficiaryDatesApplyBeforeMinAmount(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	// testLarge received 13 ETH overall but only 3 since from_date, so min_amount=3.5 drops it
+	var response beneficiaryResult
+	get(t, h.HandleBeneficiary, "/beneficiary?address="+testAddress+"&from_date=2024-01-01&min_amount=3.5", &response)
+
+	if got := response.addresses(); len(got) != 1 || got[0] != testMedium {
+		t.Fatalf("listed %v, want only %s", got, testMedium)
+	}
+	if amount := response.Data[0].Amount; amount != 4 {
+		t.Errorf("amount = %v, want 4", amount)
+	}
+}
+
+func TestBeneficiaryTopAppliesAfterFiltersAndSort(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	// Within the dates the sanctioned address and testMedium are the most recent; the filters
+	// and the sort run before top whatever the parameter order
+	var response beneficiaryResult
+	get(t, h.HandleBeneficiary, "/beneficiary?top=2&sort=recent&address="+testAddress+"&from_date=2024-01-01", &response)
+
+	if got := response.addresses(); strings.Join(got, ",") != testSanctioned+","+testMedium {
+		t.Fatalf("listed %v, want [%s %s]", got, testSanctioned, testMedium)
+	}
+	if response.TotalAvailable != 3 {
+		t.Errorf("total_available = %d, want 3", response.TotalAvailable)
+	}
+}
+
+func TestBeneficiaryTotalUSDValueIgnoresTop(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	var response struct {
+		ETHPriceUSD   float64 `json:"eth_price_usd"`
+		TotalUSDValue float64 `json:"total_usd_value"`
+	}
+	get(t, h.HandleBeneficiary, "/beneficiary?address="+testAddress+"&eth_price=true&top=1", &response)
+
+	// 20 ETH went out in total at the stub's 2000 USD, not just the 13 of the top beneficiary
+	if response.ETHPriceUSD != 2000 || response.TotalUSDValue != 40000 {
+		t.Errorf("eth_price_usd = %v, total_usd_value = %v, want 2000 and 40000", response.ETHPriceUSD, response.TotalUSDValue)
+	}
+}
+
+func TestBeneficiarySummaryIgnoresTop(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	var response struct {
+		Data       map[string]float64 `json:"data"`
+		Sanctioned *bool              `json:"has_sanctioned_interaction"`
+	}
+	get(t, h.HandleBeneficiary, "/beneficiary?address="+testAddress+"&summary=by_category&min_amount=2&top=1", &response)
+
+	// Every beneficiary of at least 2 ETH counts, not just the top one
+	if unknown := response.Data[analyzer.CategoryUnknown]; unknown != 19 {
+		t.Errorf("Unknown total = %v, want 19", unknown)
+	}
+	if response.Sanctioned == nil || !*response.Sanctioned {
+		t.Errorf("has_sanctioned_interaction = %v, want true", response.Sanctioned)
+	}
+}
+
+func TestBeneficiaryBreakdownIgnoresTop(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	var response struct {
+		Data []struct {
+			Method  string  `json:"method"`
+			Amount  float64 `json:"amount"`
+			TxCount int     `json:"tx_count"`
+		} `json:"data"`
+	}
+	get(t, h.HandleBeneficiary, "/beneficiary?address="+testAddress+"&breakdown=by_method&top=1", &response)
+
+	if len(response.Data) != 1 || response.Data[0].Method != analyzer.MethodPlainTransfer {
+		t.Fatalf("data = %+v, want a single plain_transfer group", response.Data)
+	}
+	if got := response.Data[0]; got.Amount != 20 || got.TxCount != 5 {
+		t.Errorf("plain_transfer = %v ETH in %d transactions, want 20 ETH in 5", got.Amount, got.TxCount)
+	}
+}
+
+func TestBeneficiaryBucketTotalsIgnoreTop(t *testing.T) {
+	// No address is a contract, so bucketing puts every beneficiary in wallets
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	var response struct {
+		Data []struct {
+			Bucket        string               `json:"bucket"`
+			Amount        float64              `json:"amount"`
+			Count         int                  `json:"count"`
+			Beneficiaries []listedCounterparty `json:"beneficiaries"`
+		} `json:"data"`
+	}
+	get(t, h.HandleBeneficiary, "/beneficiary?address="+testAddress+"&bucket=true&top=1", &response)
+
+	for _, bucket := range response.Data {
+		if bucket.Bucket != "wallets" {
+			continue
+		}
+		if bucket.Count != 4 || bucket.Amount != 20 {
+			t.Errorf("wallets total = %v ETH over %d, want 20 ETH over 4", bucket.Amount, bucket.Count)
+		}
+		if len(bucket.Beneficiaries) != 1 || bucket.Beneficiaries[0].Address != testLarge {
+			t.Errorf("wallets listed %+v, want only %s", bucket.Beneficiaries, testLarge)
+		}
+		return
+	}
+	t.Fatalf("no wallets bucket in %+v", response.Data)
+}
+
+func TestPayerTopKeepsSanctionScreening(t *testing.T) {
+	// The same transfers seen from the receiving side: every counterparty paid testAddress
+	var txs []testTx
+	for _, tx := range outgoingTxs() {
+		txs = append(txs, testTx{from: tx.to, to: testAddress, ether: tx.ether, at: tx.at})
+	}
+	h := newTestHandler(t, testConfig(), txs)
+
+	var response struct {
+		Data []struct {
+			Address string `json:"payer_address"`
+		} `json:"data"`
+		TotalAvailable int   `json:"total_available"`
+		Sanctioned     *bool `json:"has_sanctioned_interaction"`
+	}
+	get(t, h.HandlePayer, "/payer?address="+testAddress+"&top=1", &response)
+
+	if len(response.Data) != 1 || response.Data[0].Address != testLarge {
+		t.Fatalf("listed %+v, want only %s", response.Data, testLarge)
+	}
+	if response.TotalAvailable != 4 {
+		t.Errorf("total_available = %d, want 4", response.TotalAvailable)
+	}
+	if response.Sanctioned == nil || !*response.Sanctioned {
+		t.Errorf("has_sanctioned_interaction = %v, want true for a sanctioned payer outside top", response.Sanctioned)
+	}
+}
+
+func TestBeneficiaryChainsRejectUSD(t *testing.T) {
+	cfg := testConfig()
+	cfg.EtherscanBaseURL = "https://api.etherscan.io/v2/api"
+	h := newTestHandler(t, cfg, outgoingTxs())
+
+	for _, query := range []string{"&usd=true", "&eth_price=true"} {
+		rec := httptest.NewRecorder()
+		h.HandleBeneficiary(rec, httptest.NewRequest(http.MethodGet, "/beneficiary?address="+testAddress+"&chains=1,137"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("chains with %s: status %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestHandlersRejectInvalidAddresses(t *testing.T) {
+	h := newTestHandler(t, testConfig(), outgoingTxs())
+
+	tests := []struct {
+		handle http.HandlerFunc
+		target string
+	}{
+		{h.HandleBeneficiary, "/beneficiary?address=0x1234"},
+		{h.HandlePayer, "/payer?address=0xzz11111111111111111111111111111111111111"},
+		{h.HandleProfile, "/profile?address=1111111111111111111111111111111111111111"},
+		{h.HandleTimeSeries, "/timeseries?address=0x1234&interval=day"},
+		{h.HandleTransactions, "/transactions?address=0x1234"},
+		{h.HandleTrace, "/trace?address=0x1234"},
+		{h.HandleCounterparty, "/counterparty?from=" + testAddress + "&to=0x1234"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.handle(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status %d, want 400", tt.target, rec.Code)
+		}
+	}
+}
+
+func TestReadyReusesRecentCheck(t *testing.T) {
+	var checks int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checks++
+		fmt.Fprint(w, `{"status":"1","message":"OK","result":"0"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client := etherscan.NewClient("TESTKEY", server.URL)
+	h := NewHandler(testConfig(), client, analyzer.NewBeneficiaryAnalyzer(client), analyzer.NewPayerAnalyzer(client),
+		analyzer.NewFlowAnalyzer(client), logger.NewLogger())
+
+	ready := func() {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.HandleReady(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	ready()
+	ready()
+	if checks != 1 {
+		t.Fatalf("%d Etherscan requests for two probes, want 1", checks)
+	}
+
+	// Once the result is older than readinessTTL the key is checked again
+	h.ready.checkedAt = time.Now().Add(-readinessTTL)
+	ready()
+	if checks != 2 {
+		t.Errorf("%d Etherscan requests after the result expired, want 2", checks)
+	}
+}
+
+func TestReportDigestAndSignatureMatchCanonicalReport(t *testing.T) {
+	cfg := testConfig()
+	cfg.ReportHMACKey = "report-secret"
+	h := newTestHandler(t, cfg, outgoingTxs())
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/beneficiary?address="+testAddress+"&format=report", nil)
+	result := map[string]interface{}{"label": "<Foo & Bar>", "amount": json.Number("1.500000000000000001")}
+	h.respondWithReport(rec, req, "beneficiary", testAddress, result)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Report    json.RawMessage `json:"report"`
+		Digest    ReportHash      `json:"digest"`
+		Signature *ReportHash     `json:"signature"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	// A verifier re-encodes the report canonically and must get exactly the bytes that were hashed
+	dec := json.NewDecoder(bytes.NewReader(response.Report))
+	dec.UseNumber()
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := canonicalJSON(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(canonical, response.Report) {
+		t.Fatalf("re-canonicalized report differs:\n%s\n%s", canonical, response.Report)
+	}
+
+	digest := sha256.Sum256(canonical)
+	if response.Digest.Algorithm != reportDigestAlgorithm || response.Digest.Value != hex.EncodeToString(digest[:]) {
+		t.Errorf("digest = %+v, want the sha256 of the canonical report", response.Digest)
+	}
+	mac := hmac.New(sha256.New, []byte(cfg.ReportHMACKey))
+	mac.Write(canonical)
+	if response.Signature == nil || response.Signature.Algorithm != reportSignatureAlgorithm || response.Signature.Value != hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("signature = %+v, want the HMAC of the canonical report", response.Signature)
+	}
+
+	// HTML characters and long numbers are kept byte for byte, in the report and the response body
+	for _, want := range []string{`"label":"<Foo & Bar>"`, `"amount":1.500000000000000001`, `"query":"address=` + testAddress + `&format=report"`} {
+		if !bytes.Contains(response.Report, []byte(want)) || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("report does not contain %s: %s", want, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// maxHashBatchSize is the maximum number of transaction hashes accepted in a single request.
+// Every hash costs three Etherscan calls.
+const maxHashBatchSize = 50
+
+// TransactionBatchRequest represents the request body for the batch transaction endpoint
+type TransactionBatchRequest struct {
+	Hashes []string `json:"hashes"`
+}
+
+// TransactionBatchResponse represents the response format for the batch transaction endpoint
+type TransactionBatchResponse struct {
+	Message   string               `json:"message"`
+	RequestID string               `json:"request_id,omitempty"`
+	Truncated bool                 `json:"truncated,omitempty"`
+	Data      TransactionBatchData `json:"data"`
+
+	// HasSanctionedInteraction is set when SANCTIONS_LIST_PATH is configured
+	HasSanctionedInteraction *bool `json:"has_sanctioned_interaction,omitempty"`
+}
+
+// TransactionBatchData represents the combined fund flow of a list of transactions
+type TransactionBatchData struct {
+	Transactions  int               `json:"transactions"`
+	Failed        []string          `json:"failed,omitempty"`
+	Beneficiaries []BeneficiaryData `json:"beneficiaries"`
+	Payers        []PayerData       `json:"payers"`
+}
+
+// HandleBatchTransaction handles the /batch/tx endpoint
+func (h *Handler) HandleBatchTransaction(w http.ResponseWriter, r *http.Request) {
+	var req TransactionBatchRequest
+	if err := decodeJSONBody(w, r, h.config.MaxBodyBytes, &req); err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(req.Hashes) == 0 {
+		h.respondWithError(w, r, http.StatusBadRequest, "hashes must contain at least one transaction hash")
+		return
+	}
+	if len(req.Hashes) > maxHashBatchSize {
+		h.respondWithError(w, r, http.StatusBadRequest, fmt.Sprintf("batch size exceeds maximum of %d transaction hashes", maxHashBatchSize))
+		return
+	}
+
+	hashes, err := sanitizeHashes(req.Hashes)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	txCap, err := parseTransactionCap(r, h.config.MaxTxPerCounterparty)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	precise := r.URL.Query().Get("precise") == "true"
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Mapping combined fund flow for %d transactions", len(hashes))
+
+	flows, err := h.flowAnalyzer.AnalyzeTransactions(hashes)
+	if errors.Is(err, etherscan.ErrTransactionNotFound) {
+		h.respondWithError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Errorf("Error mapping transactions: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	beneficiaries := h.beneficiaryAnalyzer.BeneficiariesFromFlows(flows, opts)
+	payers := h.payerAnalyzer.PayersFromFlows(flows, opts)
+	response := TransactionBatchResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		HasSanctionedInteraction: h.sanctionScreening(
+			analyzer.HasSanctionedBeneficiary(beneficiaries) || analyzer.HasSanctionedPayer(payers)),
+	}
+
+	// Reverted transactions moved nothing
+	response.Data.Transactions = len(flows)
+	for _, flow := range flows {
+		if !flow.Success {
+			response.Data.Failed = append(response.Data.Failed, flow.Hash)
+		}
+	}
+
+	var beneficiariesTruncated, payersTruncated bool
+	beneficiaries, beneficiariesTruncated = analyzer.CapBeneficiaries(beneficiaries, h.config.MaxCounterparties, txCap.max, txCap.keep)
+	payers, payersTruncated = analyzer.CapPayers(payers, h.config.MaxCounterparties, txCap.max, txCap.keep)
+	response.Truncated = beneficiariesTruncated || payersTruncated
+	response.Data.Beneficiaries = toBeneficiaryData(beneficiaries, h.amountFormat(precise))
+	response.Data.Payers = toPayerData(payers, h.amountFormat(precise))
+
+	h.respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// sanitizeHashes trims and lowercases the transaction hashes, dropping duplicates, and rejects
+// the request if any of them is not a valid transaction hash
+func sanitizeHashes(hashes []string) ([]string, error) {
+	seen := make(map[string]bool, len(hashes))
+	sanitized := make([]string, 0, len(hashes))
+	for i, hash := range hashes {
+		hash = strings.ToLower(strings.TrimSpace(hash))
+		if !etherscan.IsValidTransactionHash(hash) {
+			return nil, fmt.Errorf("hashes[%d] is not a valid transaction hash: %q", i, hashes[i])
+		}
+		if !seen[hash] {
+			seen[hash] = true
+			sanitized = append(sanitized, hash)
+		}
+	}
+	return sanitized, nil
+}
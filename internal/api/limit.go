@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// analysisRetryAfter is the Retry-After, in seconds, sent when every analysis slot is taken
+const analysisRetryAfter = 5
+
+// analysisLimiter is a semaphore bounding the requests analyzed at once
+type analysisLimiter struct {
+	slots chan struct{}
+}
+
+// newAnalysisLimiter creates a limiter admitting at most limit requests at once, or returns
+// nil (unlimited) when limit is 0
+func newAnalysisLimiter(limit int) *analysisLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &analysisLimiter{slots: make(chan struct{}, limit)}
+}
+
+// tryAcquire takes a slot without waiting and reports whether one was free
+func (l *analysisLimiter) tryAcquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a slot taken by tryAcquire
+func (l *analysisLimiter) release() {
+	<-l.slots
+}
+
+// limitAnalyses refuses requests with 429 and a Retry-After header while MAX_CONCURRENT_ANALYSES
+// requests are already being analyzed, instead of queuing them. Without a limit it is a no-op.
+func (r *Router) limitAnalyses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.analyses == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if !r.analyses.tryAcquire() {
+			requestLogger(r.logger, req).Warnf("Refusing %s: %d analyses already in progress", req.URL.Path, cap(r.analyses.slots))
+			w.Header().Set("Retry-After", strconv.Itoa(analysisRetryAfter))
+			r.handler.respondWithError(w, req, http.StatusTooManyRequests, "too many analyses in progress, retry later")
+			return
+		}
+		defer r.analyses.release()
+
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
+)
+
+// contextKey is the type used for values stored in the request context
+type contextKey string
+
+const (
+	requestIDKey    contextKey = "request_id"
+	requestIDHeader            = "X-Request-ID"
+)
+
+// requestIDMiddleware attaches a request ID to the request context and response headers.
+// The client-supplied X-Request-ID is reused when present, otherwise a new UUID is generated.
+func (r *Router) requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestID := req.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set(requestIDHeader, requestID)
+		ctx := context.WithValue(req.Context(), requestIDKey, requestID)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// authMiddleware requires a matching "Authorization: Bearer <token>" header when
+// API_AUTH_TOKEN is configured. Without a configured token requests pass through unchanged.
+func (r *Router) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token := r.config.APIAuthToken
+		if token == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		provided, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			r.handler.respondWithError(w, req, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// corsMiddleware sets CORS headers for requests from allowed origins and answers
+// preflight requests. It wraps the whole router so OPTIONS requests are handled
+// before route method matching. With no configured origins it is a no-op.
+func (r *Router) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		if origin == "" || !r.originAllowed(origin) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
+
+		// Answer preflight requests directly
+		if req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+requestIDHeader+", "+fieldNamingHeader)
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// originAllowed reports whether the origin is in the configured CORS origins
+func (r *Router) originAllowed(origin string) bool {
+	for _, allowed := range r.config.CORSOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// loggingMiddleware logs HTTP requests
+func (r *Router) loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestLogger(r.logger, req).Infof("Request: %s %s", req.Method, req.URL.Path)
+		next.ServeHTTP(w, req)
+	})
+}
+
+// requestIDFromContext returns the request ID stored in the context, if any
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// requestLogger returns a logger that tags every line with the request ID
+func requestLogger(l logger.Logger, req *http.Request) logger.Logger {
+	requestID := requestIDFromContext(req.Context())
+	if requestID == "" {
+		return l
+	}
+	return l.WithField("request_id", requestID)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+)
+
+// fieldNamingHeader selects the JSON field naming of one response, overriding JSON_FIELD_NAMING
+const fieldNamingHeader = "X-Field-Naming"
+
+// fieldNaming returns the JSON field naming of the response to r: the X-Field-Naming header
+// when it names a supported convention, JSON_FIELD_NAMING otherwise
+func (h *Handler) fieldNaming(r *http.Request) string {
+	if naming := r.Header.Get(fieldNamingHeader); config.ValidFieldNaming(naming) {
+		return naming
+	}
+	return h.config.FieldNaming
+}
+
+// marshalJSON marshals v with the given field naming. Go field names and struct tags stay
+// snake_case; camelCase is produced by rewriting the object keys of the marshaled document.
+func marshalJSON(v interface{}, naming string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || naming != config.FieldNamingCamelCase {
+		return data, err
+	}
+	return camelCaseKeys(data)
+}
+
+// jsonFrame is an object or array being rewritten by camelCaseKeys
+type jsonFrame struct {
+	object bool
+	tokens int // Keys and values seen so far
+}
+
+// camelCaseKeys rewrites every object key of a JSON document from snake_case to camelCase,
+// keeping the key order and the values, numbers included, exactly as they were
+func camelCaseKeys(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	var stack []jsonFrame
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error rewriting JSON keys: %w", err)
+		}
+
+		// Write the separator before the token and note whether it is an object key
+		isKey := false
+		if delim, ok := tok.(json.Delim); !ok || delim == '{' || delim == '[' {
+			if n := len(stack); n > 0 {
+				top := &stack[n-1]
+				switch {
+				case top.object && top.tokens%2 == 1:
+					out.WriteByte(':')
+				case top.tokens > 0:
+					out.WriteByte(',')
+				}
+				isKey = top.object && top.tokens%2 == 0
+				top.tokens++
+			}
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			out.WriteRune(rune(t))
+			switch t {
+			case '{', '[':
+				stack = append(stack, jsonFrame{object: t == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if isKey {
+				t = snakeToCamel(t)
+			}
+			encoded, err := json.Marshal(t)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(encoded)
+		case json.Number:
+			out.WriteString(t.String())
+		case bool:
+			fmt.Fprint(&out, t)
+		case nil:
+			out.WriteString("null")
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// snakeToCamel converts a snake_case name to camelCase, e.g. beneficiary_address to
+// beneficiaryAddress. Names without underscores are returned unchanged.
+func snakeToCamel(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+
+	parts := strings.Split(name, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+)
+
+// openAPIParameter describes a query parameter in the OpenAPI document
+type openAPIParameter struct {
+	name        string
+	kind        string
+	enum        []string
+	required    bool
+	description string
+}
+
+// analysisParameters are the query parameters shared by /beneficiary and /payer
+var analysisParameters = []openAPIParameter{
+	{name: "address", kind: "string", required: true, description: "Ethereum address to analyze"},
+	{name: "from_block", kind: "integer", description: "Only include transactions from this block"},
+	{name: "to_block", kind: "integer", description: "Only include transactions up to this block"},
+	{name: "tz", kind: "string", description: "IANA time zone used for date_time values (default UTC)"},
+	{name: "types", kind: "string", description: "Comma-separated transaction types to fetch: normal, internal, token (default all)"},
+	{name: "exclude_call_types", kind: "string", description: "Comma-separated internal transaction call types to skip, e.g. create,suicide"},
+	{name: "merge_internal", kind: "boolean", description: "Fold internal transactions into their parent normal transaction"},
+	{name: "detect_contracts", kind: "boolean", description: "Tag each counterparty with is_contract"},
+	{name: "stream", kind: "boolean", description: "Aggregate the complete normal transaction history page by page"},
+	{name: "resolve_proxies", kind: "boolean", description: "Attribute payments sent by known proxy contracts to their originator (payer only)"},
+	{name: "exclude", kind: "string", description: "Comma-separated counterparty addresses to drop, in addition to OWN_ADDRESSES"},
+	{name: "weth", kind: "string", enum: []string{analyzer.WETHFold, analyzer.WETHLabel}, description: "Drop Ether wrapped into or unwrapped from WETH, or label it wrap/unwrap"},
+	{name: "usd", kind: "boolean", description: "Value transfers at the historical USD price on their day"},
+	{name: "eth_price", kind: "boolean", description: "Value Ether transfers at the current Etherscan price, adding eth_price_usd and total_usd_value (not with usd or chains)"},
+	{name: "mint_burn", kind: "boolean", description: "Attribute mints and burns of the analyzed token contract's own token to the recipient and burner"},
+	{name: "exclude_burns", kind: "boolean", description: "Leave transfers to the zero address out of beneficiary analysis"},
+	{name: "include_gas", kind: "boolean", description: "Add a synthetic beneficiary summing the gas fees paid by the address"},
+	{name: "min_confirmations", kind: "integer", description: "Skip transactions with fewer confirmations, which may still be reorganized away"},
+	{name: "include_spam", kind: "boolean", description: "Keep token transfers from denylisted spam contracts"},
+	{name: "dedupe", kind: "boolean", description: "Collapse a counterparty's entries sharing a transaction hash"},
+	{name: "flags", kind: "boolean", description: "Flag counterparties showing structuring patterns"},
+	{name: "self_custody", kind: "boolean", description: "Flag fresh beneficiaries that forward most of what they received, likely the same entity (beneficiary only)"},
+	{name: "bucket", kind: "boolean", description: "Group beneficiaries into exchanges, contracts and wallets with per-bucket totals (beneficiary only, JSON)"},
+	{name: "summary", kind: "string", enum: []string{summaryByCategory}, description: "Total beneficiaries by label category instead of listing them (beneficiary only, JSON)"},
+	{name: "breakdown", kind: "string", enum: []string{breakdownByMethod}, description: "Total outgoing value by the decoded method called instead of listing beneficiaries (beneficiary only, JSON)"},
+	{name: "sort", kind: "string", enum: []string{analyzer.SortAmount, analyzer.SortCount, analyzer.SortRecent, analyzer.SortScore}, description: "Result ordering (default amount)"},
+	{name: "order", kind: "string", enum: []string{orderAsc, orderDesc}, description: "Sort direction (default desc)"},
+	{name: "max_tx_per_counterparty", kind: "integer", description: "Maximum transactions returned per counterparty (totals still cover all)"},
+	{name: "keep_tx", kind: "string", enum: []string{analyzer.KeepLargest, analyzer.KeepRecent}, description: "Which transactions survive the per-counterparty cap (default largest)"},
+	{name: "precise", kind: "boolean", description: "Serialize amounts as exact decimal strings instead of numbers"},
+	{name: "from_date", kind: "string", description: "Only count transactions on or after this date (YYYY-MM-DD in tz), recomputing totals"},
+	{name: "to_date", kind: "string", description: "Only count transactions on or before this date (YYYY-MM-DD in tz), recomputing totals"},
+	{name: "active_within", kind: "integer", description: "Only return counterparties whose latest transaction is within this many days"},
+	{name: "min_amount", kind: "number", description: "Only return counterparties whose total amount is at least this"},
+	{name: "top", kind: "integer", description: "Only return the first N counterparties after filtering and sorting"},
+	{name: "chains", kind: "string", description: "Comma-separated chain IDs to analyze and merge (beneficiary analysis, Etherscan V2 API)"},
+	{name: "format", kind: "string", enum: []string{formatJSON, formatCSV, formatNDJSON, formatReport}, description: "Output format"},
+	{name: "locale", kind: "string", enum: []string{config.CSVLocaleEnglish, config.CSVLocaleEnglishUS, config.CSVLocaleGerman, config.CSVLocaleFrench}, description: "Number formatting of CSV output"},
+}
+
+var (
+	openAPIOnce     sync.Once
+	openAPIDocument map[string]interface{}
+)
+
+// HandleOpenAPI handles the /openapi.json endpoint
+func (h *Handler) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	openAPIOnce.Do(func() {
+		openAPIDocument = buildOpenAPIDocument()
+	})
+	h.respondWithJSON(w, r, http.StatusOK, openAPIDocument)
+}
+
+// buildOpenAPIDocument builds the OpenAPI 3 document. Response schemas are reflected from
+// the response structs so the spec stays in sync with what the handlers write.
+func buildOpenAPIDocument() map[string]interface{} {
+	schemas := newSchemaBuilder()
+	errorSchema := schemas.schemaFor(reflect.TypeOf(ErrorResponse{}))
+
+	analysisOperation := func(summary string, response interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"summary":    summary,
+			"parameters": queryParameters(analysisParameters),
+			"responses": map[string]interface{}{
+				"200": jsonResponse("Counterparties of the address", schemas.schemaFor(reflect.TypeOf(response))),
+				"400": jsonResponse("Invalid request parameters", errorSchema),
+				"401": jsonResponse("Missing or invalid bearer token", errorSchema),
+				"500": jsonResponse("Analysis failed", errorSchema),
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Ethereum Fund Flow Analysis API",
+			"description": "Analyzes the flow of funds for Ethereum addresses to determine beneficiaries and payers.",
+			"version":     "1.0.0",
+		},
+		"paths": map[string]interface{}{
+			"/beneficiary": map[string]interface{}{
+				"get": analysisOperation("Identify where funds flow to from an address", BeneficiaryResponse{}),
+			},
+			"/payer": map[string]interface{}{
+				"get": analysisOperation("Identify where funds flowing into an address come from", PayerResponse{}),
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"bearerAuth": []string{}},
+		},
+	}
+}
+
+// queryParameters converts parameter descriptions to OpenAPI parameter objects
+func queryParameters(params []openAPIParameter) []interface{} {
+	result := make([]interface{}, len(params))
+	for i, p := range params {
+		schema := map[string]interface{}{"type": p.kind}
+		if len(p.enum) > 0 {
+			schema["enum"] = p.enum
+		}
+		result[i] = map[string]interface{}{
+			"name":        p.name,
+			"in":          "query",
+			"required":    p.required,
+			"description": p.description,
+			"schema":      schema,
+		}
+	}
+	return result
+}
+
+// jsonResponse builds an OpenAPI response object with a JSON body
+func jsonResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// schemaBuilder reflects Go types into OpenAPI schemas, collecting named structs as components
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+// newSchemaBuilder creates a new schema builder
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]interface{}),
+	}
+}
+
+// schemaFor returns the schema for a type, referencing named structs by component
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]interface{} {
+	// Amounts are numbers, or exact decimal strings in precise mode
+	if t == reflect.TypeOf(Decimal{}) {
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "number"},
+				map[string]interface{}{"type": "string"},
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return b.structSchema(t)
+		}
+		if _, exists := b.components[name]; !exists {
+			b.components[name] = map[string]interface{}{} // Reserve the name for recursive types
+			b.components[name] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema builds an object schema from a struct's JSON-tagged fields. Fields without
+// omitempty are always written and are therefore marked required.
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, tagOptions, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = b.schemaFor(field.Type)
+		if !strings.Contains(tagOptions, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+)
+
+const (
+	orderAsc  = "asc"
+	orderDesc = "desc"
+)
+
+// sortOptions controls the ordering of counterparty results
+type sortOptions struct {
+	by        string
+	ascending bool
+}
+
+// parseSort validates the requested result ordering, defaulting to total amount, descending
+func parseSort(r *http.Request) (sortOptions, error) {
+	query := r.URL.Query()
+	opts := sortOptions{by: analyzer.SortAmount}
+
+	switch by := query.Get("sort"); by {
+	case "":
+	case analyzer.SortAmount, analyzer.SortCount, analyzer.SortRecent, analyzer.SortScore:
+		opts.by = by
+	default:
+		return opts, fmt.Errorf("sort must be 'amount', 'count', 'recent' or 'score'")
+	}
+
+	switch order := query.Get("order"); order {
+	case "", orderDesc:
+	case orderAsc:
+		opts.ascending = true
+	default:
+		return opts, fmt.Errorf("order must be 'asc' or 'desc'")
+	}
+
+	return opts, nil
+}
+
+// parseActiveWithin reads active_within, a number of days, and returns the cutoff before which
+// a counterparty's latest activity drops it. The zero time means no filter.
+func parseActiveWithin(r *http.Request) (time.Time, error) {
+	value := r.URL.Query().Get("active_within")
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		return time.Time{}, fmt.Errorf("active_within must be a positive number of days")
+	}
+	return time.Now().AddDate(0, 0, -days), nil
+}
+
+// transactionCap controls how many transactions are returned per counterparty
+type transactionCap struct {
+	max  int
+	keep string
+}
+
+// parseTransactionCap reads max_tx_per_counterparty and keep_tx. The query can only lower
+// the configured MAX_TX_PER_COUNTERPARTY, never lift it.
+func parseTransactionCap(r *http.Request, configMax int) (transactionCap, error) {
+	query := r.URL.Query()
+	txCap := transactionCap{max: configMax, keep: analyzer.KeepLargest}
+
+	if value := query.Get("max_tx_per_counterparty"); value != "" {
+		max, err := strconv.Atoi(value)
+		if err != nil || max < 1 {
+			return txCap, fmt.Errorf("max_tx_per_counterparty must be a positive integer")
+		}
+		if configMax <= 0 || max < configMax {
+			txCap.max = max
+		}
+	}
+
+	switch keep := query.Get("keep_tx"); keep {
+	case "":
+	case analyzer.KeepLargest, analyzer.KeepRecent:
+		txCap.keep = keep
+	default:
+		return txCap, fmt.Errorf("keep_tx must be 'largest' or 'recent'")
+	}
+
+	return txCap, nil
+}
+
+// parseAnalysisOptions builds analyzer options from the request's query parameters
+func parseAnalysisOptions(r *http.Request) (analyzer.Options, error) {
+	query := r.URL.Query()
+	opts := analyzer.Options{
+		Location: time.UTC,
+	}
+
+	if tz := query.Get("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return opts, fmt.Errorf("invalid tz parameter: %q is not a known IANA time zone", tz)
+		}
+		opts.Location = loc
+	}
+
+	fromBlock, err := parseBlockParam(query.Get("from_block"), "from_block")
+	if err != nil {
+		return opts, err
+	}
+	toBlock, err := parseBlockParam(query.Get("to_block"), "to_block")
+	if err != nil {
+		return opts, err
+	}
+	if toBlock > 0 && fromBlock > toBlock {
+		return opts, fmt.Errorf("from_block must not be greater than to_block")
+	}
+	opts.FromBlock = fromBlock
+	opts.ToBlock = toBlock
+
+	if types := query.Get("types"); types != "" {
+		opts.Types = make(map[string]bool)
+		for _, txType := range strings.Split(types, ",") {
+			switch txType = strings.TrimSpace(txType); txType {
+			case analyzer.MovementNormal, analyzer.MovementInternal, analyzer.MovementToken:
+				opts.Types[txType] = true
+			default:
+				return opts, fmt.Errorf("types must be a comma-separated list of 'normal', 'internal' and 'token'")
+			}
+		}
+	}
+
+	if callTypes := query.Get("exclude_call_types"); callTypes != "" {
+		opts.ExcludeCallTypes = make(map[string]bool)
+		for _, callType := range strings.Split(callTypes, ",") {
+			if callType = strings.TrimSpace(callType); callType != "" {
+				opts.ExcludeCallTypes[strings.ToLower(callType)] = true
+			}
+		}
+	}
+
+	opts.MergeInternal = query.Get("merge_internal") == "true"
+	opts.DetectContracts = query.Get("detect_contracts") == "true"
+	opts.IncludeSpam = query.Get("include_spam") == "true"
+	opts.MintBurn = query.Get("mint_burn") == "true"
+	opts.ExcludeBurns = query.Get("exclude_burns") == "true"
+	opts.IncludeGas = query.Get("include_gas") == "true"
+	opts.Stream = query.Get("stream") == "true"
+	opts.ResolveProxies = query.Get("resolve_proxies") == "true"
+	opts.USD = query.Get("usd") == "true"
+	if opts.USD && query.Get("eth_price") == "true" {
+		return opts, fmt.Errorf("usd=true and eth_price=true cannot be combined")
+	}
+
+	if value := query.Get("min_confirmations"); value != "" {
+		minConfirmations, err := strconv.Atoi(value)
+		if err != nil || minConfirmations < 0 {
+			return opts, fmt.Errorf("min_confirmations must be a non-negative integer")
+		}
+		opts.MinConfirmations = minConfirmations
+	}
+
+	if exclude := query.Get("exclude"); exclude != "" {
+		opts.Exclude = make(map[string]bool)
+		for _, address := range strings.Split(exclude, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				opts.Exclude[strings.ToLower(address)] = true
+			}
+		}
+	}
+
+	switch weth := query.Get("weth"); weth {
+	case "", analyzer.WETHFold, analyzer.WETHLabel:
+		opts.WETH = weth
+	default:
+		return opts, fmt.Errorf("weth must be 'fold' or 'label'")
+	}
+
+	return opts, nil
+}
+
+// parseBlockParam parses an optional non-negative block number parameter
+func parseBlockParam(value, name string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	block, err := strconv.Atoi(value)
+	if err != nil || block < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative block number", name)
+	}
+	return block, nil
+}
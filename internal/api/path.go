@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// PathResponse represents the response format for the path endpoint
+type PathResponse struct {
+	Message   string         `json:"message"`
+	RequestID string         `json:"request_id,omitempty"`
+	Data      *analyzer.Path `json:"data"`
+}
+
+// HandlePath handles the /path endpoint, searching the shortest chain of beneficiaries from one
+// address to another
+func (h *Handler) HandlePath(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "from and to parameters are required")
+		return
+	}
+	if !etherscan.IsValidAddress(from) || !etherscan.IsValidAddress(to) {
+		h.respondWithError(w, r, http.StatusBadRequest, "from and to must be Ethereum addresses")
+		return
+	}
+	if strings.EqualFold(from, to) {
+		h.respondWithError(w, r, http.StatusBadRequest, "from and to must be different addresses")
+		return
+	}
+
+	depth, err := h.parseTraceDepth(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Searching path from %s to %s within %d hops", from, to, depth)
+
+	path, err := h.beneficiaryAnalyzer.FindPath(from, to, depth, h.config.MaxTraceNodes, opts)
+	if err != nil {
+		log.Errorf("Error searching path: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+	if path.Truncated {
+		log.Warnf("Path search from %s stopped after %d addresses (MAX_TRACE_NODES)", from, path.NodesVisited)
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, PathResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Data:      path,
+	})
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// ProfileResponse represents the response format for the profile endpoint
+type ProfileResponse struct {
+	Message   string            `json:"message"`
+	RequestID string            `json:"request_id,omitempty"`
+	Data      *analyzer.Profile `json:"data"`
+}
+
+// HandleProfile handles the /profile endpoint
+func (h *Handler) HandleProfile(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "address parameter is required")
+		return
+	}
+	if !etherscan.IsValidAddress(address) {
+		h.respondWithError(w, r, http.StatusBadRequest, "address must be an Ethereum address")
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Building profile for address: %s", address)
+
+	profile, err := h.flowAnalyzer.AnalyzeProfile(address, opts)
+	if err != nil {
+		log.Errorf("Error building profile: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, ProfileResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Data:      profile,
+	})
+}
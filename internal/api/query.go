@@ -0,0 +1,78 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// hexParams are the query parameters holding an address or transaction hash, which consist of
+// hex digits after the 0x prefix
+var hexParams = map[string]bool{
+	"address": true,
+	"a":       true,
+	"b":       true,
+	"from":    true,
+	"to":      true,
+	"hash":    true,
+}
+
+// listParams are the query parameters holding a comma-separated list of addresses
+var listParams = map[string]bool{
+	"exclude": true,
+}
+
+// queryMiddleware rejects requests whose query string exceeds MAX_QUERY_LENGTH, is malformed
+// or holds characters its parameters never contain, before any Etherscan work is done
+func (r *Router) queryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := checkQuery(req.URL.RawQuery, r.config.MaxQueryLength); err != nil {
+			r.handler.respondWithError(w, req, http.StatusBadRequest, err.Error())
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// checkQuery validates a raw query string against the length limit (0 means unlimited) and the
+// characters each parameter may hold
+func checkQuery(rawQuery string, maxLength int) error {
+	if maxLength > 0 && len(rawQuery) > maxLength {
+		return fmt.Errorf("query string exceeds maximum of %d characters", maxLength)
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return fmt.Errorf("malformed query string")
+	}
+
+	for name, values := range query {
+		for _, value := range values {
+			var valid bool
+			switch {
+			case hexParams[name]:
+				valid = strings.IndexFunc(value, func(c rune) bool { return !isHexDigit(c) && c != 'x' && c != 'X' }) < 0
+			case listParams[name]:
+				valid = strings.IndexFunc(value, func(c rune) bool { return !isHexDigit(c) && !strings.ContainsRune("xX, ", c) }) < 0
+			default:
+				valid = strings.IndexFunc(value, isSuspicious) < 0
+			}
+			if !valid {
+				return fmt.Errorf("%s contains invalid characters", name)
+			}
+		}
+	}
+	return nil
+}
+
+// isHexDigit reports whether c is a hex digit
+func isHexDigit(c rune) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// isSuspicious reports whether c is a control character or markup/quoting character, which no
+// parameter value contains
+func isSuspicious(c rune) bool {
+	return c < 0x20 || c == 0x7f || strings.ContainsRune("<>\"'`\\", c)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// QuotaResponse represents the response format for the quota endpoint
+type QuotaResponse struct {
+	Message          string  `json:"message"`
+	RequestID        string  `json:"request_id,omitempty"`
+	CallsToday       int     `json:"calls_today"`
+	DailyBudget      int     `json:"daily_budget,omitempty"`
+	PercentRemaining float64 `json:"percent_remaining,omitempty"`
+	ResetsAt         string  `json:"resets_at"`
+
+	// Providers is the health of the base URL and each ETHERSCAN_FALLBACK_URLS provider
+	Providers []etherscan.ProviderStatus `json:"providers,omitempty"`
+}
+
+// HandleQuota handles the /quota endpoint, reporting today's Etherscan usage against the configured budget
+func (h *Handler) HandleQuota(w http.ResponseWriter, r *http.Request) {
+	calls := h.etherscanClient.CallsToday()
+	budget := h.config.DailyCallBudget
+
+	response := QuotaResponse{
+		Message:     "success",
+		RequestID:   requestIDFromContext(r.Context()),
+		CallsToday:  calls,
+		DailyBudget: budget,
+		ResetsAt:    time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Format(time.RFC3339),
+		Providers:   h.etherscanClient.ProviderStatus(),
+	}
+	if budget > 0 && calls < budget {
+		response.PercentRemaining = float64(budget-calls) / float64(budget) * 100
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, response)
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// recoveryMiddleware turns a panic in a handler into a 500 JSON error instead of crashing the
+// server, logging the panic and its stack trace with the request ID. It runs inside the gzip
+// middleware so the error response is encoded like any other.
+func (r *Router) recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// Deliberate aborts of a response are left to net/http
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			requestLogger(r.logger, req).
+				WithField("stack", string(debug.Stack())).
+				Errorf("Panic serving %s %s: %v", req.Method, req.URL.Path, recovered)
+			r.handler.respondWithError(w, req, http.StatusInternalServerError, "internal server error")
+		}()
+
+		next.ServeHTTP(w, req)
+	})
+}
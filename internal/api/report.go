@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/version"
+)
+
+// Algorithms of a report's digest and signature
+const (
+	reportDigestAlgorithm    = "sha256"
+	reportSignatureAlgorithm = "hmac-sha256"
+)
+
+// AnalysisReport is the response for format=report: the analysis result with metadata in
+// Report, and a digest and optional signature of Report's canonical JSON encoding (object keys
+// sorted, no whitespace, no HTML escaping) so any alteration can be detected
+type AnalysisReport struct {
+	Message   string          `json:"message"`
+	RequestID string          `json:"request_id,omitempty"`
+	Report    json.RawMessage `json:"report"`
+	Digest    ReportHash      `json:"digest"`
+	Signature *ReportHash     `json:"signature,omitempty"`
+}
+
+// ReportHash is a hex-encoded digest or signature with its algorithm
+type ReportHash struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// reportContent is the signed content of a report
+type reportContent struct {
+	Metadata ReportMetadata `json:"metadata"`
+	Result   interface{}    `json:"result"`
+}
+
+// ReportMetadata describes how and when a report's result was produced
+type ReportMetadata struct {
+	Endpoint    string `json:"endpoint"`
+	Address     string `json:"address"`
+	Query       string `json:"query"`
+	GeneratedAt string `json:"generated_at"`
+	LatestBlock int    `json:"latest_block"`
+	ToolVersion string `json:"tool_version"`
+}
+
+// respondWithReport wraps an analysis response in a tamper-evident report, signed when
+// REPORT_HMAC_KEY is configured. Reports are always snake_case, whatever X-Field-Naming asks for.
+func (h *Handler) respondWithReport(w http.ResponseWriter, r *http.Request, endpoint, address string, result interface{}) {
+	latestBlock, err := h.etherscanClient.GetLatestBlockNumber()
+	if err != nil {
+		requestLogger(h.logger, r).Errorf("Error fetching latest block for report: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	content := reportContent{
+		Metadata: ReportMetadata{
+			Endpoint:    endpoint,
+			Address:     address,
+			Query:       r.URL.RawQuery,
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+			LatestBlock: latestBlock,
+			ToolVersion: version.Version,
+		},
+		Result: result,
+	}
+	canonical, err := canonicalJSON(content)
+	if err != nil {
+		requestLogger(h.logger, r).Errorf("Error encoding report: %v", err)
+		h.respondWithError(w, r, http.StatusInternalServerError, "Error creating response")
+		return
+	}
+
+	digest := sha256.Sum256(canonical)
+	report := AnalysisReport{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Report:    canonical,
+		Digest:    ReportHash{Algorithm: reportDigestAlgorithm, Value: hex.EncodeToString(digest[:])},
+	}
+	if h.config.ReportHMACKey != "" {
+		mac := hmac.New(sha256.New, []byte(h.config.ReportHMACKey))
+		mac.Write(canonical)
+		report.Signature = &ReportHash{Algorithm: reportSignatureAlgorithm, Value: hex.EncodeToString(mac.Sum(nil))}
+	}
+
+	// Encoded without HTML escaping so the report's bytes are exactly the canonical ones hashed
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(report); err != nil {
+		requestLogger(h.logger, r).Errorf("Error marshaling response: %v", err)
+		h.respondWithError(w, r, http.StatusInternalServerError, "Error creating response")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// canonicalJSON encodes v with object keys sorted, no insignificant whitespace and no HTML
+// escaping, so a verifier re-encoding the report the same way gets the same bytes. Numbers
+// are kept exactly as encoded.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("error decoding report: %w", err)
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(tree); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
@@ -4,28 +4,43 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
 	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
 )
 
 // Router is the HTTP router
 type Router struct {
+	config         *config.Config
 	handler        *Handler
 	logger         logger.Logger
 	defaultAddress string
-	analysisMode   string
+
+	// analyses limits the requests analyzed at once and subscriptions the open /subscribe
+	// connections (nil means unlimited)
+	analyses      *analysisLimiter
+	subscriptions *analysisLimiter
+
+	// analysisMode can be changed at runtime through /admin/mode
+	modeMu       sync.RWMutex
+	analysisMode string
 }
 
 // NewRouter creates a new router
-func NewRouter(beneficiaryAnalyzer *analyzer.BeneficiaryAnalyzer, payerAnalyzer *analyzer.PayerAnalyzer, logger logger.Logger) *Router {
-	handler := NewHandler(beneficiaryAnalyzer, payerAnalyzer, logger)
+func NewRouter(config *config.Config, etherscanClient *etherscan.Client, beneficiaryAnalyzer *analyzer.BeneficiaryAnalyzer, payerAnalyzer *analyzer.PayerAnalyzer, flowAnalyzer *analyzer.FlowAnalyzer, logger logger.Logger) *Router {
+	handler := NewHandler(config, etherscanClient, beneficiaryAnalyzer, payerAnalyzer, flowAnalyzer, logger)
 	return &Router{
+		config:         config,
 		handler:        handler,
 		logger:         logger,
 		defaultAddress: "",
-		analysisMode:   "both",
+		analyses:       newAnalysisLimiter(config.MaxConcurrentAnalyses),
+		subscriptions:  newAnalysisLimiter(config.MaxSubscriptions),
+		analysisMode:   config.AnalysisMode,
 	}
 }
 
@@ -36,16 +51,46 @@ func (r *Router) SetDefaultAddress(address string) {
 
 // SetAnalysisMode sets the analysis mode
 func (r *Router) SetAnalysisMode(mode string) {
+	r.modeMu.Lock()
+	defer r.modeMu.Unlock()
 	r.analysisMode = mode
 }
 
+// mode returns the current analysis mode
+func (r *Router) mode() string {
+	r.modeMu.RLock()
+	defer r.modeMu.RUnlock()
+	return r.analysisMode
+}
+
 // Setup sets up the HTTP routes
 func (r *Router) Setup() *mux.Router {
 	router := mux.NewRouter()
 
-	// API routes
-	router.HandleFunc("/beneficiary", r.handler.HandleBeneficiary).Methods("GET")
-	router.HandleFunc("/payer", r.handler.HandlePayer).Methods("GET")
+	// API routes (protected by bearer auth when configured and limited to MAX_CONCURRENT_ANALYSES
+	// at once; long-lived subscriptions are not counted)
+	router.Handle("/beneficiary", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleBeneficiary)))).Methods("GET")
+	router.Handle("/payer", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandlePayer)))).Methods("GET")
+	router.Handle("/counterparty", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleCounterparty)))).Methods("GET")
+	router.Handle("/transactions", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleTransactions)))).Methods("GET")
+	router.Handle("/tx", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleTransaction)))).Methods("GET")
+	router.Handle("/tx/status", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleTransactionStatus)))).Methods("GET")
+	router.Handle("/profile", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleProfile)))).Methods("GET")
+	router.Handle("/timeseries", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleTimeSeries)))).Methods("GET")
+	router.Handle("/batch/beneficiary", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleBatchBeneficiary)))).Methods("POST")
+	router.Handle("/batch/tx", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleBatchTransaction)))).Methods("POST")
+	router.Handle("/graphql", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleGraphQL)))).Methods("POST")
+	router.Handle("/compare", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleCompare)))).Methods("GET")
+	router.Handle("/trace", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleTrace)))).Methods("GET")
+	router.Handle("/trace/resume", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandleTraceResume)))).Methods("POST")
+	router.Handle("/path", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handler.HandlePath)))).Methods("GET")
+	router.Handle("/diff", r.authMiddleware(r.limitAnalyses(http.HandlerFunc(r.handleDiff)))).Methods("POST")
+	router.Handle("/subscribe", r.authMiddleware(http.HandlerFunc(r.handleSubscribe))).Methods("GET")
+
+	// Runtime administration affects every client, so it only exists behind API_AUTH_TOKEN
+	if r.config.APIAuthToken != "" {
+		router.Handle("/admin/mode", r.authMiddleware(http.HandlerFunc(r.handleSetMode))).Methods("POST")
+	}
 
 	// Root handler
 	router.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
@@ -187,7 +232,7 @@ func (r *Router) Setup() *mux.Router {
   ./bin/api -help</pre>
 </body>
 </html>
-`, r.analysisMode, addressInfo, exampleAddress, exampleAddress, exampleAddress, exampleAddress)
+`, r.mode(), addressInfo, exampleAddress, exampleAddress, exampleAddress, exampleAddress)
 
 		tmpl, err := template.New("home").Parse(html)
 		if err != nil {
@@ -205,17 +250,27 @@ func (r *Router) Setup() *mux.Router {
 		w.Write([]byte("OK"))
 	}).Methods("GET")
 
+	// Readiness check (verifies the Etherscan API key)
+	router.HandleFunc("/ready", r.handler.HandleReady).Methods("GET")
+
+	// Etherscan quota usage
+	router.HandleFunc("/quota", r.handler.HandleQuota).Methods("GET")
+
+	// OpenAPI document describing the analysis endpoints
+	router.HandleFunc("/openapi.json", r.handler.HandleOpenAPI).Methods("GET")
+
 	// Analyze default route
 	if r.defaultAddress != "" {
 		router.HandleFunc("/analyze-default", func(w http.ResponseWriter, req *http.Request) {
 			// Generate the HTML buttons based on analysis mode
 			var beneficiaryButton, payerButton string
+			mode := r.mode()
 			
-			if r.analysisMode == "beneficiary" || r.analysisMode == "both" {
+			if mode == "beneficiary" || mode == "both" {
 				beneficiaryButton = fmt.Sprintf(`<a href="/beneficiary?address=%s" class="button">Analyze Beneficiaries</a>`, r.defaultAddress)
 			}
 			
-			if r.analysisMode == "payer" || r.analysisMode == "both" {
+			if mode == "payer" || mode == "both" {
 				payerButton = fmt.Sprintf(`<a href="/payer?address=%s" class="button">Analyze Payers</a>`, r.defaultAddress)
 			}
 
@@ -265,23 +320,28 @@ func (r *Router) Setup() *mux.Router {
     <p><a href="/">Back to Home</a></p>
 </body>
 </html>
-`, r.defaultAddress, r.analysisMode, beneficiaryButton, payerButton)
+`, r.defaultAddress, mode, beneficiaryButton, payerButton)
 
 			w.Header().Set("Content-Type", "text/html")
 			w.Write([]byte(html))
 		}).Methods("GET")
 	}
 
-	// Log requests
+	// Unknown paths and methods get the same JSON error shape as every other failure. Route
+	// middleware doesn't run for them, so they are tagged with a request ID here.
+	router.NotFoundHandler = r.requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.handler.respondWithError(w, req, http.StatusNotFound, "not found: "+req.URL.Path)
+	}))
+	router.MethodNotAllowedHandler = r.requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.handler.respondWithError(w, req, http.StatusMethodNotAllowed, "method "+req.Method+" not allowed for "+req.URL.Path)
+	}))
+
+	// Tag requests with an ID, log them, compress large responses and recover from panics
+	router.Use(r.requestIDMiddleware)
 	router.Use(r.loggingMiddleware)
+	router.Use(r.queryMiddleware)
+	router.Use(r.gzipMiddleware)
+	router.Use(r.recoveryMiddleware)
 
 	return router
 }
-
-// loggingMiddleware logs HTTP requests
-func (r *Router) loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		r.logger.Infof("Request: %s %s", req.Method, req.URL.Path)
-		next.ServeHTTP(w, req)
-	})
-}
\ No newline at end of file
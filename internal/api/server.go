@@ -1,46 +1,127 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/gorilla/mux"
 	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
 	"github.com/shrxyeh/ethereum-fund-flow/internal/config"
 	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/price"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/storage"
 	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
 )
 
 // Server is the API server
 type Server struct {
-	config       *config.Config
-	logger       logger.Logger
-	router       *Router
-	defaultAddr  string
-	analysisMode string
+	config          *config.Config
+	etherscanClient *etherscan.Client
+	logger          logger.Logger
+	router          *Router
+	defaultAddr     string
+	analysisMode    string
 }
 
 // NewServer creates a new server
 func NewServer(config *config.Config, logger logger.Logger) *Server {
 	// Create Etherscan client
-	etherscanClient := etherscan.NewClient(config.EtherscanAPIKey)
+	etherscanClient := etherscan.NewClient(config.EtherscanAPIKey, config.EtherscanBaseURL)
+	etherscanClient.SetMaxConcurrentRequests(config.MaxConcurrentRequests)
+	etherscanClient.SetLogger(logger)
+	etherscanClient.SetSlowCallThreshold(config.SlowCallThreshold)
+	etherscanClient.SetProxyRetry(config.ProxyMaxAttempts, config.ProxyTimeout)
+	etherscanClient.SetNativeDecimals(config.NativeDecimals)
+	etherscanClient.SetUserAgent(config.EtherscanUserAgent)
+	if len(config.EtherscanFallbackURLs) > 0 {
+		var fallbacks []etherscan.TransactionProvider
+		for _, fallbackURL := range config.EtherscanFallbackURLs {
+			explorer, err := etherscan.ParseExplorer(fallbackURL)
+			if err != nil {
+				logger.Warnf("Etherscan fallback not used: %v", err)
+				continue
+			}
+			fallbacks = append(fallbacks, explorer)
+		}
+		etherscanClient.SetFallbackProviders(fallbacks...)
+	}
+	if config.EtherscanHTTPProxy != "" {
+		if err := etherscanClient.SetHTTPProxy(config.EtherscanHTTPProxy); err != nil {
+			logger.Warnf("Etherscan proxy not used: %v", err)
+		}
+	}
+
+	// Persist fetched transaction lists when a cache database is configured
+	if config.CacheDBPath != "" {
+		cache, err := storage.OpenSQLiteCache(config.CacheDBPath)
+		if err != nil {
+			logger.Warnf("Transaction cache disabled: %v", err)
+		} else {
+			etherscanClient.SetCache(cache, config.CacheTTL)
+		}
+	}
 
 	// Create analyzers
 	beneficiaryAnalyzer := analyzer.NewBeneficiaryAnalyzer(etherscanClient)
 	payerAnalyzer := analyzer.NewPayerAnalyzer(etherscanClient)
+	flowAnalyzer := analyzer.NewFlowAnalyzer(etherscanClient)
+	beneficiaryAnalyzer.SetStablecoins(config.Stablecoins)
+	payerAnalyzer.SetStablecoins(config.Stablecoins)
+	beneficiaryAnalyzer.SetSpamContracts(config.SpamContracts)
+	payerAnalyzer.SetSpamContracts(config.SpamContracts)
+	payerAnalyzer.SetProxyContracts(config.ProxyContracts)
+	beneficiaryAnalyzer.SetWETHContract(config.WETHContract)
+	payerAnalyzer.SetWETHContract(config.WETHContract)
+	beneficiaryAnalyzer.SetOwnAddresses(config.OwnAddresses)
+	payerAnalyzer.SetOwnAddresses(config.OwnAddresses)
+	beneficiaryAnalyzer.SetExchangeAddresses(config.ExchangeAddresses)
+	beneficiaryAnalyzer.SetSanctionedAddresses(config.SanctionedAddresses)
+	payerAnalyzer.SetSanctionedAddresses(config.SanctionedAddresses)
+	beneficiaryAnalyzer.SetDisabledTypes(disabledTypes(config))
+	payerAnalyzer.SetDisabledTypes(disabledTypes(config))
+	flowAnalyzer.SetDisabledTypes(disabledTypes(config))
+	beneficiaryAnalyzer.SetBudget(config.AnalysisTimeout, config.AnalysisMaxRetries)
+	payerAnalyzer.SetBudget(config.AnalysisTimeout, config.AnalysisMaxRetries)
+
+	// Historical prices for usd=true, shared so both analyzers use one cache
+	prices := newPriceProvider(config)
+	beneficiaryAnalyzer.SetPriceProvider(prices)
+	payerAnalyzer.SetPriceProvider(prices)
 
 	// Create router
-	router := NewRouter(beneficiaryAnalyzer, payerAnalyzer, logger)
+	router := NewRouter(config, etherscanClient, beneficiaryAnalyzer, payerAnalyzer, flowAnalyzer, logger)
 
 	return &Server{
-		config:       config,
-		logger:       logger,
-		router:       router,
-		defaultAddr:  "",
-		analysisMode: "both",
+		config:          config,
+		etherscanClient: etherscanClient,
+		logger:          logger,
+		router:          router,
+		defaultAddr:     "",
+		analysisMode:    config.AnalysisMode,
 	}
 }
 
+// disabledTypes returns the transaction types ENABLE_INTERNAL and ENABLE_TOKEN turn off
+func disabledTypes(cfg *config.Config) map[string]bool {
+	disabled := make(map[string]bool)
+	if !cfg.EnableInternal {
+		disabled[analyzer.MovementInternal] = true
+	}
+	if !cfg.EnableToken {
+		disabled[analyzer.MovementToken] = true
+	}
+	return disabled
+}
+
+// newPriceProvider creates the configured historical price feed, or nil when it is disabled
+func newPriceProvider(cfg *config.Config) price.Provider {
+	if cfg.PriceFeed != config.PriceFeedCoinGecko {
+		return nil
+	}
+	return price.NewCache(price.NewCoinGecko(cfg.CoinGeckoBaseURL, cfg.CoinGeckoAPIKey))
+}
+
 // SetDefaultAddress sets the default Ethereum address for analysis
 func (s *Server) SetDefaultAddress(address string) {
 	s.defaultAddr = address
@@ -56,11 +137,56 @@ func (s *Server) Start() error {
 	// Pass default address and mode to the router
 	s.router.SetDefaultAddress(s.defaultAddr)
 	s.router.SetAnalysisMode(s.analysisMode)
-	
+
+	// Fail fast when Etherscan rejects the API key; other failures, such as Etherscan being
+	// unreachable, only warn so the server can start and recover once it is back
+	if err := s.etherscanClient.ValidateAPIKey(); err != nil {
+		if errors.Is(err, etherscan.ErrInvalidAPIKey) {
+			return fmt.Errorf("Etherscan API key rejected, check ETHERSCAN_API_KEY: %w", err)
+		}
+		s.logger.Warnf("Etherscan API key validation failed: %v", err)
+	}
+
 	// Setup the router
 	r := s.router.Setup()
-	
+
 	addr := fmt.Sprintf(":%s", s.config.Port)
 	s.logger.Infof("Starting server with address: %s and mode: %s", s.defaultAddr, s.analysisMode)
-	return http.ListenAndServe(addr, r)
-}
\ No newline at end of file
+	return http.ListenAndServe(addr, s.router.corsMiddleware(r))
+}
+
+// AnalysisResult is the result of a one-shot analysis run from the command line
+type AnalysisResult struct {
+	Address       string            `json:"address"`
+	Mode          string            `json:"mode"`
+	Beneficiaries []BeneficiaryData `json:"beneficiaries,omitempty"`
+	Payers        []PayerData       `json:"payers,omitempty"`
+}
+
+// Analyze runs the analyses of the current mode on an address without starting the server,
+// capping the results like the HTTP endpoints do
+func (s *Server) Analyze(address string) (*AnalysisResult, error) {
+	handler := s.router.handler
+	opts := analyzer.Options{Location: time.UTC}
+	result := &AnalysisResult{Address: address, Mode: s.analysisMode}
+
+	if s.analysisMode == "beneficiary" || s.analysisMode == "both" {
+		beneficiaries, err := handler.beneficiaryAnalyzer.AnalyzeBeneficiary(address, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error analyzing beneficiaries: %w", err)
+		}
+		beneficiaries, _ = analyzer.CapBeneficiaries(beneficiaries, s.config.MaxCounterparties, s.config.MaxTxPerCounterparty, analyzer.KeepLargest)
+		result.Beneficiaries = toBeneficiaryData(beneficiaries, handler.amountFormat(false))
+	}
+
+	if s.analysisMode == "payer" || s.analysisMode == "both" {
+		payers, err := handler.payerAnalyzer.AnalyzePayer(address, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error analyzing payers: %w", err)
+		}
+		payers, _ = analyzer.CapPayers(payers, s.config.MaxCounterparties, s.config.MaxTxPerCounterparty, analyzer.KeepLargest)
+		result.Payers = toPayerData(payers, handler.amountFormat(false))
+	}
+
+	return result, nil
+}
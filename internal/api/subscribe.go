@@ -0,0 +1,228 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
+)
+
+// subscribeWriteTimeout bounds how long a push may block on a slow subscriber
+const subscribeWriteTimeout = 10 * time.Second
+
+// Subscription event types
+const (
+	eventSubscribed = "subscribed"
+	eventUpdate     = "update"
+	eventError      = "error"
+)
+
+// SubscriptionEvent is a message pushed to /subscribe clients
+type SubscriptionEvent struct {
+	Type          string            `json:"type"`
+	Address       string            `json:"address"`
+	FromBlock     int               `json:"from_block,omitempty"`
+	ToBlock       int               `json:"to_block,omitempty"`
+	Beneficiaries []BeneficiaryData `json:"beneficiaries,omitempty"`
+	Payers        []PayerData       `json:"payers,omitempty"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// subscription tracks one WebSocket client monitoring an address
+type subscription struct {
+	conn      *websocket.Conn
+	address   string
+	opts      analyzer.Options
+	precise   bool
+	naming    string
+	lastBlock int
+	log       logger.Logger
+}
+
+// handleSubscribe handles the /subscribe endpoint. It upgrades the connection to a WebSocket
+// and, every SUBSCRIBE_POLL_INTERVAL, analyzes the blocks mined since the last check, pushing
+// the beneficiary and/or payer entries they contain (per the current analysis mode).
+func (r *Router) handleSubscribe(w http.ResponseWriter, req *http.Request) {
+	address := req.URL.Query().Get("address")
+	if address == "" {
+		r.handler.respondWithError(w, req, http.StatusBadRequest, "address parameter is required")
+		return
+	}
+	if !etherscan.IsValidAddress(address) {
+		r.handler.respondWithError(w, req, http.StatusBadRequest, "address must be an Ethereum address")
+		return
+	}
+
+	opts, err := parseAnalysisOptions(req)
+	if err != nil {
+		r.handler.respondWithError(w, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// A subscription holds its slot while connected, so further ones are refused rather than queued
+	if r.subscriptions != nil {
+		if !r.subscriptions.tryAcquire() {
+			requestLogger(r.logger, req).Warnf("Refusing subscription for %s: %d subscriptions already open", address, cap(r.subscriptions.slots))
+			w.Header().Set("Retry-After", strconv.Itoa(analysisRetryAfter))
+			r.handler.respondWithError(w, req, http.StatusTooManyRequests, "too many subscriptions open, retry later")
+			return
+		}
+		defer r.subscriptions.release()
+	}
+
+	// Only blocks mined after the subscription starts are reported
+	latest, err := r.handler.etherscanClient.GetLatestBlockNumber()
+	if err != nil {
+		requestLogger(r.logger, req).Errorf("Error fetching latest block: %v", err)
+		r.handler.respondWithAnalysisError(w, req, err)
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: r.websocketOriginAllowed}
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		// The upgrader has already replied with an HTTP error
+		return
+	}
+	defer conn.Close()
+
+	sub := &subscription{
+		conn:      conn,
+		address:   address,
+		opts:      opts,
+		precise:   req.URL.Query().Get("precise") == "true",
+		naming:    r.handler.fieldNaming(req),
+		lastBlock: latest,
+		log:       requestLogger(r.logger, req),
+	}
+	sub.log.Infof("Subscription started for %s at block %d", address, latest)
+
+	// Read (and discard) client messages so close frames are processed; a read error means the client is gone
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if err := sub.send(SubscriptionEvent{Type: eventSubscribed, Address: address, ToBlock: latest}); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(r.config.SubscribePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			sub.log.Infof("Subscription ended for %s", address)
+			return
+		case <-ticker.C:
+			if err := r.poll(sub); err != nil {
+				sub.log.Warnf("Subscription for %s closed: %v", address, err)
+				return
+			}
+		}
+	}
+}
+
+// poll analyzes the blocks mined since the subscription's last-seen block and pushes any
+// entries found. Analysis errors are reported to the client and the same range is retried on
+// the next poll; only a failed write ends the subscription. Like an analysis request, a poll
+// needs a MAX_CONCURRENT_ANALYSES slot; when none is free it is skipped and its blocks are
+// analyzed by the next poll.
+func (r *Router) poll(sub *subscription) error {
+	if r.analyses != nil {
+		if !r.analyses.tryAcquire() {
+			sub.log.Warnf("Skipping poll for %s: %d analyses already in progress", sub.address, cap(r.analyses.slots))
+			return nil
+		}
+		defer r.analyses.release()
+	}
+
+	latest, err := r.handler.etherscanClient.GetLatestBlockNumber()
+	if err != nil {
+		return sub.sendError(err)
+	}
+	from, to, ok := sub.pollRange(latest)
+	if !ok {
+		return nil
+	}
+
+	opts := sub.opts
+	opts.FromBlock = from
+	opts.ToBlock = to
+
+	event := SubscriptionEvent{Type: eventUpdate, Address: sub.address, FromBlock: opts.FromBlock, ToBlock: opts.ToBlock}
+	mode := r.mode()
+
+	if mode == "beneficiary" || mode == "both" {
+		beneficiaries, err := r.handler.beneficiaryAnalyzer.AnalyzeBeneficiary(sub.address, opts)
+		if err != nil {
+			return sub.sendError(err)
+		}
+		event.Beneficiaries = toBeneficiaryData(beneficiaries, r.handler.amountFormat(sub.precise))
+	}
+
+	if mode == "payer" || mode == "both" {
+		payers, err := r.handler.payerAnalyzer.AnalyzePayer(sub.address, opts)
+		if err != nil {
+			return sub.sendError(err)
+		}
+		event.Payers = toPayerData(payers, r.handler.amountFormat(sub.precise))
+	}
+
+	sub.lastBlock = to
+	if len(event.Beneficiaries) == 0 && len(event.Payers) == 0 {
+		return nil
+	}
+	return sub.send(event)
+}
+
+// pollRange returns the blocks a poll at the given latest block analyzes: those after the last
+// one analyzed, up to the last with MinConfirmations confirmations. Stopping there, rather than
+// at the latest block, leaves blocks too recent for min_confirmations to a later poll instead
+// of skipping their transactions for good. ok is false when there are no such blocks yet.
+func (sub *subscription) pollRange(latest int) (from, to int, ok bool) {
+	to = latest
+	if sub.opts.MinConfirmations > 0 {
+		to = latest - sub.opts.MinConfirmations + 1
+	}
+	if to <= sub.lastBlock {
+		return 0, 0, false
+	}
+	return sub.lastBlock + 1, to, true
+}
+
+// send writes an event to the subscriber
+func (sub *subscription) send(event SubscriptionEvent) error {
+	data, err := marshalJSON(event, sub.naming)
+	if err != nil {
+		return err
+	}
+	sub.conn.SetWriteDeadline(time.Now().Add(subscribeWriteTimeout))
+	return sub.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// sendError reports a failed poll to the subscriber
+func (sub *subscription) sendError(err error) error {
+	sub.log.Errorf("Error polling subscription for %s: %v", sub.address, err)
+	return sub.send(SubscriptionEvent{Type: eventError, Address: sub.address, Error: err.Error()})
+}
+
+// websocketOriginAllowed accepts same-origin connections, clients that send no Origin
+// header, and origins listed in CORS_ORIGINS
+func (r *Router) websocketOriginAllowed(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" || origin == "http://"+req.Host || origin == "https://"+req.Host {
+		return true
+	}
+	return r.originAllowed(origin)
+}
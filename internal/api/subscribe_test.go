@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
+)
+
+func TestSubscribeRefusedWhenSubscriptionsFull(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxSubscriptions = 1
+	client := etherscan.NewClient("TESTKEY", "http://127.0.0.1:0")
+	router := NewRouter(cfg, client, analyzer.NewBeneficiaryAnalyzer(client), analyzer.NewPayerAnalyzer(client),
+		analyzer.NewFlowAnalyzer(client), logger.NewLogger())
+
+	// An open subscription holds the only slot
+	if !router.subscriptions.tryAcquire() {
+		t.Fatal("no subscription slot free")
+	}
+
+	rec := httptest.NewRecorder()
+	router.Setup().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscribe?address="+testAddress, nil))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("status %d, Retry-After %q, want 429 with Retry-After", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestPollRangeWaitsForConfirmations(t *testing.T) {
+	sub := &subscription{opts: analyzer.Options{MinConfirmations: 3}, lastBlock: 100}
+
+	// Block 101 has only 2 confirmations at block 102, counting the block it was mined in
+	if from, to, ok := sub.pollRange(102); ok {
+		t.Fatalf("pollRange(102) = %d-%d, want nothing confirmed yet", from, to)
+	}
+
+	from, to, ok := sub.pollRange(105)
+	if !ok || from != 101 || to != 103 {
+		t.Fatalf("pollRange(105) = %d-%d, %v, want 101-103", from, to, ok)
+	}
+	sub.lastBlock = to
+
+	// The next poll picks up the blocks that were too recent, and nothing twice
+	if from, to, ok := sub.pollRange(107); !ok || from != 104 || to != 105 {
+		t.Errorf("pollRange(107) = %d-%d, %v, want 104-105", from, to, ok)
+	}
+}
+
+func TestPollRangeWithoutConfirmations(t *testing.T) {
+	sub := &subscription{lastBlock: 100}
+
+	if _, _, ok := sub.pollRange(100); ok {
+		t.Error("pollRange(100) found blocks, want none mined")
+	}
+	if from, to, ok := sub.pollRange(102); !ok || from != 101 || to != 102 {
+		t.Errorf("pollRange(102) = %d-%d, %v, want 101-102", from, to, ok)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+)
+
+// summaryByCategory is the summary mode totaling beneficiaries by label category
+const summaryByCategory = "by_category"
+
+// CategorySummaryResponse represents the response format for the beneficiary endpoint with summary=by_category
+type CategorySummaryResponse struct {
+	Message   string             `json:"message"`
+	RequestID string             `json:"request_id,omitempty"`
+	Data      map[string]Decimal `json:"data"`
+
+	// PrecisionWarning lists the categories whose amount exceeded PRECISION_LIMIT and is written exactly
+	PrecisionWarning []string `json:"precision_warning,omitempty"`
+
+	// HasSanctionedInteraction is set when SANCTIONS_LIST_PATH is configured
+	HasSanctionedInteraction *bool `json:"has_sanctioned_interaction,omitempty"`
+}
+
+// respondWithCategorySummary totals the beneficiaries by the label category of each counterparty.
+// Totals cover every beneficiary matching the filters, so top and the result caps don't apply.
+func (h *Handler) respondWithCategorySummary(w http.ResponseWriter, r *http.Request, beneficiaries []analyzer.Beneficiary, sanctioned bool) {
+	format := h.amountFormat(r.URL.Query().Get("precise") == "true")
+	response := CategorySummaryResponse{
+		Message:                  "success",
+		RequestID:                requestIDFromContext(r.Context()),
+		Data:                     make(map[string]Decimal),
+		HasSanctionedInteraction: h.sanctionScreening(sanctioned),
+	}
+
+	for _, total := range h.beneficiaryAnalyzer.SummarizeByCategory(beneficiaries) {
+		amount := newDecimal(total.Amount, total.RawAmount, format)
+		if amount.imprecise {
+			response.PrecisionWarning = append(response.PrecisionWarning, total.Category)
+		}
+		response.Data[total.Category] = amount
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, response)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// TimeSeriesResponse represents the response format for the timeseries endpoint
+type TimeSeriesResponse struct {
+	Message   string                `json:"message"`
+	RequestID string                `json:"request_id,omitempty"`
+	Interval  analyzer.Interval     `json:"interval"`
+	Data      []analyzer.TimeBucket `json:"data"`
+}
+
+// HandleTimeSeries handles the /timeseries endpoint
+func (h *Handler) HandleTimeSeries(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "address parameter is required")
+		return
+	}
+	if !etherscan.IsValidAddress(address) {
+		h.respondWithError(w, r, http.StatusBadRequest, "address must be an Ethereum address")
+		return
+	}
+
+	intervalParam := r.URL.Query().Get("interval")
+	if intervalParam == "" {
+		intervalParam = string(analyzer.IntervalDay)
+	}
+	interval, err := analyzer.ParseInterval(intervalParam)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Analyzing %s time series for address: %s", interval, address)
+
+	buckets, err := h.flowAnalyzer.AnalyzeTimeSeries(address, interval, opts)
+	if err != nil {
+		log.Errorf("Error analyzing time series: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, TimeSeriesResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Interval:  interval,
+		Data:      buckets,
+	})
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// defaultTraceDepth is the number of hops traced when the request doesn't specify a depth
+const defaultTraceDepth = 2
+
+// TraceResponse represents the response format for the trace endpoint
+type TraceResponse struct {
+	Message   string          `json:"message"`
+	RequestID string          `json:"request_id,omitempty"`
+	Data      *analyzer.Trace `json:"data"`
+}
+
+// TraceResumeRequest represents the request body for the trace resume endpoint
+type TraceResumeRequest struct {
+	State           *analyzer.TraceState `json:"state"`
+	AdditionalDepth int                  `json:"additional_depth"`
+}
+
+// HandleTrace handles the /trace endpoint
+func (h *Handler) HandleTrace(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "address parameter is required")
+		return
+	}
+	if !etherscan.IsValidAddress(address) {
+		h.respondWithError(w, r, http.StatusBadRequest, "address must be an Ethereum address")
+		return
+	}
+
+	depth, err := h.parseTraceDepth(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Tracing %s to depth %d", address, depth)
+
+	trace, err := h.beneficiaryAnalyzer.TraceBeneficiaries(address, depth, h.config.MaxTraceNodes, opts)
+	if err != nil {
+		log.Errorf("Error tracing address: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+	h.respondWithTrace(w, r, trace)
+}
+
+// HandleTraceResume handles the /trace/resume endpoint
+func (h *Handler) HandleTraceResume(w http.ResponseWriter, r *http.Request) {
+	var req TraceResumeRequest
+	if err := decodeJSONBody(w, r, h.config.MaxBodyBytes, &req); err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.State == nil {
+		h.respondWithError(w, r, http.StatusBadRequest, "state is required")
+		return
+	}
+	if req.State.Depth+req.AdditionalDepth > h.config.MaxTraceDepth {
+		h.respondWithError(w, r, http.StatusBadRequest, fmt.Sprintf("depth must not exceed %d (MAX_TRACE_DEPTH)", h.config.MaxTraceDepth))
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Resuming trace of %s with %d queued addresses", req.State.Root, len(req.State.Queue))
+
+	trace, err := h.beneficiaryAnalyzer.ResumeTrace(req.State, req.AdditionalDepth, h.config.MaxTraceNodes, opts)
+	if errors.Is(err, analyzer.ErrInvalidTraceState) {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Errorf("Error resuming trace: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	h.respondWithTrace(w, r, trace)
+}
+
+// respondWithTrace writes a trace, keeping its checkpoint only when the trace stopped early or
+// checkpoint=true asks for it to extend the depth later
+func (h *Handler) respondWithTrace(w http.ResponseWriter, r *http.Request, trace *analyzer.Trace) {
+	log := requestLogger(h.logger, r)
+	if trace.Truncated {
+		log.Warnf("Trace of %s stopped after %d addresses (MAX_TRACE_NODES)", trace.Root, trace.NodesVisited)
+	}
+	if trace.Interrupted != "" {
+		log.Warnf("Trace of %s interrupted: %s", trace.Root, trace.Interrupted)
+	}
+	if !trace.Truncated && trace.Interrupted == "" && r.URL.Query().Get("checkpoint") != "true" {
+		trace.State = nil
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, TraceResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Data:      trace,
+	})
+}
+
+// parseTraceDepth reads the depth parameter, rejecting depths beyond MAX_TRACE_DEPTH
+func (h *Handler) parseTraceDepth(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("depth")
+	if value == "" {
+		return min(defaultTraceDepth, h.config.MaxTraceDepth), nil
+	}
+
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 1 {
+		return 0, fmt.Errorf("depth must be a positive integer")
+	}
+	if depth > h.config.MaxTraceDepth {
+		return 0, fmt.Errorf("depth must not exceed %d (MAX_TRACE_DEPTH)", h.config.MaxTraceDepth)
+	}
+	return depth, nil
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+)
+
+// TransactionFlowResponse represents the response format for the tx endpoint
+type TransactionFlowResponse struct {
+	Message   string                    `json:"message"`
+	RequestID string                    `json:"request_id,omitempty"`
+	Data      *analyzer.TransactionFlow `json:"data"`
+}
+
+// TransactionStatusResponse represents the response format for the tx status endpoint
+type TransactionStatusResponse struct {
+	Message   string                      `json:"message"`
+	RequestID string                      `json:"request_id,omitempty"`
+	Data      *analyzer.TransactionStatus `json:"data"`
+}
+
+// HandleTransaction handles the /tx endpoint
+func (h *Handler) HandleTransaction(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Query().Get("hash")
+	if hash == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "hash parameter is required")
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Mapping fund flow for transaction: %s", hash)
+
+	flow, err := h.flowAnalyzer.AnalyzeTransaction(hash)
+	if errors.Is(err, etherscan.ErrTransactionNotFound) {
+		h.respondWithError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Errorf("Error mapping transaction: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, TransactionFlowResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Data:      flow,
+	})
+}
+
+// HandleTransactionStatus handles the /tx/status endpoint
+func (h *Handler) HandleTransactionStatus(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Query().Get("hash")
+	if hash == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "hash parameter is required")
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Looking up status of transaction: %s", hash)
+
+	status, err := h.flowAnalyzer.TransactionStatus(hash)
+	if errors.Is(err, etherscan.ErrTransactionNotFound) {
+		h.respondWithError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Errorf("Error looking up transaction status: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, TransactionStatusResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Data:      status,
+	})
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/analyzer"
+	"github.com/shrxyeh/ethereum-fund-flow/internal/etherscan"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	txTypeNormal   = "normal"
+	txTypeInternal = "internal"
+	txTypeToken    = "token"
+	txTypeAll      = "all"
+)
+
+// RawTransactions holds the unprocessed Etherscan records for an address, by type
+type RawTransactions struct {
+	Normal   []RawTransaction   `json:"normal,omitempty"`
+	Internal []RawTransaction   `json:"internal,omitempty"`
+	Tokens   []RawTokenTransfer `json:"tokens,omitempty"`
+}
+
+// RawTransaction is an unprocessed Etherscan transaction with its gas price also given in Gwei
+type RawTransaction struct {
+	etherscan.Transaction
+	GasPriceGwei *float64 `json:"gas_price_gwei,omitempty"` // Unset when Etherscan reports no gas price (internal transactions)
+}
+
+// RawTokenTransfer is an unprocessed Etherscan token transfer with its gas price also given in Gwei
+type RawTokenTransfer struct {
+	etherscan.TokenTransfer
+	GasPriceGwei *float64 `json:"gas_price_gwei,omitempty"`
+}
+
+// rawTransactions wraps Etherscan transactions with their derived Gwei gas price
+func rawTransactions(txs []etherscan.Transaction) []RawTransaction {
+	raw := make([]RawTransaction, len(txs))
+	for i, tx := range txs {
+		raw[i] = RawTransaction{Transaction: tx, GasPriceGwei: gasPriceGwei(tx.GasPrice)}
+	}
+	return raw
+}
+
+// rawTokenTransfers wraps Etherscan token transfers with their derived Gwei gas price
+func rawTokenTransfers(transfers []etherscan.TokenTransfer) []RawTokenTransfer {
+	raw := make([]RawTokenTransfer, len(transfers))
+	for i, transfer := range transfers {
+		raw[i] = RawTokenTransfer{TokenTransfer: transfer, GasPriceGwei: gasPriceGwei(transfer.GasPrice)}
+	}
+	return raw
+}
+
+// gasPriceGwei converts a Wei gas price to Gwei, or returns nil when it is missing or unparseable
+func gasPriceGwei(gasPrice string) *float64 {
+	if gasPrice == "" {
+		return nil
+	}
+	gwei, err := analyzer.WeiToGwei(gasPrice)
+	if err != nil {
+		return nil
+	}
+	return &gwei
+}
+
+// TransactionsResponse represents the response format for the transactions endpoint
+type TransactionsResponse struct {
+	Message   string          `json:"message"`
+	RequestID string          `json:"request_id,omitempty"`
+	Data      RawTransactions `json:"data"`
+}
+
+// HandleTransactions handles the /transactions endpoint, returning raw records without aggregation
+func (h *Handler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "address parameter is required")
+		return
+	}
+	if !etherscan.IsValidAddress(address) {
+		h.respondWithError(w, r, http.StatusBadRequest, "address must be an Ethereum address")
+		return
+	}
+
+	txType := r.URL.Query().Get("type")
+	if txType == "" {
+		txType = txTypeAll
+	}
+	if txType != txTypeNormal && txType != txTypeInternal && txType != txTypeToken && txType != txTypeAll {
+		h.respondWithError(w, r, http.StatusBadRequest, "type must be 'normal', 'internal', 'token' or 'all'")
+		return
+	}
+
+	// Types disabled for the deployment are refused when asked for and skipped within all
+	if txType == txTypeInternal && !h.config.EnableInternal || txType == txTypeToken && !h.config.EnableToken {
+		h.respondWithError(w, r, http.StatusBadRequest, txType+" transactions are disabled on this server")
+		return
+	}
+
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log := requestLogger(h.logger, r)
+	log.Infof("Fetching %s transactions for address: %s", txType, address)
+
+	var data RawTransactions
+	eg := errgroup.Group{}
+
+	if txType == txTypeNormal || txType == txTypeAll {
+		eg.Go(func() error {
+			normalTxs, err := h.etherscanClient.GetNormalTransactions(address, opts.FromBlock, opts.ToBlock)
+			if err != nil {
+				return fmt.Errorf("error fetching normal transactions: %w", err)
+			}
+			data.Normal = rawTransactions(normalTxs)
+			return nil
+		})
+	}
+
+	if txType == txTypeInternal || txType == txTypeAll && h.config.EnableInternal {
+		eg.Go(func() error {
+			internalTxs, err := h.etherscanClient.GetInternalTransactions(address, opts.FromBlock, opts.ToBlock)
+			if err != nil {
+				return fmt.Errorf("error fetching internal transactions: %w", err)
+			}
+			data.Internal = rawTransactions(internalTxs)
+			return nil
+		})
+	}
+
+	if txType == txTypeToken || txType == txTypeAll && h.config.EnableToken {
+		eg.Go(func() error {
+			tokenTransfers, err := h.etherscanClient.GetTokenTransfers(address, opts.FromBlock, opts.ToBlock)
+			if err != nil {
+				return fmt.Errorf("error fetching token transfers: %w", err)
+			}
+			data.Tokens = rawTokenTransfers(tokenTransfers)
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		log.Errorf("Error fetching transactions: %v", err)
+		h.respondWithAnalysisError(w, r, err)
+		return
+	}
+
+	h.respondWithJSON(w, r, http.StatusOK, TransactionsResponse{
+		Message:   "success",
+		RequestID: requestIDFromContext(r.Context()),
+		Data:      data,
+	})
+}
@@ -3,23 +3,223 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	// defaultEtherscanBaseURL is the Etherscan mainnet API endpoint
+	defaultEtherscanBaseURL = "https://api.etherscan.io/api"
+
+	// defaultStablecoins pegs USDC, USDT and DAI on mainnet at $1
+	defaultStablecoins = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48:1," +
+		"0xdac17f958d2ee523a2206206994597c13d831ec7:1," +
+		"0x6b175474e89094c44da98b954eedeac495271d0f:1"
+
+	// defaultWETHContract is the mainnet Wrapped Ether contract
+	defaultWETHContract = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+
+	// defaultCoinGeckoBaseURL is the public CoinGecko API
+	defaultCoinGeckoBaseURL = "https://api.coingecko.com/api/v3"
+)
+
+// Supported historical price feeds
+const (
+	PriceFeedCoinGecko = "coingecko"
+	PriceFeedNone      = "none"
+)
+
+// Number formatting locales of CSV exports
+const (
+	CSVLocaleEnglish   = "en"    // 1234567.89, the canonical format
+	CSVLocaleEnglishUS = "en-us" // 1,234,567.89
+	CSVLocaleGerman    = "de"    // 1.234.567,89
+	CSVLocaleFrench    = "fr"    // 1 234 567,89
+)
+
+// JSON field naming conventions of responses
+const (
+	FieldNamingSnakeCase = "snake_case" // beneficiary_address, the canonical naming
+	FieldNamingCamelCase = "camelCase"  // beneficiaryAddress
+)
+
 // Config holds application configuration
 type Config struct {
-	EtherscanAPIKey string
-	Port            string
+	EtherscanAPIKey  string
+	EtherscanBaseURL string
+	Port             string
+
+	// EtherscanHTTPProxy, when set, is the proxy every Etherscan request goes through,
+	// overriding HTTP_PROXY and HTTPS_PROXY
+	EtherscanHTTPProxy string
+
+	// EtherscanUserAgent is the User-Agent sent on Etherscan requests (empty means
+	// eth-fund-flow/<version>)
+	EtherscanUserAgent string
+
+	// EtherscanFallbackURLs are Etherscan-compatible API URLs tried, in order, when the base URL
+	// is rate-limited, failing or unreachable. Each may carry its own apikey query parameter.
+	EtherscanFallbackURLs []string
+
+	// AnalysisMode is the default analysis mode: beneficiary, payer or both
+	AnalysisMode string
+
+	// APIAuthToken, when set, is required as a bearer token on the analysis endpoints
+	APIAuthToken string
+
+	// DailyCallBudget is the number of Etherscan calls allowed per day, reported by /quota
+	DailyCallBudget int
+
+	// AnalysisTimeout bounds the total time of one analysis's Etherscan requests, retries
+	// included (0 means no deadline); AnalysisMaxRetries bounds their total retries
+	AnalysisTimeout    time.Duration
+	AnalysisMaxRetries int
+
+	// MaxConcurrentRequests bounds outstanding Etherscan requests across all analyses (0 means unlimited)
+	MaxConcurrentRequests int
+
+	// MaxConcurrentAnalyses bounds the API requests analyzed at once; further requests are
+	// refused with 429 (0 means unlimited)
+	MaxConcurrentAnalyses int
+
+	// EnableInternal and EnableToken turn the internal transaction and token transfer fetches on
+	// for every analysis; disabling them saves Etherscan quota
+	EnableInternal bool
+	EnableToken    bool
+
+	// Result caps (0 means unlimited)
+	MaxCounterparties    int
+	MaxTxPerCounterparty int
+
+	// PrecisionLimit is the amount above which float results are written as exact decimal
+	// strings with a precision warning, since a float64 can't hold them exactly (0 disables)
+	PrecisionLimit float64
+
+	// CORSOrigins lists the origins allowed to make cross-origin requests ("*" allows any)
+	CORSOrigins []string
+
+	// Stablecoins maps lowercase token contract addresses to their USD peg
+	Stablecoins map[string]float64
+
+	// NativeDecimals maps chain IDs (0 for the endpoint's default chain) to the decimals of
+	// their native token, for chains whose native token doesn't have Ether's 18
+	NativeDecimals map[int]int
+
+	// ProxyContracts is the set of lowercase router/proxy contract addresses resolve_proxies sees through
+	ProxyContracts map[string]bool
+
+	// OwnAddresses is the set of lowercase addresses the user controls, dropped from counterparty results
+	OwnAddresses map[string]bool
+
+	// ExchangeAddresses is the set of lowercase addresses bucket=true reports as exchanges, in
+	// addition to the built-in exchange wallets
+	ExchangeAddresses map[string]bool
+
+	// Historical price feed for usd=true: PriceFeed is "coingecko" or "none"
+	PriceFeed        string
+	CoinGeckoBaseURL string
+	CoinGeckoAPIKey  string
+
+	// WETHContract is the lowercase Wrapped Ether contract whose deposits and withdrawals are wrap/unwrap flows
+	WETHContract string
+
+	// SpamContracts is the set of lowercase token contract addresses whose transfers are ignored
+	SpamContracts map[string]bool
+
+	// SanctionedAddresses is the set of lowercase sanctioned addresses counterparties are screened against
+	SanctionedAddresses map[string]bool
+
+	// Structuring detection thresholds
+	StructuringMinRepeated    int
+	StructuringMinRound       int
+	StructuringRoundTolerance float64
+
+	// ExchangeInternalMinAmount is the smallest transfer between unnamed exchange wallets
+	// flagged as an internal exchange movement
+	ExchangeInternalMinAmount float64
+
+	// Self-custody detection thresholds: the percentage of the received Ether a fresh
+	// beneficiary must forward, the time it must do so within, and the beneficiaries looked ahead
+	SelfCustodyMinForwardPercent float64
+	SelfCustodyWindow            time.Duration
+	SelfCustodyMaxLookups        int
+
+	// Counterparty significance score weights
+	ScoreWeightAmount  float64
+	ScoreWeightCount   float64
+	ScoreWeightRecency float64
+
+	// SlowCallThreshold is the Etherscan call latency above which a warning is logged (0 disables it)
+	SlowCallThreshold time.Duration
+
+	// ProxyMaxAttempts and ProxyTimeout are the attempts and per-attempt timeout of proxy
+	// module (JSON-RPC) calls such as eth_blockNumber and eth_getTransactionByHash
+	ProxyMaxAttempts int
+	ProxyTimeout     time.Duration
+
+	// CacheDBPath is the SQLite database persisting fetched transaction lists (empty disables it);
+	// lists reaching the latest block (or an end block not yet mined) are refetched once older than CacheTTL
+	CacheDBPath string
+	CacheTTL    time.Duration
+
+	// SubscribePollInterval is how often /subscribe checks for new blocks
+	SubscribePollInterval time.Duration
+
+	// MaxSubscriptions bounds the /subscribe connections open at once; further ones are
+	// refused with 429 (0 means unlimited)
+	MaxSubscriptions int
+
+	// Multi-hop trace limits: the deepest trace a request may ask for and the most addresses
+	// one trace analyzes (0 means unlimited)
+	MaxTraceDepth int
+	MaxTraceNodes int
+
+	// MaxBodyBytes limits the size of POST request bodies (0 means unlimited)
+	MaxBodyBytes int64
+
+	// MaxQueryLength limits the length of request query strings (0 means unlimited)
+	MaxQueryLength int
+
+	// CSVLocale is the default number formatting of CSV exports: en, en-us, de or fr
+	CSVLocale string
+
+	// FieldNaming is the default JSON field naming of responses: snake_case or camelCase
+	FieldNaming string
+
+	// ReportHMACKey, when set, is the key format=report signatures are computed with
+	ReportHMACKey string
 }
 
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	_ = godotenv.Load()
 
+	// Parse errors are collected with the validation problems so all of them are reported at once
+	env := &envParser{}
+
 	etherscanAPIKey := os.Getenv("ETHERSCAN_API_KEY")
-	if etherscanAPIKey == "" {
-		return nil, fmt.Errorf("ETHERSCAN_API_KEY environment variable is required")
+
+	etherscanBaseURL := os.Getenv("ETHERSCAN_BASE_URL")
+	if etherscanBaseURL == "" {
+		etherscanBaseURL = defaultEtherscanBaseURL
+	}
+
+	wethContract := os.Getenv("WETH_CONTRACT")
+	if wethContract == "" {
+		wethContract = defaultWETHContract
+	}
+
+	priceFeed := os.Getenv("PRICE_FEED")
+	if priceFeed == "" {
+		priceFeed = PriceFeedCoinGecko
+	}
+
+	coinGeckoBaseURL := os.Getenv("COINGECKO_BASE_URL")
+	if coinGeckoBaseURL == "" {
+		coinGeckoBaseURL = defaultCoinGeckoBaseURL
 	}
 
 	port := os.Getenv("PORT")
@@ -27,8 +227,342 @@ func LoadConfig() (*Config, error) {
 		port = "8080" // Default port
 	}
 
-	return &Config{
-		EtherscanAPIKey: etherscanAPIKey,
-		Port:            port,
-	}, nil
-}
\ No newline at end of file
+	dailyCallBudget := env.int("DAILY_CALL_BUDGET", 100000)
+	maxConcurrentRequests := env.int("MAX_CONCURRENT_REQUESTS", 5)
+	maxConcurrentAnalyses := env.int("MAX_CONCURRENT_ANALYSES", 20)
+	enableInternal := env.bool("ENABLE_INTERNAL", true)
+	enableToken := env.bool("ENABLE_TOKEN", true)
+	analysisTimeout := env.duration("ANALYSIS_TIMEOUT", 2*time.Minute)
+	analysisMaxRetries := env.int("ANALYSIS_MAX_RETRIES", 6)
+
+	analysisMode := os.Getenv("ANALYSIS_MODE")
+	if analysisMode == "" {
+		analysisMode = "both"
+	}
+
+	maxCounterparties := env.int("MAX_COUNTERPARTIES", 0)
+	maxTxPerCounterparty := env.int("MAX_TX_PER_COUNTERPARTY", 0)
+
+	// 2^53: above it a float64 no longer represents every integer
+	precisionLimit := env.float("PRECISION_LIMIT", 1<<53)
+
+	stablecoinsEnv := os.Getenv("STABLECOINS")
+	if stablecoinsEnv == "" {
+		stablecoinsEnv = defaultStablecoins
+	}
+	stablecoins, err := parseStablecoins(stablecoinsEnv)
+	if err != nil {
+		env.fail(fmt.Errorf("invalid STABLECOINS: %w", err))
+	}
+
+	nativeDecimals, err := parseNativeDecimals(os.Getenv("NATIVE_DECIMALS"))
+	if err != nil {
+		env.fail(fmt.Errorf("invalid NATIVE_DECIMALS: %w", err))
+	}
+
+	spamContracts, err := loadDenylist(os.Getenv("SPAM_DENYLIST_PATH"))
+	if err != nil {
+		env.fail(fmt.Errorf("invalid SPAM_DENYLIST_PATH: %w", err))
+	}
+
+	sanctionedAddresses, err := loadDenylist(os.Getenv("SANCTIONS_LIST_PATH"))
+	if err != nil {
+		env.fail(fmt.Errorf("invalid SANCTIONS_LIST_PATH: %w", err))
+	}
+
+	structuringMinRepeated := env.int("STRUCTURING_MIN_REPEATED", 3)
+	structuringMinRound := env.int("STRUCTURING_MIN_ROUND", 3)
+	structuringRoundTolerance := env.float("STRUCTURING_ROUND_TOLERANCE", 0.001)
+	exchangeInternalMinAmount := env.float("EXCHANGE_INTERNAL_MIN_AMOUNT", 100)
+	selfCustodyMinForward := env.float("SELF_CUSTODY_MIN_FORWARD_PERCENT", 90)
+	selfCustodyWindow := env.duration("SELF_CUSTODY_WINDOW", 24*time.Hour)
+	selfCustodyMaxLookups := env.int("SELF_CUSTODY_MAX_LOOKUPS", 20)
+	scoreWeightAmount := env.float("SCORE_WEIGHT_AMOUNT", 0.5)
+	scoreWeightCount := env.float("SCORE_WEIGHT_COUNT", 0.3)
+	scoreWeightRecency := env.float("SCORE_WEIGHT_RECENCY", 0.2)
+	slowCallThreshold := env.duration("SLOW_CALL_THRESHOLD", 5*time.Second)
+	proxyMaxAttempts := env.int("ETHERSCAN_PROXY_MAX_ATTEMPTS", 4)
+	proxyTimeout := env.duration("ETHERSCAN_PROXY_TIMEOUT", 15*time.Second)
+	cacheTTL := env.duration("CACHE_TTL", 10*time.Minute)
+	subscribePollInterval := env.duration("SUBSCRIBE_POLL_INTERVAL", 15*time.Second)
+	maxSubscriptions := env.int("MAX_SUBSCRIPTIONS", 100)
+	maxTraceDepth := env.int("MAX_TRACE_DEPTH", 5)
+	maxTraceNodes := env.int("MAX_TRACE_NODES", 100)
+	maxBodyBytes := env.int("MAX_BODY_BYTES", 1<<20)
+	maxQueryLength := env.int("MAX_QUERY_LENGTH", 2048)
+
+	csvLocale := strings.ToLower(os.Getenv("CSV_LOCALE"))
+	if csvLocale == "" {
+		csvLocale = CSVLocaleEnglish
+	}
+
+	fieldNaming := os.Getenv("JSON_FIELD_NAMING")
+	if fieldNaming == "" {
+		fieldNaming = FieldNamingSnakeCase
+	}
+
+	cfg := &Config{
+		EtherscanAPIKey:           etherscanAPIKey,
+		EtherscanBaseURL:          etherscanBaseURL,
+		EtherscanHTTPProxy:        os.Getenv("ETHERSCAN_HTTP_PROXY"),
+		EtherscanUserAgent:        os.Getenv("ETHERSCAN_USER_AGENT"),
+		EtherscanFallbackURLs:     splitList(os.Getenv("ETHERSCAN_FALLBACK_URLS")),
+		Port:                      port,
+		AnalysisMode:              analysisMode,
+		APIAuthToken:              os.Getenv("API_AUTH_TOKEN"),
+		DailyCallBudget:           dailyCallBudget,
+		MaxConcurrentRequests:     maxConcurrentRequests,
+		MaxConcurrentAnalyses:     maxConcurrentAnalyses,
+		EnableInternal:            enableInternal,
+		EnableToken:               enableToken,
+		AnalysisTimeout:           analysisTimeout,
+		AnalysisMaxRetries:        analysisMaxRetries,
+		MaxCounterparties:         maxCounterparties,
+		MaxTxPerCounterparty:      maxTxPerCounterparty,
+		PrecisionLimit:            precisionLimit,
+		CORSOrigins:               splitList(os.Getenv("CORS_ORIGINS")),
+		Stablecoins:               stablecoins,
+		NativeDecimals:            nativeDecimals,
+		SpamContracts:             spamContracts,
+		SanctionedAddresses:       sanctionedAddresses,
+		ProxyContracts:            addressSet(os.Getenv("PROXY_CONTRACTS")),
+		WETHContract:              strings.ToLower(wethContract),
+		OwnAddresses:              addressSet(os.Getenv("OWN_ADDRESSES")),
+		ExchangeAddresses:         addressSet(os.Getenv("EXCHANGE_ADDRESSES")),
+		PriceFeed:                 priceFeed,
+		CoinGeckoBaseURL:          coinGeckoBaseURL,
+		CoinGeckoAPIKey:           os.Getenv("COINGECKO_API_KEY"),
+		StructuringMinRepeated:    structuringMinRepeated,
+		StructuringMinRound:       structuringMinRound,
+		StructuringRoundTolerance: structuringRoundTolerance,
+		ScoreWeightAmount:         scoreWeightAmount,
+		ScoreWeightCount:          scoreWeightCount,
+		ScoreWeightRecency:        scoreWeightRecency,
+		SlowCallThreshold:         slowCallThreshold,
+		ProxyMaxAttempts:          proxyMaxAttempts,
+		ProxyTimeout:              proxyTimeout,
+		CacheDBPath:               os.Getenv("CACHE_DB_PATH"),
+		CacheTTL:                  cacheTTL,
+		SubscribePollInterval:     subscribePollInterval,
+		MaxSubscriptions:          maxSubscriptions,
+		MaxTraceDepth:             maxTraceDepth,
+		MaxTraceNodes:             maxTraceNodes,
+		MaxBodyBytes:              int64(maxBodyBytes),
+		MaxQueryLength:            maxQueryLength,
+		CSVLocale:                 csvLocale,
+		FieldNaming:               fieldNaming,
+		ReportHMACKey:             os.Getenv("REPORT_HMAC_KEY"),
+
+		ExchangeInternalMinAmount: exchangeInternalMinAmount,
+
+		SelfCustodyMinForwardPercent: selfCustodyMinForward,
+		SelfCustodyWindow:            selfCustodyWindow,
+		SelfCustodyMaxLookups:        selfCustodyMaxLookups,
+	}
+
+	if err := cfg.validate(env.problems); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// parseStablecoins parses a comma-separated list of "contract:peg" pairs
+func parseStablecoins(value string) (map[string]float64, error) {
+	stablecoins := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		contract, pegStr, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be in the form contract:peg", entry)
+		}
+
+		peg, err := strconv.ParseFloat(pegStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid peg for %s: %w", contract, err)
+		}
+		stablecoins[strings.ToLower(strings.TrimSpace(contract))] = peg
+	}
+	return stablecoins, nil
+}
+
+// parseNativeDecimals parses a comma-separated list of "chainid:decimals" pairs
+func parseNativeDecimals(value string) (map[int]int, error) {
+	nativeDecimals := make(map[int]int)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		chainStr, decimalsStr, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be in the form chainid:decimals", entry)
+		}
+
+		chainID, err := strconv.Atoi(strings.TrimSpace(chainStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain ID in %q", entry)
+		}
+		decimals, err := strconv.Atoi(strings.TrimSpace(decimalsStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid decimals for chain %d: must be an integer", chainID)
+		}
+		nativeDecimals[chainID] = decimals
+	}
+	return nativeDecimals, nil
+}
+
+// loadDenylist reads a file of addresses, one per line, into a set keyed by lowercase
+// address. Blank lines and lines starting with # are ignored. An empty path yields an empty set.
+func loadDenylist(path string) (map[string]bool, error) {
+	denylist := make(map[string]bool)
+	if path == "" {
+		return denylist, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denylist[strings.ToLower(line)] = true
+	}
+	return denylist, nil
+}
+
+// addressSet parses a comma-separated list of addresses into a set keyed by lowercase address
+func addressSet(value string) map[string]bool {
+	set := make(map[string]bool)
+	for _, address := range splitList(value) {
+		set[strings.ToLower(address)] = true
+	}
+	return set
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envParser reads typed environment variables, collecting parse errors instead of stopping at
+// the first one. A variable that fails to parse takes its fallback.
+type envParser struct {
+	problems []error
+}
+
+// fail records a problem with the environment
+func (p *envParser) fail(err error) {
+	p.problems = append(p.problems, err)
+}
+
+// int reads an integer environment variable, returning the fallback when unset or invalid
+func (p *envParser) int(key string, fallback int) int {
+	i, err := getEnvInt(key, fallback)
+	if err != nil {
+		p.fail(err)
+		return fallback
+	}
+	return i
+}
+
+// float reads a floating point environment variable, returning the fallback when unset or invalid
+func (p *envParser) float(key string, fallback float64) float64 {
+	f, err := getEnvFloat(key, fallback)
+	if err != nil {
+		p.fail(err)
+		return fallback
+	}
+	return f
+}
+
+// bool reads a boolean environment variable, returning the fallback when unset or invalid
+func (p *envParser) bool(key string, fallback bool) bool {
+	b, err := getEnvBool(key, fallback)
+	if err != nil {
+		p.fail(err)
+		return fallback
+	}
+	return b
+}
+
+// duration reads a duration environment variable, returning the fallback when unset or invalid
+func (p *envParser) duration(key string, fallback time.Duration) time.Duration {
+	d, err := getEnvDuration(key, fallback)
+	if err != nil {
+		p.fail(err)
+		return fallback
+	}
+	return d
+}
+
+// getEnvInt reads an integer environment variable, returning the fallback when unset
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	return i, nil
+}
+
+// getEnvFloat reads a floating point environment variable, returning the fallback when unset
+func getEnvFloat(key string, fallback float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number: %w", key, err)
+	}
+	return f, nil
+}
+
+// getEnvBool reads a boolean environment variable such as "true" or "false", returning the fallback when unset
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false: %w", key, err)
+	}
+	return b, nil
+}
+
+// getEnvDuration reads a duration environment variable such as "5s", returning the fallback when unset
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration such as 5s: %w", key, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// setValidEnv sets the environment of a valid configuration
+func setValidEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("ETHERSCAN_API_KEY", strings.Repeat("A", etherscanAPIKeyLength))
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	setValidEnv(t)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "8080" || cfg.MaxConcurrentAnalyses != 20 || cfg.MaxSubscriptions != 100 || cfg.SelfCustodyMinForwardPercent != 90 {
+		t.Errorf("config = %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoadConfigReportsEveryProblem(t *testing.T) {
+	setValidEnv(t)
+	env := map[string]string{
+		"DAILY_CALL_BUDGET":                "lots",
+		"ENABLE_TOKEN":                     "maybe",
+		"ANALYSIS_TIMEOUT":                 "2 minutes",
+		"PRECISION_LIMIT":                  "-1",
+		"EXCHANGE_INTERNAL_MIN_AMOUNT":     "-5",
+		"SELF_CUSTODY_MIN_FORWARD_PERCENT": "150",
+		"STABLECOINS":                      "0xdac17f958d2ee523a2206206994597c13d831ec7",
+		"NATIVE_DECIMALS":                  "137:24",
+		"SANCTIONS_LIST_PATH":              "/nonexistent/sanctions.txt",
+		"PORT":                             "99999",
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	// A single error names every bad variable, parse errors and range checks alike
+	_, err := LoadConfig()
+	if err == nil {
+		t.Fatal("LoadConfig succeeded, want an error")
+	}
+	for key := range env {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error doesn't mention %s:\n%v", key, err)
+		}
+	}
+}
+
+func TestLoadConfigNativeDecimalsRange(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"137:18", true},
+		{"9999:6,0:1", true},
+		{"137:19", false},
+		{"137:0", false},
+	}
+	for _, tt := range tests {
+		setValidEnv(t)
+		t.Setenv("NATIVE_DECIMALS", tt.value)
+
+		_, err := LoadConfig()
+		if tt.valid && err != nil {
+			t.Errorf("NATIVE_DECIMALS=%s: %v", tt.value, err)
+		}
+		if !tt.valid && (err == nil || !strings.Contains(err.Error(), "between 1 and 18")) {
+			t.Errorf("NATIVE_DECIMALS=%s: error %v, want it out of range", tt.value, err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// etherscanAPIKeyLength is the length of keys issued by etherscan.io
+const etherscanAPIKeyLength = 34
+
+// Validate checks the configuration for problems and returns a single error listing all of them
+func (c *Config) Validate() error {
+	return c.validate(nil)
+}
+
+// validate checks the configuration, returning a single error listing the given problems found
+// while loading it followed by those of the configuration itself
+func (c *Config) validate(problems []error) error {
+
+	// API key
+	if c.EtherscanAPIKey == "" {
+		problems = append(problems, fmt.Errorf("ETHERSCAN_API_KEY environment variable is required"))
+	} else if !isAlphanumeric(c.EtherscanAPIKey) {
+		problems = append(problems, fmt.Errorf("ETHERSCAN_API_KEY must contain only letters and digits"))
+	} else if c.usesEtherscan() && len(c.EtherscanAPIKey) != etherscanAPIKeyLength {
+		problems = append(problems, fmt.Errorf("ETHERSCAN_API_KEY must be %d characters long, got %d", etherscanAPIKeyLength, len(c.EtherscanAPIKey)))
+	}
+
+	// Base URL
+	if err := validateBaseURL(c.EtherscanBaseURL); err != nil {
+		problems = append(problems, fmt.Errorf("invalid ETHERSCAN_BASE_URL: %w", err))
+	}
+
+	// Fallback providers
+	for _, fallbackURL := range c.EtherscanFallbackURLs {
+		if err := validateBaseURL(fallbackURL); err != nil {
+			problems = append(problems, fmt.Errorf("invalid ETHERSCAN_FALLBACK_URLS entry: %w", err))
+		}
+	}
+
+	// Proxy
+	if c.EtherscanHTTPProxy != "" {
+		if err := validateProxyURL(c.EtherscanHTTPProxy); err != nil {
+			problems = append(problems, fmt.Errorf("invalid ETHERSCAN_HTTP_PROXY: %w", err))
+		}
+	}
+
+	// Port
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", c.Port))
+	}
+
+	// Quota and concurrency
+	if c.DailyCallBudget < 0 {
+		problems = append(problems, fmt.Errorf("DAILY_CALL_BUDGET must not be negative"))
+	}
+	if c.MaxConcurrentRequests < 0 {
+		problems = append(problems, fmt.Errorf("MAX_CONCURRENT_REQUESTS must not be negative"))
+	}
+	if c.MaxConcurrentAnalyses < 0 {
+		problems = append(problems, fmt.Errorf("MAX_CONCURRENT_ANALYSES must not be negative"))
+	}
+	if c.AnalysisTimeout < 0 {
+		problems = append(problems, fmt.Errorf("ANALYSIS_TIMEOUT must not be negative"))
+	}
+	if c.AnalysisMaxRetries < 0 {
+		problems = append(problems, fmt.Errorf("ANALYSIS_MAX_RETRIES must not be negative"))
+	}
+
+	// Analysis mode
+	if !ValidAnalysisMode(c.AnalysisMode) {
+		problems = append(problems, fmt.Errorf("ANALYSIS_MODE must be 'beneficiary', 'payer', or 'both', got %q", c.AnalysisMode))
+	}
+
+	// Result caps
+	if c.MaxCounterparties < 0 {
+		problems = append(problems, fmt.Errorf("MAX_COUNTERPARTIES must not be negative"))
+	}
+	if c.MaxTxPerCounterparty < 0 {
+		problems = append(problems, fmt.Errorf("MAX_TX_PER_COUNTERPARTY must not be negative"))
+	}
+
+	if c.PrecisionLimit < 0 {
+		problems = append(problems, fmt.Errorf("PRECISION_LIMIT must not be negative"))
+	}
+
+	// Stablecoins
+	for contract, peg := range c.Stablecoins {
+		if peg <= 0 {
+			problems = append(problems, fmt.Errorf("STABLECOINS peg for %s must be positive", contract))
+		}
+	}
+
+	// Native token decimals. Raw totals are kept in Ether's 18 decimals, which would drop the
+	// smallest units of a token with more.
+	for chainID, decimals := range c.NativeDecimals {
+		if chainID < 0 {
+			problems = append(problems, fmt.Errorf("NATIVE_DECIMALS chain ID %d must not be negative", chainID))
+		}
+		if decimals < 1 || decimals > 18 {
+			problems = append(problems, fmt.Errorf("NATIVE_DECIMALS for chain %d must be between 1 and 18", chainID))
+		}
+	}
+
+	// Structuring thresholds
+	if c.StructuringMinRepeated < 0 {
+		problems = append(problems, fmt.Errorf("STRUCTURING_MIN_REPEATED must not be negative"))
+	}
+	if c.StructuringMinRound < 0 {
+		problems = append(problems, fmt.Errorf("STRUCTURING_MIN_ROUND must not be negative"))
+	}
+	if c.StructuringRoundTolerance < 0 || c.StructuringRoundTolerance >= 1 {
+		problems = append(problems, fmt.Errorf("STRUCTURING_ROUND_TOLERANCE must be between 0 and 1"))
+	}
+
+	// Exchange and self-custody heuristics
+	if c.ExchangeInternalMinAmount < 0 {
+		problems = append(problems, fmt.Errorf("EXCHANGE_INTERNAL_MIN_AMOUNT must not be negative"))
+	}
+	if c.SelfCustodyMinForwardPercent <= 0 || c.SelfCustodyMinForwardPercent > 100 {
+		problems = append(problems, fmt.Errorf("SELF_CUSTODY_MIN_FORWARD_PERCENT must be greater than 0 and at most 100"))
+	}
+
+	// Score weights
+	if c.ScoreWeightAmount < 0 || c.ScoreWeightCount < 0 || c.ScoreWeightRecency < 0 {
+		problems = append(problems, fmt.Errorf("SCORE_WEIGHT_AMOUNT, SCORE_WEIGHT_COUNT and SCORE_WEIGHT_RECENCY must not be negative"))
+	} else if c.ScoreWeightAmount+c.ScoreWeightCount+c.ScoreWeightRecency == 0 {
+		problems = append(problems, fmt.Errorf("at least one of SCORE_WEIGHT_AMOUNT, SCORE_WEIGHT_COUNT and SCORE_WEIGHT_RECENCY must be positive"))
+	}
+
+	// Observability
+	if c.SlowCallThreshold < 0 {
+		problems = append(problems, fmt.Errorf("SLOW_CALL_THRESHOLD must not be negative"))
+	}
+	if c.ProxyMaxAttempts < 1 {
+		problems = append(problems, fmt.Errorf("ETHERSCAN_PROXY_MAX_ATTEMPTS must be at least 1"))
+	}
+	if c.ProxyTimeout < 0 {
+		problems = append(problems, fmt.Errorf("ETHERSCAN_PROXY_TIMEOUT must not be negative"))
+	}
+
+	// Price feed
+	switch c.PriceFeed {
+	case PriceFeedCoinGecko:
+		if err := validateBaseURL(c.CoinGeckoBaseURL); err != nil {
+			problems = append(problems, fmt.Errorf("invalid COINGECKO_BASE_URL: %w", err))
+		}
+	case PriceFeedNone:
+	default:
+		problems = append(problems, fmt.Errorf("PRICE_FEED must be 'coingecko' or 'none', got %q", c.PriceFeed))
+	}
+
+	// Trace limits
+	if c.MaxTraceDepth < 1 {
+		problems = append(problems, fmt.Errorf("MAX_TRACE_DEPTH must be at least 1"))
+	}
+	if c.MaxTraceNodes < 0 {
+		problems = append(problems, fmt.Errorf("MAX_TRACE_NODES must not be negative"))
+	}
+
+	// Cache
+	if c.CacheTTL < 0 {
+		problems = append(problems, fmt.Errorf("CACHE_TTL must not be negative"))
+	}
+
+	// Subscriptions
+	if c.SubscribePollInterval <= 0 {
+		problems = append(problems, fmt.Errorf("SUBSCRIBE_POLL_INTERVAL must be positive"))
+	}
+	if c.MaxSubscriptions < 0 {
+		problems = append(problems, fmt.Errorf("MAX_SUBSCRIPTIONS must not be negative"))
+	}
+
+	// Request bodies
+	if c.MaxBodyBytes < 0 {
+		problems = append(problems, fmt.Errorf("MAX_BODY_BYTES must not be negative"))
+	}
+
+	// CSV export
+	if !ValidCSVLocale(c.CSVLocale) {
+		problems = append(problems, fmt.Errorf("CSV_LOCALE must be 'en', 'en-us', 'de' or 'fr', got %q", c.CSVLocale))
+	}
+
+	// JSON responses
+	if !ValidFieldNaming(c.FieldNaming) {
+		problems = append(problems, fmt.Errorf("JSON_FIELD_NAMING must be 'snake_case' or 'camelCase', got %q", c.FieldNaming))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// ValidAnalysisMode reports whether mode is a supported analysis mode
+func ValidAnalysisMode(mode string) bool {
+	return mode == "beneficiary" || mode == "payer" || mode == "both"
+}
+
+// ValidCSVLocale reports whether locale is a supported CSV number formatting locale
+func ValidCSVLocale(locale string) bool {
+	switch locale {
+	case CSVLocaleEnglish, CSVLocaleEnglishUS, CSVLocaleGerman, CSVLocaleFrench:
+		return true
+	}
+	return false
+}
+
+// ValidFieldNaming reports whether naming is a supported JSON field naming convention
+func ValidFieldNaming(naming string) bool {
+	return naming == FieldNamingSnakeCase || naming == FieldNamingCamelCase
+}
+
+// usesEtherscan reports whether the base URL points at etherscan.io rather than a compatible explorer
+func (c *Config) usesEtherscan() bool {
+	u, err := url.Parse(c.EtherscanBaseURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	return host == "etherscan.io" || strings.HasSuffix(host, ".etherscan.io")
+}
+
+// validateBaseURL checks that the given URL is an absolute http(s) URL
+func validateBaseURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in %q", rawURL)
+	}
+	return nil
+}
+
+// validateProxyURL checks that the given URL is an absolute http(s) or socks5 proxy URL
+func validateProxyURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		return fmt.Errorf("scheme must be http, https or socks5, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in %q", rawURL)
+	}
+	return nil
+}
+
+// isAlphanumeric reports whether s contains only ASCII letters and digits
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package etherscan
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBudgetExhausted is returned when an analysis runs out of its time or retry budget
+var ErrBudgetExhausted = errors.New("etherscan request budget exhausted")
+
+// requestBudget bounds the total time and the total retries of every request made through a client
+type requestBudget struct {
+	ctx     context.Context
+	retries atomic.Int64 // Remaining retries; negative means unlimited
+}
+
+// WithBudget returns a client sharing c's configuration, caches and rate limits whose requests
+// give up once ctx is done and share at most maxRetries retries between them (negative means
+// only the per-request attempt limit applies). Requests failing for either reason return an
+// error wrapping ErrBudgetExhausted.
+func (c *Client) WithBudget(ctx context.Context, maxRetries int) *Client {
+	budget := &requestBudget{ctx: ctx}
+	budget.retries.Store(int64(maxRetries))
+
+	scoped := *c
+	scoped.budget = budget
+	return &scoped
+}
+
+// context returns the context requests are made with
+func (c *Client) context() context.Context {
+	if c.budget == nil {
+		return context.Background()
+	}
+	return c.budget.ctx
+}
+
+// takeRetry claims one retry from the budget, reporting whether one was left
+func (c *Client) takeRetry() bool {
+	if c.budget == nil {
+		return true
+	}
+	for {
+		remaining := c.budget.retries.Load()
+		if remaining < 0 {
+			return true
+		}
+		if remaining == 0 {
+			return false
+		}
+		if c.budget.retries.CompareAndSwap(remaining, remaining-1) {
+			return true
+		}
+	}
+}
+
+// sleep waits for d, returning early with false if the budget's context is done first
+func (c *Client) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-c.context().Done():
+		return false
+	}
+}
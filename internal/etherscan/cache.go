@@ -0,0 +1,130 @@
+package etherscan
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// openRangeSuffix sets apart, in the cache, responses for ranges that were still open when
+// fetched, so a range that has since closed is never served a list missing its last blocks
+const openRangeSuffix = ":open"
+
+// ResponseCache persists transaction list responses keyed by address, action and block range
+type ResponseCache interface {
+	// Get returns a response stored within maxAge (0 means any age)
+	Get(address, action string, startBlock, endBlock int, maxAge time.Duration) ([]byte, bool, error)
+	Put(address, action string, startBlock, endBlock int, body []byte) error
+}
+
+// responseKey identifies a cacheable transaction list request
+type responseKey struct {
+	address    string
+	action     string
+	startBlock int
+	endBlock   int
+}
+
+// listKey builds the cache key of a transaction list request
+func listKey(address, action string, startBlock, endBlock int) *responseKey {
+	return &responseKey{address: strings.ToLower(address), action: action, startBlock: startBlock, endBlock: endBlock}
+}
+
+// chainHeads records the latest block seen per chain ID, shared with the chain-scoped clients
+type chainHeads struct {
+	mu    sync.Mutex
+	heads map[int]chainHead
+}
+
+// chainHead is a latest block number with the time it was fetched
+type chainHead struct {
+	block     int
+	fetchedAt time.Time
+}
+
+// record stores the latest block of the chain
+func (h *chainHeads) record(chainID, block int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.heads[chainID] = chainHead{block: block, fetchedAt: time.Now()}
+}
+
+// get returns the latest block of the chain if one was recorded within maxAge
+func (h *chainHeads) get(chainID int, maxAge time.Duration) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	head, ok := h.heads[chainID]
+	if !ok || time.Since(head.fetchedAt) > maxAge {
+		return 0, false
+	}
+	return head.block, true
+}
+
+// SetCache sets a persistent cache for transaction lists. Responses for a block range that
+// ended before the latest block are reused indefinitely; ranges reaching the latest block, or
+// beyond it, are refetched once older than ttl.
+func (c *Client) SetCache(cache ResponseCache, ttl time.Duration) {
+	c.cache = cache
+	c.cacheTTL = ttl
+}
+
+// cachedGet serves a request from the cache when possible, otherwise fetches it and stores
+// successful responses. A nil key, no cache or a failing cache falls back to a plain fetch.
+func (c *Client) cachedGet(endpoint string, key *responseKey) ([]byte, error) {
+	if key == nil || c.cache == nil {
+		return c.get(endpoint)
+	}
+
+	maxAge := time.Duration(0)
+	action := c.cacheAction(key.action)
+	if c.rangeOpen(key.endBlock) {
+		maxAge = c.cacheTTL
+		action += openRangeSuffix
+	}
+
+	body, ok, err := c.cache.Get(key.address, action, key.startBlock, key.endBlock, maxAge)
+	if err != nil && c.debug {
+		fmt.Printf("DEBUG: Cache read failed: %v\n", err)
+	}
+	if ok {
+		if c.debug {
+			fmt.Printf("DEBUG: Cache hit for %s %s [%d, %d]\n", action, key.address, key.startBlock, key.endBlock)
+		}
+		return body, nil
+	}
+
+	body, err = c.get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only successful responses are worth keeping; errors such as rate limits are transient
+	if checkResultError(body) == nil {
+		if err := c.cache.Put(key.address, action, key.startBlock, key.endBlock, body); err != nil && c.debug {
+			fmt.Printf("DEBUG: Cache write failed: %v\n", err)
+		}
+	}
+	return body, nil
+}
+
+// rangeOpen reports whether a block range ending at endBlock may still gain transactions: an
+// end block of 0 or at or above the latest block. The latest block is refetched once older
+// than the cache TTL; when it can't be fetched the range is treated as open.
+func (c *Client) rangeOpen(endBlock int) bool {
+	if endBlock <= 0 || endBlock >= LatestBlock {
+		return true
+	}
+
+	latest, ok := c.heads.get(c.chainID, c.cacheTTL)
+	if !ok {
+		var err error
+		if latest, err = c.GetLatestBlockNumber(); err != nil {
+			if c.debug {
+				fmt.Printf("DEBUG: Treating block range as open: %v\n", err)
+			}
+			return true
+		}
+	}
+	return endBlock >= latest
+}
@@ -0,0 +1,152 @@
+package etherscan
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testAddress = "0x1111111111111111111111111111111111111111"
+
+// memoryCache is an in-memory ResponseCache recording the lookups made
+type memoryCache struct {
+	mu      sync.Mutex
+	bodies  map[string][]byte
+	lookups []cacheLookup
+}
+
+// cacheLookup is one Get call on a memoryCache
+type cacheLookup struct {
+	action string
+	maxAge time.Duration
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{bodies: make(map[string][]byte)}
+}
+
+func (m *memoryCache) Get(address, action string, startBlock, endBlock int, maxAge time.Duration) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups = append(m.lookups, cacheLookup{action: action, maxAge: maxAge})
+	body, ok := m.bodies[fmt.Sprint(address, action, startBlock, endBlock)]
+	return body, ok, nil
+}
+
+func (m *memoryCache) Put(address, action string, startBlock, endBlock int, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bodies[fmt.Sprint(address, action, startBlock, endBlock)] = body
+	return nil
+}
+
+// lastLookup returns the most recent Get call
+func (m *memoryCache) lastLookup() cacheLookup {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lookups[len(m.lookups)-1]
+}
+
+// newCachedTestClient returns a client caching in a memoryCache, backed by a stub Etherscan at
+// block head that counts the transaction list requests it serves
+func newCachedTestClient(t *testing.T, head int, ttl time.Duration) (*Client, *memoryCache, *int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	listRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("action") {
+		case "eth_blockNumber":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":83,"result":"0x%x"}`, head)
+		default:
+			mu.Lock()
+			listRequests++
+			mu.Unlock()
+			fmt.Fprint(w, `{"status":"0","message":"No transactions found","result":[]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cache := newMemoryCache()
+	client := NewClient("TESTKEY", server.URL)
+	client.SetCache(cache, ttl)
+	return client, cache, &listRequests
+}
+
+func TestCachedGetTreatsEndBlocksFromTheHeadAsOpen(t *testing.T) {
+	const ttl = 10 * time.Minute
+	client, cache, _ := newCachedTestClient(t, 1000, ttl)
+
+	for _, tc := range []struct {
+		endBlock int
+		open     bool
+	}{
+		{0, true},
+		{LatestBlock, true},
+		{2000, true},
+		{1000, true},
+		{999, false},
+	} {
+		if _, err := client.GetNormalTransactions(testAddress, 0, tc.endBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		want := cacheLookup{action: "txlist", maxAge: 0}
+		if tc.open {
+			want = cacheLookup{action: "txlist" + openRangeSuffix, maxAge: ttl}
+		}
+		if got := cache.lastLookup(); got != want {
+			t.Errorf("end block %d: lookup %+v, want %+v", tc.endBlock, got, want)
+		}
+	}
+}
+
+func TestCachedGetRefetchesRangeClosedSinceFetched(t *testing.T) {
+	client, _, listRequests := newCachedTestClient(t, 1000, time.Hour)
+
+	// Fetched while block 1500 is yet to be mined, then again within the TTL
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetNormalTransactions(testAddress, 0, 1500); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *listRequests != 1 {
+		t.Fatalf("%d list requests for an open range within the TTL, want 1", *listRequests)
+	}
+
+	// Once the chain is past block 1500, the list fetched while open isn't reused
+	client.heads.record(0, 2000)
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetNormalTransactions(testAddress, 0, 1500); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *listRequests != 2 {
+		t.Errorf("%d list requests after the range closed, want 2 (one refetch, then cached)", *listRequests)
+	}
+}
+
+func TestCachedGetTreatsRangeAsOpenWithoutLatestBlock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("action") == "eth_blockNumber" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"status":"0","message":"No transactions found","result":[]}`)
+	}))
+	defer server.Close()
+
+	cache := newMemoryCache()
+	client := NewClient("TESTKEY", server.URL)
+	client.SetCache(cache, time.Minute)
+
+	if _, err := client.GetNormalTransactions(testAddress, 0, 500); err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.lastLookup(); got.maxAge != time.Minute {
+		t.Errorf("lookup max age %v, want the TTL when the latest block is unknown", got.maxAge)
+	}
+}
@@ -0,0 +1,79 @@
+package etherscan
+
+import (
+	"fmt"
+	"sync"
+)
+
+// chainContractCaches holds the contract lookup cache of each chain a client has been scoped to,
+// since the same address can be a contract on one chain and an account on another
+type chainContractCaches struct {
+	mu     sync.Mutex
+	caches map[int]*contractCache
+}
+
+// get returns the contract cache of a chain, creating it on first use
+func (cc *chainContractCaches) get(chainID int) *contractCache {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cache, ok := cc.caches[chainID]
+	if !ok {
+		cache = newContractCache()
+		cc.caches[chainID] = cache
+	}
+	return cache
+}
+
+// newContractCache creates an empty contract lookup cache
+func newContractCache() *contractCache {
+	return &contractCache{
+		isContract: make(map[string]bool),
+		names:      make(map[string]contractName),
+	}
+}
+
+// WithChain returns a client sharing c's configuration, rate limits and quota whose requests
+// target the given chain through the chainid parameter of the Etherscan V2 API. Contract
+// lookups and cached transaction lists are kept separately per chain.
+func (c *Client) WithChain(chainID int) *Client {
+	scoped := *c
+	scoped.chainID = chainID
+	scoped.contracts = c.chainContracts.get(chainID)
+	return &scoped
+}
+
+// DefaultNativeDecimals is the decimal count of a native token with no configured decimals, as Ether's
+const DefaultNativeDecimals = 18
+
+// SetNativeDecimals sets the decimals of the native token per chain ID, 0 being the endpoint's
+// default chain. Chains not listed use DefaultNativeDecimals. Decimals must be between 1 and 18,
+// as analyses keep raw amounts in Ether's 18 decimals.
+func (c *Client) SetNativeDecimals(decimals map[int]int) {
+	c.nativeDecimals = decimals
+}
+
+// NativeDecimals returns the decimals of the native token of the chain the client targets
+func (c *Client) NativeDecimals() int {
+	if decimals, ok := c.nativeDecimals[c.chainID]; ok {
+		return decimals
+	}
+	return DefaultNativeDecimals
+}
+
+// withChainParam adds the chain ID of a chain-scoped client to a request URL
+func (c *Client) withChainParam(endpoint string) string {
+	if c.chainID == 0 {
+		return endpoint
+	}
+	return fmt.Sprintf("%s&chainid=%d", endpoint, c.chainID)
+}
+
+// cacheAction is the action a transaction list is cached under, qualified by the chain for
+// chain-scoped clients so lists of different chains never collide
+func (c *Client) cacheAction(action string) string {
+	if c.chainID == 0 {
+		return action
+	}
+	return fmt.Sprintf("%s@%d", action, c.chainID)
+}
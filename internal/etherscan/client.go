@@ -2,82 +2,222 @@ package etherscan
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	// "log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/shrxyeh/ethereum-fund-flow/internal/version"
+	"github.com/shrxyeh/ethereum-fund-flow/pkg/logger"
 )
 
 const (
-	baseURL = "https://api.etherscan.io/api"
+	// DefaultBaseURL is the Etherscan mainnet API endpoint
+	DefaultBaseURL = "https://api.etherscan.io/api"
+
+	// LatestBlock is the end block used to query up to the chain head
+	LatestBlock = 99999999
 )
 
+// ErrInvalidAPIKey is returned when Etherscan rejects the configured API key
+var ErrInvalidAPIKey = errors.New("etherscan API key is invalid or expired")
+
 // Client is the Etherscan API client
 type Client struct {
 	apiKey     string
+	baseURL    string
 	httpClient *http.Client
+	contracts  *contractCache
 	debug      bool
+
+	// calls counts today's requests for quota reporting
+	calls *callCounter
+
+	// inFlight bounds the number of outstanding requests across all callers (nil means unlimited)
+	inFlight chan struct{}
+
+	// Slow call reporting
+	logger            logger.Logger
+	slowCallThreshold time.Duration
+
+	// Persistent transaction list cache (nil means disabled)
+	cache    ResponseCache
+	cacheTTL time.Duration
+
+	// Latest block seen per chain, telling the cache which block ranges are still open
+	heads *chainHeads
+
+	// Deadline and retry budget shared by every request of one analysis (nil means unbounded)
+	budget *requestBudget
+
+	// chainID selects the chain of an Etherscan V2 API request (0 means the endpoint's default)
+	chainID        int
+	chainContracts *chainContractCaches
+
+	// Native token decimals per chain ID, for chains that don't use Ether's 18
+	nativeDecimals map[int]int
+
+	// Recently fetched native token prices, shared with the chain-scoped clients
+	ethPrices *ethPriceCache
+
+	// userAgent is the User-Agent header sent on every request
+	userAgent string
+
+	// Attempts and per-attempt timeout of proxy module calls (0 means those of other calls)
+	proxyAttempts int
+	proxyTimeout  time.Duration
+
+	// providers, when fallbacks are configured, are tried in order for every request
+	// (nil means only the base URL is used)
+	providers *MultiProvider
 }
 
-// NewClient creates a new Etherscan client
-func NewClient(apiKey string) *Client {
+// NewClient creates a new Etherscan client for the given Etherscan-compatible base URL.
+// An empty base URL falls back to DefaultBaseURL.
+func NewClient(apiKey, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
 	return &Client{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second, // Increase timeout to 60 seconds
 		},
-		debug: true, // Enable debug logging
+		contracts:      newContractCache(),
+		chainContracts: &chainContractCaches{caches: make(map[int]*contractCache)},
+		calls:          &callCounter{},
+		ethPrices:      &ethPriceCache{prices: make(map[int]cachedETHPrice)},
+		heads:          &chainHeads{heads: make(map[int]chainHead)},
+		userAgent:      version.UserAgent(),
+		debug:          true, // Enable debug logging
+	}
+}
+
+// SetMaxConcurrentRequests bounds the number of requests in flight at once across every
+// analysis sharing this client (0 means unlimited)
+func (c *Client) SetMaxConcurrentRequests(n int) {
+	if n <= 0 {
+		c.inFlight = nil
+		return
+	}
+	c.inFlight = make(chan struct{}, n)
+}
+
+// SetUserAgent sets the User-Agent header sent on every request (empty restores the default)
+func (c *Client) SetUserAgent(userAgent string) {
+	if userAgent == "" {
+		userAgent = version.UserAgent()
+	}
+	c.userAgent = userAgent
+}
+
+// SetFallbackProviders sets the providers tried, in order, when the base URL is rate-limited,
+// failing or unreachable. Providers that fail are skipped for a cooldown. No providers
+// restores using only the base URL. Fallbacks only serve the endpoint's default chain; requests
+// of a chain-scoped client (WithChain) always use the base URL.
+func (c *Client) SetFallbackProviders(fallbacks ...TransactionProvider) {
+	if len(fallbacks) == 0 {
+		c.providers = nil
+		return
+	}
+
+	primary := c.baseURL
+	if u, err := url.Parse(c.baseURL); err == nil && u.Host != "" {
+		primary = u.Host
+	}
+	providers := append([]TransactionProvider{NewExplorer(primary, c.baseURL, c.apiKey)}, fallbacks...)
+	c.providers = NewMultiProvider(providers...)
+}
+
+// ProviderStatus reports the health of the base URL and the fallback providers, in the order
+// they are tried (nil without fallbacks)
+func (c *Client) ProviderStatus() []ProviderStatus {
+	if c.providers == nil {
+		return nil
+	}
+	return c.providers.Status()
+}
+
+// SetProxyRetry sets the number of attempts and the per-attempt timeout of proxy module
+// (JSON-RPC) calls, which return a single small object and can fail faster than transaction
+// lists. Zero keeps the values used for other calls.
+func (c *Client) SetProxyRetry(attempts int, timeout time.Duration) {
+	c.proxyAttempts = attempts
+	c.proxyTimeout = timeout
+}
+
+// SetLogger sets the logger used to report slow calls
+func (c *Client) SetLogger(l logger.Logger) {
+	c.logger = l
+}
+
+// SetSlowCallThreshold sets the latency above which a call is logged as slow (0 disables it)
+func (c *Client) SetSlowCallThreshold(threshold time.Duration) {
+	c.slowCallThreshold = threshold
+}
+
+// SetHTTPProxy routes every Etherscan request through the given proxy URL, taking precedence
+// over the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables. An empty URL restores
+// the default environment-based proxy selection.
+func (c *Client) SetHTTPProxy(proxyURL string) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
 	}
+	c.httpClient.Transport = transport
+	return nil
 }
 
-// GetNormalTransactions fetches normal transactions for an address with pagination
-func (c *Client) GetNormalTransactions(address string) ([]Transaction, error) {
-	endpoint := fmt.Sprintf("%s?module=account&action=txlist&address=%s&startblock=0&endblock=99999999&page=1&offset=100&sort=desc&apikey=%s",
-		baseURL, address, c.apiKey)
+// GetNormalTransactions fetches normal transactions for an address within a block range with pagination
+func (c *Client) GetNormalTransactions(address string, startBlock, endBlock int) ([]Transaction, error) {
+	endpoint := fmt.Sprintf("%s?module=account&action=txlist&address=%s&startblock=%d&endblock=%d&page=1&offset=100&sort=desc&apikey=%s",
+		c.baseURL, address, startBlock, c.endBlock(endBlock), c.apiKey)
 	
 	if c.debug {
 		fmt.Printf("DEBUG: Fetching normal transactions for address: %s\n", address)
 		fmt.Printf("DEBUG: API endpoint: %s\n", endpoint)
 	}
 		
-	return c.fetchTransactions(endpoint)
+	return c.fetchTransactions(endpoint, listKey(address, "txlist", startBlock, endBlock))
 }
 
-// GetInternalTransactions fetches internal transactions for an address with pagination
-func (c *Client) GetInternalTransactions(address string) ([]Transaction, error) {
-	endpoint := fmt.Sprintf("%s?module=account&action=txlistinternal&address=%s&startblock=0&endblock=99999999&page=1&offset=100&sort=desc&apikey=%s",
-		baseURL, address, c.apiKey)
+// GetInternalTransactions fetches internal transactions for an address within a block range with pagination
+func (c *Client) GetInternalTransactions(address string, startBlock, endBlock int) ([]Transaction, error) {
+	endpoint := fmt.Sprintf("%s?module=account&action=txlistinternal&address=%s&startblock=%d&endblock=%d&page=1&offset=100&sort=desc&apikey=%s",
+		c.baseURL, address, startBlock, c.endBlock(endBlock), c.apiKey)
 	
 	if c.debug {
 		fmt.Printf("DEBUG: Fetching internal transactions for address: %s\n", address)
 		fmt.Printf("DEBUG: API endpoint: %s\n", endpoint)
 	}
 		
-	return c.fetchTransactions(endpoint)
+	return c.fetchTransactions(endpoint, listKey(address, "txlistinternal", startBlock, endBlock))
 }
 
-// GetTokenTransfers fetches token transfers (ERC-20, ERC-721, ERC-1155) for an address with pagination
-func (c *Client) GetTokenTransfers(address string) ([]TokenTransfer, error) {
-	endpoint := fmt.Sprintf("%s?module=account&action=tokentx&address=%s&startblock=0&endblock=99999999&page=1&offset=100&sort=desc&apikey=%s",
-		baseURL, address, c.apiKey)
+// GetTokenTransfers fetches token transfers (ERC-20, ERC-721, ERC-1155) for an address within a block range with pagination
+func (c *Client) GetTokenTransfers(address string, startBlock, endBlock int) ([]TokenTransfer, error) {
+	endpoint := fmt.Sprintf("%s?module=account&action=tokentx&address=%s&startblock=%d&endblock=%d&page=1&offset=100&sort=desc&apikey=%s",
+		c.baseURL, address, startBlock, c.endBlock(endBlock), c.apiKey)
 	
 	if c.debug {
 		fmt.Printf("DEBUG: Fetching token transfers for address: %s\n", address)
 		fmt.Printf("DEBUG: API endpoint: %s\n", endpoint)
 	}
 		
-	resp, err := c.httpClient.Get(endpoint)
+	body, err := c.cachedGet(endpoint, listKey(address, "tokentx", startBlock, endBlock))
 	if err != nil {
 		return nil, fmt.Errorf("error fetching token transfers: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
-	}
 
 	if c.debug {
 		// Print the first 500 characters of the response for debugging
@@ -88,35 +228,21 @@ func (c *Client) GetTokenTransfers(address string) ([]TokenTransfer, error) {
 		fmt.Printf("DEBUG: Token transfers response: %s\n", responsePreview)
 	}
 
-	// First try to parse as a standard response with array result
+	// Error responses carry a message string instead of an array result
+	if err := checkResultError(body); err != nil {
+		return nil, err
+	}
+
 	var result TokenTransferResponse
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
-	// Handle error response where result might be a string instead of an array
 	if result.Status == "0" {
 		// Check if "No transactions found" - this is not really an error
 		if result.Message == "No transactions found" {
 			return []TokenTransfer{}, nil
 		}
-		
-		// Try to parse as error response where Result is a string
-		var errorResult struct {
-			Status  string `json:"status"`
-			Message string `json:"message"`
-			Result  string `json:"result"`
-		}
-		
-		if jsonErr := json.Unmarshal(body, &errorResult); jsonErr == nil && errorResult.Result != "" {
-			// Handle rate limit errors
-			if errorResult.Result == "Max rate limit reached" {
-				return nil, fmt.Errorf("etherscan API rate limit exceeded, please try again later")
-			}
-			return nil, fmt.Errorf("etherscan API error: %s", errorResult.Result)
-		}
-		
-		// Generic error
 		return nil, fmt.Errorf("etherscan API error: %s", result.Message)
 	}
 
@@ -133,34 +259,20 @@ func (c *Client) GetTokenTransfers(address string) ([]TokenTransfer, error) {
 	return result.Result, nil
 }
 
-// fetchTransactions is a helper function to fetch and parse transaction data
-func (c *Client) fetchTransactions(endpoint string) ([]Transaction, error) {
-	// Add retry with exponential backoff
-	maxRetries := 3
-	var resp *http.Response
-	var err error
-	
-	for retry := 0; retry < maxRetries; retry++ {
-		if retry > 0 {
-			// Wait before retrying (exponential backoff)
-			wait := time.Duration(retry * retry) * time.Second
-			time.Sleep(wait)
-		}
-		
-		resp, err = c.httpClient.Get(endpoint)
-		if err == nil {
-			break
-		}
-	}
-	
-	if err != nil {
-		return nil, fmt.Errorf("error fetching transactions after %d retries: %w", maxRetries, err)
+// endBlock returns the end block to query, treating 0 as the latest block
+func (c *Client) endBlock(endBlock int) int {
+	if endBlock <= 0 {
+		return LatestBlock
 	}
-	defer resp.Body.Close()
+	return endBlock
+}
 
-	body, err := io.ReadAll(resp.Body)
+// fetchTransactions is a helper function to fetch and parse transaction data; key enables the
+// persistent cache for the request (nil bypasses it)
+func (c *Client) fetchTransactions(endpoint string, key *responseKey) ([]Transaction, error) {
+	body, err := c.cachedGet(endpoint, key)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, fmt.Errorf("error fetching transactions: %w", err)
 	}
 
 	if c.debug {
@@ -172,24 +284,14 @@ func (c *Client) fetchTransactions(endpoint string) ([]Transaction, error) {
 		fmt.Printf("DEBUG: Transaction response: %s\n", responsePreview)
 	}
 
+	// Error responses carry a message string instead of an array result
+	if err := checkResultError(body); err != nil {
+		return nil, err
+	}
+
 	// parse as a standard response with array result
 	var result TransactionResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		// Check if it's a response with a non-array result (like error messages)
-		var errorResult struct {
-			Status  string `json:"status"`
-			Message string `json:"message"`
-			Result  string `json:"result"`
-		}
-		
-		if jsonErr := json.Unmarshal(body, &errorResult); jsonErr == nil && errorResult.Result != "" {
-			// Handle rate limit errors
-			if errorResult.Result == "Max rate limit reached" {
-				return nil, fmt.Errorf("etherscan API rate limit exceeded, please try again later")
-			}
-			return nil, fmt.Errorf("etherscan API error: %s", errorResult.Result)
-		}
-		
 		return nil, fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
@@ -226,49 +328,43 @@ func (c *Client) fetchTransactions(endpoint string) ([]Transaction, error) {
 	return result.Result, nil
 }
 
+// ValidateAPIKey makes a single cheap request to check that Etherscan accepts the API key
+func (c *Client) ValidateAPIKey() error {
+	endpoint := fmt.Sprintf("%s?module=account&action=balance&address=0x0000000000000000000000000000000000000000&tag=latest&apikey=%s",
+		c.baseURL, c.apiKey)
+
+	body, err := c.get(endpoint)
+	if err != nil {
+		return fmt.Errorf("error validating API key: %w", err)
+	}
+
+	return checkResultError(body)
+}
+
 // GetLatestBlockNumber fetches the latest block number
 func (c *Client) GetLatestBlockNumber() (int, error) {
-	endpoint := fmt.Sprintf("%s?module=proxy&action=eth_blockNumber&apikey=%s", baseURL, c.apiKey)
+	endpoint := fmt.Sprintf("%s?module=proxy&action=eth_blockNumber&apikey=%s", c.baseURL, c.apiKey)
 	
 	if c.debug {
 		fmt.Printf("DEBUG: Fetching latest block number\n")
 		fmt.Printf("DEBUG: API endpoint: %s\n", endpoint)
 	}
-	
-	resp, err := c.httpClient.Get(endpoint)
-	if err != nil {
-		return 0, fmt.Errorf("error fetching latest block: %w", err)
-	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error reading response body: %w", err)
-	}
-
-	if c.debug {
-		fmt.Printf("DEBUG: Latest block response: %s\n", string(body))
-	}
-
-	var result struct {
-		JsonRPC string `json:"jsonrpc"`
-		ID      int    `json:"id"`
-		Result  string `json:"result"`
-	}
-	
-	if err := json.Unmarshal(body, &result); err != nil {
-		return 0, fmt.Errorf("error unmarshaling response: %w", err)
+	var result string
+	if err := c.proxyResult(endpoint, &result); err != nil {
+		return 0, fmt.Errorf("error fetching latest block: %w", err)
 	}
 
 	// Convert hex string to int
-	blockNumber, err := strconv.ParseInt(result.Result[2:], 16, 64)
+	blockNumber, err := strconv.ParseInt(strings.TrimPrefix(result, "0x"), 16, 64)
 	if err != nil {
-		return 0, fmt.Errorf("error parsing block number: %w", err)
+		return 0, fmt.Errorf("error parsing block number %q: %w", result, err)
 	}
 
 	if c.debug {
 		fmt.Printf("DEBUG: Latest block number: %d\n", blockNumber)
 	}
+	c.heads.record(c.chainID, int(blockNumber))
 
 	return int(blockNumber), nil
 }
\ No newline at end of file
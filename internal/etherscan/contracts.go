@@ -0,0 +1,107 @@
+package etherscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// contractCache caches contract lookups, which never change for an address
+type contractCache struct {
+	mu         sync.RWMutex
+	isContract map[string]bool
+	names      map[string]contractName
+}
+
+// contractName is the cached verified name of a contract
+type contractName struct {
+	name     string
+	verified bool
+}
+
+// IsContract reports whether an address holds contract code, using eth_getCode.
+// Results are cached per address since deployed code never changes.
+func (c *Client) IsContract(address string) (bool, error) {
+	key := strings.ToLower(address)
+
+	c.contracts.mu.RLock()
+	isContract, cached := c.contracts.isContract[key]
+	c.contracts.mu.RUnlock()
+	if cached {
+		return isContract, nil
+	}
+
+	endpoint := fmt.Sprintf("%s?module=proxy&action=eth_getCode&address=%s&tag=latest&apikey=%s",
+		c.baseURL, address, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching code for address: %s\n", address)
+	}
+
+	var code string
+	if err := c.proxyResult(endpoint, &code); err != nil {
+		return false, fmt.Errorf("error fetching code: %w", err)
+	}
+
+	isContract = code != "0x"
+
+	c.contracts.mu.Lock()
+	c.contracts.isContract[key] = isContract
+	c.contracts.mu.Unlock()
+
+	return isContract, nil
+}
+
+// GetContractName returns the verified contract name of an address, e.g. "UniswapV2Router02",
+// using the getsourcecode action. It reports false when the address has no verified source.
+// Results are cached per address since verified source never changes.
+func (c *Client) GetContractName(address string) (string, bool, error) {
+	key := strings.ToLower(address)
+
+	c.contracts.mu.RLock()
+	cached, ok := c.contracts.names[key]
+	c.contracts.mu.RUnlock()
+	if ok {
+		return cached.name, cached.verified, nil
+	}
+
+	endpoint := fmt.Sprintf("%s?module=contract&action=getsourcecode&address=%s&apikey=%s",
+		c.baseURL, address, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching contract source for address: %s\n", address)
+	}
+
+	body, err := c.get(endpoint)
+	if err != nil {
+		return "", false, fmt.Errorf("error fetching contract source: %w", err)
+	}
+
+	// Error responses carry a message string instead of an array result
+	if err := checkResultError(body); err != nil {
+		return "", false, err
+	}
+
+	var result struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Result  []struct {
+			ContractName string `json:"ContractName"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", false, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+
+	var name contractName
+	if len(result.Result) > 0 && result.Result[0].ContractName != "" {
+		name = contractName{name: result.Result[0].ContractName, verified: true}
+	}
+
+	c.contracts.mu.Lock()
+	c.contracts.names[key] = name
+	c.contracts.mu.Unlock()
+
+	return name.name, name.verified, nil
+}
@@ -0,0 +1,126 @@
+package etherscan
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// maxResultsPerQuery is the Etherscan hard cap on results returned by a single query,
+// regardless of the page and offset requested
+const maxResultsPerQuery = 10000
+
+// GetAllNormalTransactions fetches the complete normal transaction history for an address
+// within a block range, oldest first, walking past the 10,000-result cap as described for
+// StreamNormalTransactions
+func (c *Client) GetAllNormalTransactions(address string, startBlock, endBlock int) ([]Transaction, error) {
+	var all []Transaction
+	err := c.StreamNormalTransactions(address, startBlock, endBlock, func(page []Transaction) error {
+		all = append(all, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// GetEarliestNormalTransactions fetches the first normal transactions of an address, up to
+// limit of them, oldest first
+func (c *Client) GetEarliestNormalTransactions(address string, limit int) ([]Transaction, error) {
+	endpoint := fmt.Sprintf("%s?module=account&action=txlist&address=%s&startblock=0&endblock=%d&page=1&offset=%d&sort=asc&apikey=%s",
+		c.baseURL, address, LatestBlock, limit, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching earliest normal transactions for address: %s\n", address)
+	}
+
+	return c.fetchTransactions(endpoint, nil)
+}
+
+// StreamNormalTransactions walks the complete normal transaction history for an address
+// within a block range, oldest first, handing each page to fn as it arrives so callers can
+// aggregate without holding the whole history. Etherscan returns at most 10,000 results per
+// query, so each full window restarts at its last block, and that block's transactions are
+// dropped from the window since they may have been cut off part way. Records already handed
+// over are skipped, so an overlap between windows never reaches fn twice. An error from fn
+// stops the walk and is returned.
+func (c *Client) StreamNormalTransactions(address string, startBlock, endBlock int, fn func(page []Transaction) error) error {
+	endBlock = c.endBlock(endBlock)
+	seen := newPageDeduper()
+
+	for startBlock <= endBlock {
+		endpoint := fmt.Sprintf("%s?module=account&action=txlist&address=%s&startblock=%d&endblock=%d&page=1&offset=%d&sort=asc&apikey=%s",
+			c.baseURL, address, startBlock, endBlock, maxResultsPerQuery, c.apiKey)
+
+		if c.debug {
+			fmt.Printf("DEBUG: Fetching normal transactions for address %s from block %d\n", address, startBlock)
+		}
+
+		txs, err := c.fetchTransactions(endpoint, nil)
+		if err != nil {
+			return err
+		}
+
+		// A short window means the rest of the range has been fetched
+		if len(txs) < maxResultsPerQuery {
+			return fn(seen.filter(txs))
+		}
+
+		firstBlock, err := strconv.Atoi(txs[0].BlockNumber)
+		if err != nil {
+			return fmt.Errorf("error parsing block number %q: %w", txs[0].BlockNumber, err)
+		}
+		lastBlock, err := strconv.Atoi(txs[len(txs)-1].BlockNumber)
+		if err != nil {
+			return fmt.Errorf("error parsing block number %q: %w", txs[len(txs)-1].BlockNumber, err)
+		}
+
+		// The window can't be split any further when it holds a single block
+		if firstBlock == lastBlock {
+			return fmt.Errorf("block %d has more than %d transactions for address %s", lastBlock, maxResultsPerQuery, address)
+		}
+
+		// Hand over everything before the last block, which is fetched again in full by the next window
+		complete := len(txs)
+		for complete > 0 && txs[complete-1].BlockNumber == txs[len(txs)-1].BlockNumber {
+			complete--
+		}
+		if err := fn(seen.filter(txs[:complete])); err != nil {
+			return err
+		}
+		startBlock = lastBlock
+	}
+
+	return nil
+}
+
+// pageDeduper drops transactions already handed over by an earlier window or page. Only the
+// hashes of the last block handed over are remembered, since windows never reach further
+// back than that, so memory stays bounded by one block's transactions.
+type pageDeduper struct {
+	seen      map[string]bool
+	lastBlock string
+}
+
+// newPageDeduper creates a deduper with nothing handed over yet
+func newPageDeduper() *pageDeduper {
+	return &pageDeduper{seen: make(map[string]bool)}
+}
+
+// filter returns the transactions not handed over before, in order, and remembers them
+func (d *pageDeduper) filter(txs []Transaction) []Transaction {
+	fresh := make([]Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if d.seen[tx.Hash] {
+			continue
+		}
+		if tx.BlockNumber != d.lastBlock {
+			// Moving on to a later block: the earlier ones can't be returned again
+			clear(d.seen)
+			d.lastBlock = tx.BlockNumber
+		}
+		d.seen[tx.Hash] = true
+		fresh = append(fresh, tx)
+	}
+	return fresh
+}
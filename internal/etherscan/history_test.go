@@ -0,0 +1,142 @@
+package etherscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newHistoryServer returns a stub Etherscan serving txs, sorted by block, like txlist does:
+// those within the requested block range, oldest first, at most offset of them. A positive
+// lookback starts each window that many blocks before the requested start block, as a provider
+// repeating records across the boundary does.
+func newHistoryServer(t *testing.T, txs []Transaction, lookback int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		startBlock, _ := strconv.Atoi(query.Get("startblock"))
+		startBlock -= lookback
+		endBlock, _ := strconv.Atoi(query.Get("endblock"))
+		offset, _ := strconv.Atoi(query.Get("offset"))
+
+		var window []Transaction
+		for _, tx := range txs {
+			block, _ := strconv.Atoi(tx.BlockNumber)
+			if block >= startBlock && block <= endBlock && len(window) < offset {
+				window = append(window, tx)
+			}
+		}
+		json.NewEncoder(w).Encode(TransactionResponse{Status: "1", Message: "OK", Result: window})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// boundaryHistory returns one transaction in each of the first blocks, then 5 in the block where
+// the first window is cut off after 2 of them, overlapping the second window, and 3 in the
+// block after it
+func boundaryHistory() []Transaction {
+	var txs []Transaction
+	addTx := func(block int) {
+		txs = append(txs, Transaction{Hash: fmt.Sprintf("0x%064x", len(txs)), BlockNumber: strconv.Itoa(block)})
+	}
+	for block := 1; block < boundaryBlock; block++ {
+		addTx(block)
+	}
+	for i := 0; i < 5; i++ {
+		addTx(boundaryBlock)
+	}
+	for i := 0; i < 3; i++ {
+		addTx(boundaryBlock + 1)
+	}
+	return txs
+}
+
+// boundaryBlock is the block the first window of boundaryHistory is cut off in
+const boundaryBlock = maxResultsPerQuery - 1
+
+// streamPages walks the history served with the given lookback and returns the pages handed over
+func streamPages(t *testing.T, txs []Transaction, lookback int) [][]Transaction {
+	t.Helper()
+
+	client := NewClient("TESTKEY", newHistoryServer(t, txs, lookback).URL)
+	client.debug = false
+
+	var pages [][]Transaction
+	err := client.StreamNormalTransactions(testAddress, 0, 0, func(page []Transaction) error {
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pages
+}
+
+// checkHandedOverOnce fails the test unless the pages hold every transaction exactly once
+func checkHandedOverOnce(t *testing.T, pages [][]Transaction, txs []Transaction) {
+	t.Helper()
+
+	seen := make(map[string]bool)
+	count := 0
+	for _, page := range pages {
+		for _, tx := range page {
+			if seen[tx.Hash] {
+				t.Fatalf("transaction %s of block %s handed over twice", tx.Hash, tx.BlockNumber)
+			}
+			seen[tx.Hash] = true
+			count++
+		}
+	}
+	if count != len(txs) {
+		t.Errorf("%d transactions handed over, want all %d", count, len(txs))
+	}
+}
+
+func TestGetAllNormalTransactionsAcrossBoundaryBlock(t *testing.T) {
+	txs := boundaryHistory()
+	pages := streamPages(t, txs, 0)
+
+	if len(pages) != 2 {
+		t.Fatalf("%d pages, want 2", len(pages))
+	}
+	if last := pages[0][len(pages[0])-1].BlockNumber; last != strconv.Itoa(boundaryBlock-1) {
+		t.Errorf("first page ends at block %s, want %d: the cut-off boundary block belongs to the next", last, boundaryBlock-1)
+	}
+	checkHandedOverOnce(t, pages, txs)
+}
+
+func TestGetAllNormalTransactionsSkipsRecordsRepeatedAcrossWindows(t *testing.T) {
+	// The second window also returns the last block already handed over
+	txs := boundaryHistory()
+	pages := streamPages(t, txs, 1)
+
+	if len(pages) != 2 {
+		t.Fatalf("%d pages, want 2", len(pages))
+	}
+	if first := pages[1][0].BlockNumber; first != strconv.Itoa(boundaryBlock) {
+		t.Errorf("second page starts at block %s, want %d: block %d was handed over already", first, boundaryBlock, boundaryBlock-1)
+	}
+	checkHandedOverOnce(t, pages, txs)
+}
+
+func TestPageDeduperFilter(t *testing.T) {
+	tx := func(hash, block string) Transaction {
+		return Transaction{Hash: hash, BlockNumber: block}
+	}
+
+	d := newPageDeduper()
+	if got := d.filter([]Transaction{tx("0xa", "1"), tx("0xb", "2"), tx("0xc", "2")}); len(got) != 3 {
+		t.Fatalf("first page kept %d of 3", len(got))
+	}
+
+	// The repeated records of block 2 are dropped, a new one of it is kept
+	got := d.filter([]Transaction{tx("0xb", "2"), tx("0xc", "2"), tx("0xd", "2"), tx("0xe", "3")})
+	if len(got) != 2 || got[0].Hash != "0xd" || got[1].Hash != "0xe" {
+		t.Errorf("second page kept %+v, want 0xd and 0xe", got)
+	}
+}
@@ -3,6 +3,7 @@ package etherscan
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,6 +26,8 @@ type Transaction struct {
 	Hash              string `json:"hash"`
 	BlockNumber       string `json:"blockNumber"`
 	TimeStamp         string `json:"timeStamp"`
+	Nonce             string `json:"nonce"`
+	TransactionIndex  string `json:"transactionIndex"` // Position within the block
 	From              string `json:"from"`
 	To                string `json:"to"`
 	Value             string `json:"value"`
@@ -37,6 +40,12 @@ type Transaction struct {
 	CumulativeGasUsed string `json:"cumulativeGasUsed"`
 	GasUsed           string `json:"gasUsed"`
 	Confirmations     string `json:"confirmations"`
+
+	// FunctionName is the signature of the called function when Etherscan knows it (normal transactions)
+	FunctionName string `json:"functionName"`
+
+	// Type is the call type of an internal transaction (call, create, suicide, ...); empty for normal transactions
+	Type string `json:"type"`
 }
 
 // TokenTransfer represents an ERC-20/ERC-721/ERC-1155 token transfer
@@ -44,6 +53,8 @@ type TokenTransfer struct {
 	Hash              string `json:"hash"`
 	BlockNumber       string `json:"blockNumber"`
 	TimeStamp         string `json:"timeStamp"`
+	Nonce             string `json:"nonce"`
+	TransactionIndex  string `json:"transactionIndex"` // Position within the block
 	From              string `json:"from"`
 	To                string `json:"to"`
 	Value             string `json:"value"`
@@ -58,20 +69,95 @@ type TokenTransfer struct {
 	Confirmations     string `json:"confirmations"`
 }
 
-// FormatTime formats the timestamp from the Etherscan API
-func FormatTime(timestamp string) (string, error) {
-	fmt.Printf("DEBUG: FormatTime called with timestamp: %s\n", timestamp)
-	
+// ProxyTransaction represents a transaction returned by eth_getTransactionByHash.
+// Numeric fields are hex-encoded.
+type ProxyTransaction struct {
+	Hash        string `json:"hash"`
+	BlockNumber string `json:"blockNumber"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+	Input       string `json:"input"`
+}
+
+// ETHPrice represents the current native token price returned by the stats module's ethprice
+type ETHPrice struct {
+	EthBTC          string `json:"ethbtc"`
+	EthBTCTimestamp string `json:"ethbtc_timestamp"`
+	EthUSD          string `json:"ethusd"`
+	EthUSDTimestamp string `json:"ethusd_timestamp"`
+}
+
+// ProxyBlock represents the header fields of a block returned by eth_getBlockByNumber.
+// Numeric fields are hex-encoded.
+type ProxyBlock struct {
+	Number    string `json:"number"`
+	Timestamp string `json:"timestamp"`
+}
+
+// TransactionReceipt represents a receipt returned by eth_getTransactionReceipt.
+// Numeric fields are hex-encoded.
+type TransactionReceipt struct {
+	TransactionHash string `json:"transactionHash"`
+	BlockNumber     string `json:"blockNumber"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Status          string `json:"status"`
+	GasUsed         string `json:"gasUsed"`
+	Logs            []Log  `json:"logs"`
+}
+
+// Log represents an event log emitted by a transaction
+type Log struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+	LogIndex string   `json:"logIndex"`
+}
+
+// FormatTime formats the timestamp from the Etherscan API as an ISO-8601 (RFC 3339) string
+// in the given location. A nil location formats in UTC. Empty or zero timestamps yield an
+// empty string rather than the Unix epoch.
+func FormatTime(timestamp string, loc *time.Location) (string, error) {
+	if timestamp == "" || timestamp == "0" {
+		return "", nil
+	}
+
 	unixTime, err := strconv.ParseInt(timestamp, 10, 64)
 	if err != nil {
 		return "", fmt.Errorf("error parsing timestamp: %w", err)
 	}
-	
-	t := time.Unix(unixTime, 0)
-	
-	formatted := t.Format("2006-01-02 15:04:05")
-	
-	fmt.Printf("DEBUG: Formatted timestamp: %s -> %s\n", timestamp, formatted)
-	
-	return formatted, nil
-}
\ No newline at end of file
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return time.Unix(unixTime, 0).In(loc).Format(time.RFC3339), nil
+}
+
+// IsValidAddress reports whether address is a 0x-prefixed, 20-byte hex Ethereum address.
+// Mixed-case (checksummed) addresses are accepted without verifying the checksum.
+func IsValidAddress(address string) bool {
+	if len(address) != 42 || !strings.HasPrefix(address, "0x") && !strings.HasPrefix(address, "0X") {
+		return false
+	}
+	for _, c := range address[2:] {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidTransactionHash reports whether hash is a 0x-prefixed, 32-byte hex transaction hash
+func IsValidTransactionHash(hash string) bool {
+	if len(hash) != 66 || !strings.HasPrefix(hash, "0x") && !strings.HasPrefix(hash, "0X") {
+		return false
+	}
+	for _, c := range hash[2:] {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
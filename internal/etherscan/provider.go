@@ -0,0 +1,207 @@
+package etherscan
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// A failing provider is skipped for providerCooldown, doubling with each consecutive
+	// failure up to maxProviderCooldown
+	providerCooldown    = 30 * time.Second
+	maxProviderCooldown = 5 * time.Minute
+)
+
+// TransactionProvider is an upstream able to serve the client's Etherscan API queries
+type TransactionProvider interface {
+	// Name identifies the provider in logs and health reports
+	Name() string
+	// URL returns the request URL for the given query, adding the provider's credentials
+	URL(query url.Values) string
+}
+
+// Explorer is a TransactionProvider for an Etherscan-compatible explorer API, such as
+// Etherscan itself, another explorer of the Etherscan family or a Blockscout instance
+type Explorer struct {
+	name    string
+	baseURL string
+	apiKey  string
+}
+
+// NewExplorer creates a provider for the explorer API at baseURL (an empty API key sends none)
+func NewExplorer(name, baseURL, apiKey string) *Explorer {
+	return &Explorer{name: name, baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey}
+}
+
+// ParseExplorer creates a provider from an explorer API URL, taking the API key from its
+// apikey query parameter and the name from its host
+func ParseExplorer(rawURL string) (*Explorer, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid provider URL %q: must be an absolute http(s) URL", redactAPIKey(rawURL))
+	}
+
+	query := u.Query()
+	apiKey := query.Get("apikey")
+	query.Del("apikey")
+	u.RawQuery = query.Encode()
+	return NewExplorer(u.Host, u.String(), apiKey), nil
+}
+
+// Name returns the explorer's name
+func (e *Explorer) Name() string {
+	return e.name
+}
+
+// URL returns the explorer request URL for the query, replacing any API key it carries
+func (e *Explorer) URL(query url.Values) string {
+	q := url.Values{}
+	for key, values := range query {
+		q[key] = values
+	}
+	q.Del("apikey")
+	if e.apiKey != "" {
+		q.Set("apikey", e.apiKey)
+	}
+
+	u, err := url.Parse(e.baseURL)
+	if err != nil {
+		return e.baseURL + "?" + q.Encode()
+	}
+	// Keep parameters the base URL itself carries
+	for key, values := range u.Query() {
+		if !q.Has(key) {
+			q[key] = values
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// ProviderStatus is the health of one provider of a MultiProvider
+type ProviderStatus struct {
+	Name                string `json:"name"`
+	Healthy             bool   `json:"healthy"`
+	ConsecutiveFailures int    `json:"consecutive_failures,omitempty"`
+	LastError           string `json:"last_error,omitempty"`
+	RetryAt             string `json:"retry_at,omitempty"`
+}
+
+// providerHealth tracks the recent failures of one provider
+type providerHealth struct {
+	failures       int
+	unhealthyUntil time.Time
+	lastError      string
+}
+
+// MultiProvider tries its providers in order, skipping those that failed recently. A provider
+// becomes healthy again after its cooldown or on its next success.
+type MultiProvider struct {
+	providers []TransactionProvider
+
+	mu     sync.Mutex
+	health []providerHealth
+}
+
+// NewMultiProvider creates a MultiProvider trying the providers in the given order
+func NewMultiProvider(providers ...TransactionProvider) *MultiProvider {
+	return &MultiProvider{
+		providers: providers,
+		health:    make([]providerHealth, len(providers)),
+	}
+}
+
+// order returns the indexes of the providers to try: the healthy ones in order, then the
+// unhealthy ones soonest to recover first, so a request is still attempted when all are failing
+func (m *MultiProvider) order() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var healthy, unhealthy []int
+	for i := range m.providers {
+		if m.health[i].unhealthyUntil.After(now) {
+			unhealthy = append(unhealthy, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	sort.SliceStable(unhealthy, func(a, b int) bool {
+		return m.health[unhealthy[a]].unhealthyUntil.Before(m.health[unhealthy[b]].unhealthyUntil)
+	})
+	return append(healthy, unhealthy...)
+}
+
+// markSuccess resets the provider's health
+func (m *MultiProvider) markSuccess(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health[i] = providerHealth{}
+}
+
+// markFailure records a failure, putting the provider on an escalating cooldown
+func (m *MultiProvider) markFailure(i int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := &m.health[i]
+	h.failures++
+	cooldown := maxProviderCooldown
+	if h.failures <= 10 {
+		if c := providerCooldown << (h.failures - 1); c < maxProviderCooldown {
+			cooldown = c
+		}
+	}
+	h.unhealthyUntil = time.Now().Add(cooldown)
+	h.lastError = errorMessage(err)
+}
+
+// Status reports the health of every provider, in order
+func (m *MultiProvider) Status() []ProviderStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]ProviderStatus, len(m.providers))
+	for i, p := range m.providers {
+		h := m.health[i]
+		statuses[i] = ProviderStatus{
+			Name:                p.Name(),
+			Healthy:             !h.unhealthyUntil.After(now),
+			ConsecutiveFailures: h.failures,
+			LastError:           h.lastError,
+		}
+		if !statuses[i].Healthy {
+			statuses[i].RetryAt = h.unhealthyUntil.UTC().Format(time.RFC3339)
+		}
+	}
+	return statuses
+}
+
+// shouldFallBack reports whether a provider's failure is worth trying the next provider for:
+// rate limits, server errors and transport failures. Other 4xx responses would fail the same
+// way everywhere.
+func shouldFallBack(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.retryable()
+	}
+	return true
+}
+
+// errorMessage returns the error's message with the API key of any request URL it quotes redacted
+func errorMessage(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Sprintf("%s %q: %v", urlErr.Op, redactAPIKey(urlErr.URL), urlErr.Err)
+	}
+	return err.Error()
+}
@@ -0,0 +1,43 @@
+package etherscan
+
+import (
+	"sync"
+	"time"
+)
+
+// callCounter counts the requests made during the current UTC day
+type callCounter struct {
+	mu    sync.Mutex
+	day   string
+	count int
+}
+
+// record counts one request, starting a new count when the UTC day has changed
+func (cc *callCounter) record() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	today := time.Now().UTC().Format("2006-01-02")
+	if cc.day != today {
+		cc.day = today
+		cc.count = 0
+	}
+	cc.count++
+}
+
+// today returns the number of requests made so far during the current UTC day
+func (cc *callCounter) today() int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.day != time.Now().UTC().Format("2006-01-02") {
+		return 0
+	}
+	return cc.count
+}
+
+// CallsToday returns the number of Etherscan requests this client has made during the
+// current UTC day, including retries. The count resets at midnight UTC.
+func (c *Client) CallsToday() int {
+	return c.calls.today()
+}
@@ -0,0 +1,335 @@
+package etherscan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxAttempts is the number of times a request is tried before giving up
+	maxAttempts = 4
+
+	// baseRetryDelay is the backoff before the first retry; it doubles on each attempt
+	baseRetryDelay = time.Second
+
+	// maxRetryDelay caps the backoff and any Retry-After the server asks for
+	maxRetryDelay = 30 * time.Second
+)
+
+// ErrRateLimited is returned when Etherscan keeps rejecting requests for exceeding the rate limit
+var ErrRateLimited = errors.New("etherscan API rate limit exceeded, please try again later")
+
+// StatusError is returned when Etherscan responds with an HTTP error status
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("etherscan returned HTTP %s", e.Status)
+}
+
+// retryable reports whether the status is a server-side failure worth retrying
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError
+}
+
+// get performs a GET request and returns the response body. Transport errors, 5xx
+// responses and rate-limit responses are retried with jittered exponential backoff,
+// honoring any Retry-After header the server sends. Other 4xx responses fail immediately.
+//
+// With a budget (WithBudget), the wait and the request stop at its deadline, and each retry
+// draws from the retry count shared with the analysis's other requests.
+//
+// Proxy module (JSON-RPC) calls use their own attempt count and per-attempt timeout when set
+// (SetProxyRetry), and a JSON-RPC error object reporting a rate limit is retried like any other.
+func (c *Client) get(endpoint string) ([]byte, error) {
+	var lastErr error
+
+	attempts := c.attempts(endpoint)
+	for attempt := 0; attempt < attempts; attempt++ {
+		body, retryAfter, err := c.fetch(endpoint)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		if ctxErr := c.context().Err(); ctxErr != nil {
+			return nil, fmt.Errorf("%w: %v (last error: %v)", ErrBudgetExhausted, ctxErr, err)
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && !statusErr.retryable() {
+			return nil, err
+		}
+
+		if attempt == attempts-1 {
+			break
+		}
+		if !c.takeRetry() {
+			return nil, fmt.Errorf("%w: no retries left (last error: %v)", ErrBudgetExhausted, err)
+		}
+
+		wait := retryAfter
+		if wait <= 0 {
+			wait = backoff(attempt)
+		}
+		if c.debug {
+			fmt.Printf("DEBUG: Request failed (%v), retrying in %s\n", err, wait)
+		}
+		if !c.sleep(wait) {
+			return nil, fmt.Errorf("%w: %v (last error: %v)", ErrBudgetExhausted, c.context().Err(), err)
+		}
+	}
+
+	return nil, fmt.Errorf("request failed after %d attempts: %w", attempts, lastErr)
+}
+
+// attempts returns the number of times the endpoint's request is tried
+func (c *Client) attempts(endpoint string) int {
+	if c.proxyAttempts > 0 && isProxyCall(endpoint) {
+		return c.proxyAttempts
+	}
+	return maxAttempts
+}
+
+// isProxyCall reports whether the endpoint is a proxy module (JSON-RPC) call
+func isProxyCall(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	return err == nil && u.Query().Get("module") == "proxy"
+}
+
+// fetch performs one attempt of a request. With fallback providers (SetFallbackProviders) it
+// tries them in order until one succeeds, moving on when a provider is rate-limited, returns a
+// server error or can't be reached. Requests scoped to a chain (WithChain) only go to the base
+// URL, since a fallback explorer may ignore the chainid parameter and answer for its own chain.
+func (c *Client) fetch(endpoint string) ([]byte, time.Duration, error) {
+	if c.providers == nil || c.chainID != 0 {
+		return c.doGet(endpoint)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return c.doGet(endpoint)
+	}
+	query := u.Query()
+
+	var retryAfter time.Duration
+	var lastErr error
+	for _, i := range c.providers.order() {
+		provider := c.providers.providers[i]
+		body, wait, err := c.doGet(provider.URL(query))
+		if err == nil {
+			c.providers.markSuccess(i)
+			return body, 0, nil
+		}
+		if c.context().Err() != nil || !shouldFallBack(err) {
+			return nil, wait, err
+		}
+
+		c.providers.markFailure(i, err)
+		if c.debug {
+			fmt.Printf("DEBUG: Provider %s failed (%v), trying the next provider\n", provider.Name(), errorMessage(err))
+		}
+		lastErr = fmt.Errorf("%s: %w", provider.Name(), err)
+		retryAfter = wait
+	}
+	return nil, retryAfter, lastErr
+}
+
+// doGet performs a single GET request. Retryable failures are returned as errors
+// together with any server-requested delay.
+func (c *Client) doGet(endpoint string) ([]byte, time.Duration, error) {
+	// Hold a slot only for the request itself so backoff waits don't block other callers
+	if c.inFlight != nil {
+		select {
+		case c.inFlight <- struct{}{}:
+		case <-c.context().Done():
+			return nil, 0, c.context().Err()
+		}
+		defer func() { <-c.inFlight }()
+	}
+
+	endpoint = c.withChainParam(endpoint)
+	c.calls.record()
+	start := time.Now()
+	defer func() { c.reportSlowCall(endpoint, time.Since(start)) }()
+
+	req, err := c.newRequest(endpoint)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.proxyTimeout > 0 && isProxyCall(endpoint) {
+		ctx, cancel := context.WithTimeout(req.Context(), c.proxyTimeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	if resp.StatusCode == http.StatusTooManyRequests || errors.Is(checkResultError(body), ErrRateLimited) ||
+		errors.Is(checkRPCError(body), ErrRateLimited) {
+		return nil, retryAfter, ErrRateLimited
+	}
+
+	// Gateway failures come back as HTML or empty bodies, so report the status instead of a parse error
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, retryAfter, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	return body, 0, nil
+}
+
+// reportSlowCall logs a warning when a call took longer than the slow call threshold
+func (c *Client) reportSlowCall(endpoint string, elapsed time.Duration) {
+	if c.logger == nil || c.slowCallThreshold <= 0 || elapsed <= c.slowCallThreshold {
+		return
+	}
+
+	query := url.Values{}
+	if u, err := url.Parse(endpoint); err == nil {
+		query = u.Query()
+	}
+	c.logger.WithField("action", query.Get("action")).
+		WithField("address", query.Get("address")).
+		WithField("url", redactAPIKey(endpoint)).
+		Warnf("Slow Etherscan call took %s (threshold %s)", elapsed.Round(time.Millisecond), c.slowCallThreshold)
+}
+
+// redactAPIKey replaces the apikey query parameter of an endpoint so it can be logged
+func redactAPIKey(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "<unparseable url>"
+	}
+
+	query := u.Query()
+	if query.Has("apikey") {
+		query.Set("apikey", "REDACTED")
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// backoff returns the jittered exponential delay before the given retry attempt
+func backoff(attempt int) time.Duration {
+	delay := baseRetryDelay << attempt
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	// Full jitter between half and the whole delay avoids synchronized retries
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		delay = time.Until(t)
+	}
+
+	if delay < 0 {
+		return 0
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// newRequest builds a GET request for the endpoint, bound to the budget's deadline, with the
+// client's User-Agent
+func (c *Client) newRequest(endpoint string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(c.context(), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	return req, nil
+}
+
+// checkResultError detects Etherscan error responses, whose result is a message
+// string instead of data, and converts them to an error
+func checkResultError(body []byte) error {
+	var errorResult struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Result  string `json:"result"`
+	}
+
+	if err := json.Unmarshal(body, &errorResult); err != nil {
+		// The result is not a string, so this is a data response
+		return nil
+	}
+	if errorResult.Status == "1" || errorResult.Result == "" {
+		return nil
+	}
+	return resultError(errorResult.Result)
+}
+
+// RPCError is the error object of a failed proxy module (JSON-RPC) call
+type RPCError struct {
+	Code    int
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("etherscan JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// checkRPCError detects proxy module responses carrying a JSON-RPC error object and converts
+// them to an error, mapping rate limit and API key messages like other Etherscan errors
+func checkRPCError(body []byte) error {
+	var response struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.Error == nil {
+		return nil
+	}
+
+	if err := resultError(response.Error.Message); errors.Is(err, ErrRateLimited) || errors.Is(err, ErrInvalidAPIKey) {
+		return err
+	}
+	return &RPCError{Code: response.Error.Code, Message: response.Error.Message}
+}
+
+// resultError maps an Etherscan error message to an error
+func resultError(result string) error {
+	switch {
+	case strings.Contains(strings.ToLower(result), "rate limit"):
+		return ErrRateLimited
+	case strings.Contains(strings.ToLower(result), "invalid api key"):
+		return ErrInvalidAPIKey
+	default:
+		return fmt.Errorf("etherscan API error: %s", result)
+	}
+}
@@ -0,0 +1,192 @@
+package etherscan
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newSequenceServer returns a stub Etherscan answering each request with the next of bodies,
+// the last one repeated, and counting the requests it served
+func newSequenceServer(t *testing.T, bodies ...string) (*httptest.Server, func() int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	served := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body := bodies[len(bodies)-1]
+		if served < len(bodies) {
+			body = bodies[served]
+		}
+		served++
+		mu.Unlock()
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return served
+	}
+}
+
+func TestProxyCallReturnsRPCError(t *testing.T) {
+	server, served := newSequenceServer(t, `{"jsonrpc":"2.0","id":83,"error":{"code":-32602,"message":"invalid argument 0: hex string without 0x prefix"}}`)
+	client := NewClient("TESTKEY", server.URL)
+
+	_, err := client.GetLatestBlockNumber()
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("error = %v, want an RPCError", err)
+	}
+	if rpcErr.Code != -32602 || rpcErr.Message != "invalid argument 0: hex string without 0x prefix" {
+		t.Errorf("RPCError = %+v, want code -32602 with the server's message", rpcErr)
+	}
+	if n := served(); n != 1 {
+		t.Errorf("%d requests, want 1: only rate limits are retried", n)
+	}
+}
+
+func TestProxyCallRetriesRPCRateLimit(t *testing.T) {
+	server, served := newSequenceServer(t,
+		`{"jsonrpc":"2.0","id":83,"error":{"code":-32005,"message":"Max rate limit reached, please use API Key for higher rate limit"}}`,
+		`{"jsonrpc":"2.0","id":83,"result":"0x12d687"}`,
+	)
+	client := NewClient("TESTKEY", server.URL)
+
+	block, err := client.GetLatestBlockNumber()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block != 1234567 {
+		t.Errorf("block = %d, want 1234567", block)
+	}
+	if n := served(); n != 2 {
+		t.Errorf("%d requests, want 2", n)
+	}
+}
+
+func TestProxyCallRateLimitExhaustsProxyAttempts(t *testing.T) {
+	server, served := newSequenceServer(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"Max rate limit reached"}}`)
+	client := NewClient("TESTKEY", server.URL)
+	client.SetProxyRetry(1, 0)
+
+	if _, err := client.IsContract(testAddress); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("error = %v, want ErrRateLimited", err)
+	}
+	if n := served(); n != 1 {
+		t.Errorf("%d requests, want the 1 proxy attempt", n)
+	}
+}
+
+// newStatusServer returns a stub Etherscan answering each request with the next of statuses,
+// the last one repeated, with an empty transaction list on success, and counting the requests
+func newStatusServer(t *testing.T, statuses ...int) (*httptest.Server, func() int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	served := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		status := statuses[len(statuses)-1]
+		if served < len(statuses) {
+			status = statuses[served]
+		}
+		served++
+		mu.Unlock()
+
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		w.Write([]byte(`{"status":"0","message":"No transactions found","result":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return served
+	}
+}
+
+func TestGetRetriesServerErrors(t *testing.T) {
+	server, served := newStatusServer(t, http.StatusServiceUnavailable, http.StatusOK)
+	client := NewClient("TESTKEY", server.URL)
+
+	txs, err := client.GetNormalTransactions(testAddress, 0, 0)
+	if err != nil {
+		t.Fatalf("error = %v, want the 503 retried", err)
+	}
+	if len(txs) != 0 {
+		t.Errorf("%d transactions, want none", len(txs))
+	}
+	if n := served(); n != 2 {
+		t.Errorf("%d requests, want 2", n)
+	}
+}
+
+func TestGetFailsImmediatelyOnClientErrors(t *testing.T) {
+	server, served := newStatusServer(t, http.StatusForbidden, http.StatusOK)
+	client := NewClient("TESTKEY", server.URL)
+
+	_, err := client.GetNormalTransactions(testAddress, 0, 0)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("error = %v, want a StatusError for the 403", err)
+	}
+	if want := "etherscan returned HTTP 403 Forbidden"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+	if n := served(); n != 1 {
+		t.Errorf("%d requests, want 1: a 4xx isn't retried", n)
+	}
+}
+
+func TestFetchFallsBackAndSkipsFailedProvider(t *testing.T) {
+	primary, primaryServed := newStatusServer(t, http.StatusServiceUnavailable, http.StatusOK)
+	fallback, fallbackServed := newStatusServer(t, http.StatusOK)
+	client := NewClient("TESTKEY", primary.URL)
+	client.SetFallbackProviders(NewExplorer("fallback", fallback.URL, ""))
+
+	// The primary's 503 is answered by the fallback within the same attempt
+	if _, err := client.GetNormalTransactions(testAddress, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if p, f := primaryServed(), fallbackServed(); p != 1 || f != 1 {
+		t.Fatalf("primary served %d, fallback %d, want 1 each", p, f)
+	}
+
+	status := client.ProviderStatus()
+	if len(status) != 2 || status[0].Healthy || status[0].ConsecutiveFailures != 1 || status[0].RetryAt == "" || !status[1].Healthy {
+		t.Fatalf("status = %+v, want the primary cooling down and the fallback healthy", status)
+	}
+
+	// While the primary cools down the fallback is tried first
+	if _, err := client.GetNormalTransactions(testAddress, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if p, f := primaryServed(), fallbackServed(); p != 1 || f != 2 {
+		t.Errorf("primary served %d, fallback %d, want 1 and 2", p, f)
+	}
+}
+
+func TestFetchKeepsChainRequestsOnBaseURL(t *testing.T) {
+	primary, primaryServed := newStatusServer(t, http.StatusServiceUnavailable, http.StatusOK)
+	fallback, fallbackServed := newStatusServer(t, http.StatusOK)
+	client := NewClient("TESTKEY", primary.URL)
+	client.SetFallbackProviders(NewExplorer("fallback", fallback.URL, ""))
+
+	// The fallback might answer for its own chain, so the 503 is retried on the base URL
+	if _, err := client.WithChain(137).GetNormalTransactions(testAddress, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if p, f := primaryServed(), fallbackServed(); p != 2 || f != 0 {
+		t.Errorf("primary served %d, fallback %d, want 2 and 0", p, f)
+	}
+}
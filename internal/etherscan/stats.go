@@ -0,0 +1,65 @@
+package etherscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ethPriceTTL is how long a fetched native token price is reused
+const ethPriceTTL = 5 * time.Minute
+
+// ethPriceCache holds the last native token price fetched per chain
+type ethPriceCache struct {
+	mu     sync.Mutex
+	prices map[int]cachedETHPrice
+}
+
+// cachedETHPrice is a fetched price with the time it was fetched
+type cachedETHPrice struct {
+	usd       float64
+	fetchedAt time.Time
+}
+
+// GetETHPrice returns the current USD price of the native token of the chain the client
+// targets, from the stats module. Prices are reused for a few minutes.
+func (c *Client) GetETHPrice() (float64, error) {
+	c.ethPrices.mu.Lock()
+	cached, ok := c.ethPrices.prices[c.chainID]
+	c.ethPrices.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < ethPriceTTL {
+		return cached.usd, nil
+	}
+
+	endpoint := fmt.Sprintf("%s?module=stats&action=ethprice&apikey=%s", c.baseURL, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching ETH price\n")
+	}
+
+	body, err := c.get(endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching ETH price: %w", err)
+	}
+	if err := checkResultError(body); err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		Result ETHPrice `json:"result"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return 0, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	usd, err := strconv.ParseFloat(response.Result.EthUSD, 64)
+	if err != nil || usd <= 0 {
+		return 0, fmt.Errorf("invalid ETH price %q", response.Result.EthUSD)
+	}
+
+	c.ethPrices.mu.Lock()
+	c.ethPrices.prices[c.chainID] = cachedETHPrice{usd: usd, fetchedAt: time.Now()}
+	c.ethPrices.mu.Unlock()
+	return usd, nil
+}
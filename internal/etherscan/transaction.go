@@ -0,0 +1,148 @@
+package etherscan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrTransactionNotFound is returned when Etherscan has no transaction for a hash
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// GetTransactionByHash fetches a transaction by its hash using eth_getTransactionByHash
+func (c *Client) GetTransactionByHash(hash string) (*ProxyTransaction, error) {
+	endpoint := fmt.Sprintf("%s?module=proxy&action=eth_getTransactionByHash&txhash=%s&apikey=%s",
+		c.baseURL, hash, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching transaction: %s\n", hash)
+	}
+
+	var tx *ProxyTransaction
+	if err := c.proxyResult(endpoint, &tx); err != nil {
+		return nil, fmt.Errorf("error fetching transaction: %w", err)
+	}
+	if tx == nil {
+		return nil, ErrTransactionNotFound
+	}
+	return tx, nil
+}
+
+// GetTransactionReceipt fetches a transaction's receipt, including its event logs, using eth_getTransactionReceipt
+func (c *Client) GetTransactionReceipt(hash string) (*TransactionReceipt, error) {
+	endpoint := fmt.Sprintf("%s?module=proxy&action=eth_getTransactionReceipt&txhash=%s&apikey=%s",
+		c.baseURL, hash, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching transaction receipt: %s\n", hash)
+	}
+
+	var receipt *TransactionReceipt
+	if err := c.proxyResult(endpoint, &receipt); err != nil {
+		return nil, fmt.Errorf("error fetching transaction receipt: %w", err)
+	}
+	if receipt == nil {
+		return nil, ErrTransactionNotFound
+	}
+	return receipt, nil
+}
+
+// GetBlockTimestamp fetches the Unix timestamp (in decimal) of a block using eth_getBlockByNumber
+func (c *Client) GetBlockTimestamp(blockNumber int64) (string, error) {
+	endpoint := fmt.Sprintf("%s?module=proxy&action=eth_getBlockByNumber&tag=0x%x&boolean=false&apikey=%s",
+		c.baseURL, blockNumber, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching block: %d\n", blockNumber)
+	}
+
+	var block *ProxyBlock
+	if err := c.proxyResult(endpoint, &block); err != nil {
+		return "", fmt.Errorf("error fetching block: %w", err)
+	}
+	if block == nil {
+		return "", fmt.Errorf("block %d not found", blockNumber)
+	}
+
+	timestamp, ok := new(big.Int).SetString(strings.TrimPrefix(block.Timestamp, "0x"), 16)
+	if !ok {
+		return "", fmt.Errorf("invalid block timestamp %q", block.Timestamp)
+	}
+	return timestamp.String(), nil
+}
+
+// GetTransactionReceiptStatus fetches the execution status of a transaction using
+// gettxreceiptstatus: "1" for success, "0" for failure and "" when unknown, as for pending
+// and pre-Byzantium transactions
+func (c *Client) GetTransactionReceiptStatus(hash string) (string, error) {
+	endpoint := fmt.Sprintf("%s?module=transaction&action=gettxreceiptstatus&txhash=%s&apikey=%s",
+		c.baseURL, hash, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching transaction receipt status: %s\n", hash)
+	}
+
+	body, err := c.get(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("error fetching transaction receipt status: %w", err)
+	}
+	if err := checkResultError(body); err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Result struct {
+			Status string `json:"status"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return response.Result.Status, nil
+}
+
+// GetInternalTransactionsByHash fetches the internal transactions spawned by a transaction
+func (c *Client) GetInternalTransactionsByHash(hash string) ([]Transaction, error) {
+	endpoint := fmt.Sprintf("%s?module=account&action=txlistinternal&txhash=%s&apikey=%s",
+		c.baseURL, hash, c.apiKey)
+
+	if c.debug {
+		fmt.Printf("DEBUG: Fetching internal transactions for hash: %s\n", hash)
+	}
+
+	return c.fetchTransactions(endpoint, nil)
+}
+
+// proxyResult performs a proxy module (JSON-RPC) request and decodes its result into v,
+// returning an *RPCError when the response carries a JSON-RPC error object. A null result
+// leaves v untouched.
+func (c *Client) proxyResult(endpoint string, v interface{}) error {
+	body, err := c.get(endpoint)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRPCError(body); err != nil {
+		return err
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("error unmarshaling response: %w", err)
+	}
+
+	// Failures such as an invalid key are reported as a plain string result
+	var message string
+	if json.Unmarshal(response.Result, &message) == nil && !strings.HasPrefix(message, "0x") {
+		return resultError(message)
+	}
+
+	if err := json.Unmarshal(response.Result, v); err != nil {
+		return fmt.Errorf("error unmarshaling result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File is a Sink writing results to a file, replacing any previous content
+type File struct {
+	path string
+}
+
+// NewFile creates a sink writing to the file at path
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Write writes the data to a temporary file next to the target and renames it into place,
+// so readers never see a partially written result
+func (f *File) Write(data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), "."+filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrUnsupportedDestination is returned for destinations with no sink implementation
+var ErrUnsupportedDestination = errors.New("unsupported output destination")
+
+// Sink delivers a serialized analysis result to its destination
+type Sink interface {
+	Write(data []byte) error
+}
+
+// New creates the sink for a destination: an http(s) URL for a webhook, or a file path,
+// optionally as a file:// URL
+func New(destination string) (Sink, error) {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// A plain path (a one-letter scheme is a Windows drive)
+		return NewFile(destination), nil
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "file":
+		return NewFile(u.Path), nil
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("missing host in %q", destination)
+		}
+		return NewWebhook(destination), nil
+	default:
+		return nil, fmt.Errorf("%w: scheme %q (use a file path or an http(s) webhook URL)", ErrUnsupportedDestination, u.Scheme)
+	}
+}
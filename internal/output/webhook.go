@@ -0,0 +1,40 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Webhook is a Sink POSTing results as JSON to a URL
+type Webhook struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhook creates a sink POSTing to the webhook URL
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Write POSTs the data to the webhook, failing on any non-2xx response
+func (wh *Webhook) Write(data []byte) error {
+	resp, err := wh.httpClient.Post(wh.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error posting to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
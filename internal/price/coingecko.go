@@ -0,0 +1,74 @@
+package price
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CoinGecko is a Provider backed by CoinGecko's market chart API
+type CoinGecko struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewCoinGecko creates a CoinGecko price provider; apiKey may be empty for the keyless tier
+func NewCoinGecko(baseURL, apiKey string) *CoinGecko {
+	return &CoinGecko{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// USDPrice returns the first USD price CoinGecko reports within the 24 hours starting at t.
+// Tokens are looked up by their Ethereum contract address.
+func (c *CoinGecko) USDPrice(asset string, t time.Time) (float64, error) {
+	path := "/coins/ethereum/market_chart/range"
+	if asset != Ether {
+		path = "/coins/ethereum/contract/" + url.PathEscape(asset) + "/market_chart/range"
+	}
+
+	query := url.Values{}
+	query.Set("vs_currency", "usd")
+	query.Set("from", fmt.Sprint(t.Unix()))
+	query.Set("to", fmt.Sprint(t.Add(24*time.Hour).Unix()))
+
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating price request: %w", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("x-cg-demo-api-key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching price of %s: %w", asset, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, ErrNoPrice
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error fetching price of %s: %s", asset, resp.Status)
+	}
+
+	var chart struct {
+		Prices [][2]float64 `json:"prices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		return 0, fmt.Errorf("error decoding price of %s: %w", asset, err)
+	}
+	if len(chart.Prices) == 0 {
+		return 0, ErrNoPrice
+	}
+
+	return chart.Prices[0][1], nil
+}
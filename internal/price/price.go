@@ -0,0 +1,91 @@
+package price
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Ether identifies native Ether; ERC-20 tokens are identified by their lowercase contract address
+const Ether = "eth"
+
+// unavailableBackoff is how long the cache stops asking a failing feed for prices
+const unavailableBackoff = time.Minute
+
+var (
+	// ErrNoPrice is returned when the feed has no price for the asset on that day
+	ErrNoPrice = errors.New("no price available")
+
+	// ErrUnavailable is returned while a recently failing feed is being left alone
+	ErrUnavailable = errors.New("price feed unavailable")
+)
+
+// Provider returns the USD price of an asset on the day containing t
+type Provider interface {
+	USDPrice(asset string, t time.Time) (float64, error)
+}
+
+// cacheKey identifies an asset's price on one UTC day
+type cacheKey struct {
+	asset string
+	day   int64
+}
+
+// cachedPrice is a cached lookup; ok is false when the feed had no price
+type cachedPrice struct {
+	price float64
+	ok    bool
+}
+
+// Cache wraps a Provider, caching prices by (asset, UTC day). After a feed failure it returns
+// ErrUnavailable for a while instead of waiting on the feed for every transaction.
+type Cache struct {
+	provider Provider
+
+	mu               sync.Mutex
+	prices           map[cacheKey]cachedPrice
+	unavailableUntil time.Time
+}
+
+// NewCache creates a price cache in front of a provider
+func NewCache(provider Provider) *Cache {
+	return &Cache{
+		provider: provider,
+		prices:   make(map[cacheKey]cachedPrice),
+	}
+}
+
+// USDPrice returns the USD price of an asset on the UTC day containing t
+func (c *Cache) USDPrice(asset string, t time.Time) (float64, error) {
+	day := t.UTC().Truncate(24 * time.Hour)
+	key := cacheKey{asset: asset, day: day.Unix()}
+
+	c.mu.Lock()
+	cached, found := c.prices[key]
+	unavailable := time.Now().Before(c.unavailableUntil)
+	c.mu.Unlock()
+
+	if found {
+		if !cached.ok {
+			return 0, ErrNoPrice
+		}
+		return cached.price, nil
+	}
+	if unavailable {
+		return 0, ErrUnavailable
+	}
+
+	price, err := c.provider.USDPrice(asset, day)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case errors.Is(err, ErrNoPrice):
+		c.prices[key] = cachedPrice{}
+	case err != nil:
+		c.unavailableUntil = time.Now().Add(unavailableBackoff)
+	default:
+		c.prices[key] = cachedPrice{price: price, ok: true}
+	}
+	return price, err
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver
+)
+
+// schema creates the response table; a response is identified by address, action and block range
+const schema = `
+CREATE TABLE IF NOT EXISTS responses (
+	address     TEXT    NOT NULL,
+	action      TEXT    NOT NULL,
+	start_block INTEGER NOT NULL,
+	end_block   INTEGER NOT NULL,
+	body        BLOB    NOT NULL,
+	fetched_at  INTEGER NOT NULL,
+	PRIMARY KEY (address, action, start_block, end_block)
+)`
+
+// SQLiteCache persists Etherscan responses in a local SQLite database so repeated analyses,
+// even across restarts, don't spend quota on data already fetched
+type SQLiteCache struct {
+	db *sql.DB
+}
+
+// OpenSQLiteCache opens (creating if needed) the cache database at path
+func OpenSQLiteCache(path string) (*SQLiteCache, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening cache database: %w", err)
+	}
+
+	// SQLite allows a single writer; serializing access avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating cache schema: %w", err)
+	}
+
+	return &SQLiteCache{db: db}, nil
+}
+
+// Get returns the cached response for the key if one was stored within maxAge (0 means any age)
+func (c *SQLiteCache) Get(address, action string, startBlock, endBlock int, maxAge time.Duration) ([]byte, bool, error) {
+	var body []byte
+	var fetchedAt int64
+	err := c.db.QueryRow(
+		`SELECT body, fetched_at FROM responses WHERE address = ? AND action = ? AND start_block = ? AND end_block = ?`,
+		address, action, startBlock, endBlock,
+	).Scan(&body, &fetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading cache: %w", err)
+	}
+
+	if maxAge > 0 && time.Since(time.Unix(fetchedAt, 0)) > maxAge {
+		return nil, false, nil
+	}
+	return body, true, nil
+}
+
+// Put stores a response, replacing any previous one for the key
+func (c *SQLiteCache) Put(address, action string, startBlock, endBlock int, body []byte) error {
+	_, err := c.db.Exec(
+		`INSERT OR REPLACE INTO responses (address, action, start_block, end_block, body, fetched_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		address, action, startBlock, endBlock, body, time.Now().Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (c *SQLiteCache) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,14 @@
+// Package version identifies the build, for the User-Agent of outbound requests
+package version
+
+// Name is the product name sent in the User-Agent
+const Name = "eth-fund-flow"
+
+// Version is the release version, set at build time with
+// -ldflags "-X github.com/shrxyeh/ethereum-fund-flow/internal/version.Version=1.2.3"
+var Version = "dev"
+
+// UserAgent returns the default User-Agent of outbound requests, e.g. "eth-fund-flow/1.2.3"
+func UserAgent() string {
+	return Name + "/" + Version
+}